@@ -0,0 +1,24 @@
+package app
+
+// AgentSocket is the stable path in the VM of the ssh agent socket forwarded from the host,
+// for containers to mount. It is the path used by Docker Desktop.
+const AgentSocket = "/run/host-services/ssh-auth.sock"
+
+// agentGroup is the group in the VM with access to the forwarded ssh agent, besides the user and root.
+const agentGroup = "ssh-agent"
+
+// agentSession returns the session forwarding the ssh agent of the host. The forwarded socket,
+// at a random path for each session, is linked to AgentSocket for the duration of the session.
+func agentSession() sshSession {
+	return sshSession{
+		name: "ssh agent forwarding",
+		args: []string{"-A"},
+		command: `sudo mkdir -p $(dirname ` + AgentSocket + `) && ` +
+			`sudo ln -sfn "$SSH_AUTH_SOCK" ` + AgentSocket + ` && ` +
+			// root in containers has access, other users of containers with the group only
+			`(getent group ` + agentGroup + ` >/dev/null || sudo addgroup -S ` + agentGroup + ` 2>/dev/null || sudo groupadd -r ` + agentGroup + `) && ` +
+			`sudo chgrp ` + agentGroup + ` $(dirname "$SSH_AUTH_SOCK") "$SSH_AUTH_SOCK" && ` +
+			`chmod 710 $(dirname "$SSH_AUTH_SOCK") && chmod 660 "$SSH_AUTH_SOCK" && ` +
+			`exec sleep 2147483647`,
+	}
+}
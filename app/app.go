@@ -1,13 +1,21 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/container/docker"
 	"github.com/abiosoft/colima/environment/container/kubernetes"
 	"github.com/abiosoft/colima/environment/host"
 	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/environment/vm/remote"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -15,10 +23,21 @@ type App interface {
 	Active() bool
 	Start(config.Config) error
 	Stop(force bool) error
-	Delete() error
+	Delete(keepData bool) error
+	DeleteSummary(keepData bool) []string
+	Rename(name string) error
 	SSH(...string) error
+	Exec(opts ExecOptions, args ...string) error
+	Copy(recursive, preserve bool, src []string, dst string) error
 	Status() error
+	StatusInfo() (StatusInfo, error)
+	Healthcheck() ([]HealthCheck, error)
+	VerifyRyuk() error
+	Stats() (Stats, error)
+	Top() ([]ContainerStats, error)
+	Monitor() error
 	Version() error
+	VersionInfo() (VersionInfo, error)
 	Runtime() (string, error)
 	Kubernetes() (environment.Container, error)
 }
@@ -27,7 +46,9 @@ var _ App = (*colimaApp)(nil)
 
 // New creates a new app.
 func New() (App, error) {
-	guest := lima.New(host.New())
+	// the config of the profile may not exist yet
+	conf, _ := config.Load()
+	guest := newGuest(conf)
 	if err := host.IsInstalled(guest); err != nil {
 		return nil, fmt.Errorf("dependency check failed for VM: %w", err)
 	}
@@ -41,7 +62,68 @@ type colimaApp struct {
 	guest environment.VM
 }
 
+// newGuest returns the VM of conf, the remote host if set.
+func newGuest(conf config.Config) environment.VM {
+	if conf.Remote != "" {
+		return remote.New(host.New(), conf.Remote)
+	}
+	return lima.New(host.New())
+}
+
+// sshConfigFile writes the SSH config of the VM of the current profile and returns the path to the file.
+func sshConfigFile() (string, error) {
+	if conf, err := config.Load(); err == nil && conf.Remote != "" {
+		return remote.SSHConfigFile(conf.Remote)
+	}
+	return lima.SSHConfigFile()
+}
+
+// guestDir returns the path in the VM of the host directory dir, if it is mounted with conf.
+// The host directories are not mounted on a remote host.
+func guestDir(conf config.Config, dir string) (string, bool) {
+	if conf.Remote != "" {
+		return "", false
+	}
+	return lima.GuestDir(conf, dir)
+}
+
 func (c colimaApp) Start(conf config.Config) error {
+	if conf.Remote != "" {
+		// a new profile is created with the remote host of conf
+		c.guest = newGuest(conf)
+	}
+	if err := runHooks(conf, "pre_start", conf.Hooks.PreStart); err != nil {
+		return err
+	}
+	if err := c.start(conf); err != nil {
+		// the first line suffices, errors may include log output
+		message := strings.SplitN(err.Error(), "\n", 2)[0]
+		sendNotification(conf, "failed to start: "+message)
+		emitEvent(EventStartFailed, message)
+		return err
+	}
+	c.checkDisk(conf)
+	sendNotification(conf, "started")
+	emitEvent(EventStarted, "")
+
+	// the socket forwarding of a running VM is set on start
+	if conf.Runtime == docker.Name && idleConfigured(conf) != socketProxied(conf) {
+		log.Warnln("the idle policy and lazy start apply to the docker socket after 'colima restart'")
+	}
+
+	// the idle policy, health checks, tunnels and timezone
+	if err := RestartMonitor(conf); err != nil {
+		log.Warnln(err)
+	}
+
+	// the profile is up, failures are not fatal
+	if err := runHooks(conf, "post_start", conf.Hooks.PostStart); err != nil {
+		log.Warnln(err)
+	}
+	return nil
+}
+
+func (c colimaApp) start(conf config.Config) error {
 	log.Println("starting", config.Profile().DisplayName)
 
 	var containers []environment.Container
@@ -62,14 +144,45 @@ func (c colimaApp) Start(conf config.Config) error {
 		containers = append(containers, env)
 	}
 
-	// the order for start is:
+	// the order for start is sequential:
 	//   vm start -> container runtime provision -> container runtime start
+	// only the artifacts of the runtimes are prefetched on the host concurrently, e.g. k3s,
+	// and a few independent steps of the VM and docker setup run in parallel.
+	ctx := context.WithValue(context.Background(), config.CtxKey(), conf)
+	var prefetched map[string]chan struct{}
+	if !c.guest.Created() {
+		prefetched = prefetch(ctx, containers)
+	}
+
+	// cap the size of logs accumulated by previous runs
+	if !c.guest.Running() {
+		rotateLogs()
+	} else {
+		c.resumePaused()
+		// the clock may have drifted while the host was asleep, a remote host keeps its own
+		if conf.Remote == "" {
+			c.syncClock()
+		}
+	}
+
+	progress := cli.NewProgress()
 
 	// start vm
+	if c.guest.Created() {
+		progress.Phase("VM boot")
+	} else {
+		// the VM image is downloaded on first boot
+		progress.Phase("download and VM boot")
+	}
 	if err := c.guest.Start(conf); err != nil {
-		return fmt.Errorf("error starting vm: %w", err)
+		return cli.NewError(cli.ExitVM, fmt.Errorf("error starting vm: %w", err),
+			"check the VM logs with 'colima logs --source vm', or run 'colima doctor'")
 	}
 
+	progress.Phase("provisioning")
+
+	c.applyTimezone(conf)
+
 	// persist runtime for future reference.
 	if err := c.setRuntime(conf.Runtime); err != nil {
 		return fmt.Errorf("error setting current runtime: %w", err)
@@ -79,23 +192,86 @@ func (c colimaApp) Start(conf config.Config) error {
 		return fmt.Errorf("error setting kubernetes version: %w", err)
 	}
 
+	// restore runtime data kept from a previous delete
+	if err := c.restoreData(conf.Runtime); err != nil {
+		return err
+	}
+
 	// provision and start container runtimes
 	for _, cont := range containers {
-		if err := cont.Provision(); err != nil {
-			return fmt.Errorf("error provisioning %s: %w", cont.Name(), err)
+		if cont.Name() == kubernetes.Name {
+			progress.Phase("kubernetes")
+		}
+		if done, ok := prefetched[cont.Name()]; ok {
+			<-done
 		}
-		if err := cont.Start(); err != nil {
-			return fmt.Errorf("error starting %s: %w", cont.Name(), err)
+		if err := cont.Provision(ctx); err != nil {
+			return containerError(cont.Name(), fmt.Errorf("error provisioning %s: %w", cont.Name(), err))
+		}
+		if cont.Name() != kubernetes.Name {
+			progress.Phase("runtime")
+		}
+		if err := cont.Start(ctx); err != nil {
+			return containerError(cont.Name(), fmt.Errorf("error starting %s: %w", cont.Name(), err))
 		}
 	}
 
-	log.Println("done")
+	progress.Done()
 	return nil
 }
 
+// prefetch downloads the artifacts of the containers into the cache of the host in the background.
+// The channels of the containers that prefetch are closed when done. Failures are not fatal, the
+// artifacts are downloaded again by the provisioning.
+func prefetch(ctx context.Context, containers []environment.Container) map[string]chan struct{} {
+	prefetched := map[string]chan struct{}{}
+	for _, cont := range containers {
+		p, ok := cont.(environment.Prefetcher)
+		if !ok {
+			continue
+		}
+		done := make(chan struct{})
+		prefetched[cont.Name()] = done
+		go func(name string) {
+			defer close(done)
+			if err := p.Prefetch(ctx); err != nil {
+				log.Warnln(fmt.Errorf("error prefetching %s artifacts: %w", name, err))
+			}
+		}(cont.Name())
+	}
+	return prefetched
+}
+
+// containerError categorizes the error of the container runtime or Kubernetes.
+func containerError(name string, err error) error {
+	if name == kubernetes.Name {
+		return cli.NewError(cli.ExitKubernetes, err, "check the logs with 'colima logs --source k3s', or reset with 'colima kubernetes reset'")
+	}
+	return cli.NewError(cli.ExitRuntime, err, "check the logs with 'colima logs --source "+name+"'")
+}
+
 func (c colimaApp) Stop(force bool) error {
 	log.Println("stopping", config.Profile().DisplayName)
 
+	conf, err := config.Load()
+	if err != nil {
+		// not fatal, the default shutdown timeout is used
+		log.Warnln(err)
+	}
+	ctx := context.WithValue(context.Background(), config.CtxKey(), conf)
+
+	if err := runHooks(conf, "pre_stop", conf.Hooks.PreStop); err != nil {
+		if !force {
+			return err
+		}
+		// a forceful shutdown proceeds regardless
+		log.Warnln(err)
+	}
+
+	// the monitor would otherwise resume the VM on the next connection
+	stopMonitor()
+	c.resumePaused()
+
 	// the order for stop is:
 	//   container stop -> vm stop
 
@@ -109,7 +285,7 @@ func (c colimaApp) Stop(force bool) error {
 		// stop happens in reverse of start
 		for i := len(containers) - 1; i >= 0; i-- {
 			cont := containers[i]
-			if err := cont.Stop(); err != nil {
+			if err := cont.Stop(ctx); err != nil {
 				// failure to stop a container runtime is not fatal
 				// it is only meant for graceful shutdown.
 				// the VM will shut down anyways.
@@ -120,17 +296,38 @@ func (c colimaApp) Stop(force bool) error {
 
 	// stop vm
 	// no need to check running status, it may be in a state that requires stopping.
+	// a graceful shutdown that does not complete within the shutdown timeout is forced.
 	if err := c.guest.Stop(force); err != nil {
 		return fmt.Errorf("error stopping vm: %w", err)
 	}
+	rotateLogs()
+	emitEvent(EventStopped, "")
+
+	if err := runHooks(conf, "post_stop", conf.Hooks.PostStop); err != nil {
+		log.Warnln(err)
+	}
 
 	log.Println("done")
 	return nil
 }
 
-func (c colimaApp) Delete() error {
+func (c colimaApp) Delete(keepData bool) error {
 	log.Println("deleting", config.Profile().DisplayName)
 
+	if keepData {
+		if conf, _ := config.Load(); conf.Remote != "" {
+			return fmt.Errorf("--keep-data is not supported with a remote host, the data of its runtime is not deleted")
+		}
+		if !c.guest.Running() {
+			return fmt.Errorf("%s must be running to keep the data", config.Profile().DisplayName)
+		}
+		if err := c.stopAndKeepData(); err != nil {
+			return fmt.Errorf("error keeping data: %w", err)
+		}
+	}
+
+	stopMonitor()
+
 	// the order for teardown is:
 	//   container teardown -> vm teardown
 
@@ -166,31 +363,281 @@ func (c colimaApp) Delete() error {
 	return nil
 }
 
+// stopAndKeepData stops the container runtimes and archives the runtime data to the host.
+func (c colimaApp) stopAndKeepData() error {
+	runtime, err := c.currentRuntime()
+	if err != nil {
+		return err
+	}
+	containers, err := c.currentContainerEnvironments()
+	if err != nil {
+		return err
+	}
+
+	conf, _ := config.Load()
+	ctx := context.WithValue(context.Background(), config.CtxKey(), conf)
+	// stop in reverse order of start
+	for i := len(containers) - 1; i >= 0; i-- {
+		if err := containers[i].Stop(ctx); err != nil {
+			return fmt.Errorf("error stopping %s: %w", containers[i].Name(), err)
+		}
+	}
+
+	return c.keepData(runtime)
+}
+
+// DeleteSummary returns a summary of what is lost on delete.
+// The runtime data is excluded if keepData is true.
+func (c colimaApp) DeleteSummary(keepData bool) []string {
+	conf, _ := config.Load()
+
+	var summary []string
+	if c.guest.Created() && conf.Remote == "" {
+		if conf.VM.Disk > 0 {
+			summary = append(summary, fmt.Sprintf("the VM and its %dGiB disk", conf.VM.Disk))
+		} else {
+			summary = append(summary, "the VM and its disk")
+		}
+	}
+
+	runtime := conf.Runtime
+	if r, err := c.currentRuntime(); err == nil {
+		runtime = r
+	}
+	// the runtime of a remote host and its data are not deleted
+	if runtime != "" && !keepData && conf.Remote == "" {
+		if c.guest.Running() {
+			summary = append(summary, c.runtimeDataSummary(runtime))
+		} else {
+			summary = append(summary, "all images, containers and volumes of the "+runtime+" runtime")
+		}
+	}
+
+	if conf.Kubernetes.Enabled {
+		summary = append(summary, "the Kubernetes cluster and its data")
+	}
+
+	summary = append(summary, "the settings in "+config.Dir())
+	return summary
+}
+
+// runtimeDataSummary returns the number of images, containers and volumes of the runtime.
+func (c colimaApp) runtimeDataSummary(runtime string) string {
+	client := "docker"
+	if runtime != "docker" {
+		client = "nerdctl"
+	}
+	count := func(args ...string) int {
+		out, err := c.guest.RunOutput(append([]string{"sudo", client}, args...)...)
+		if err != nil {
+			return 0
+		}
+		return len(strings.Fields(out))
+	}
+
+	return fmt.Sprintf("%d images, %d containers and %d volumes of the %s runtime",
+		count("image", "ls", "-q"),
+		count("ps", "-aq"),
+		count("volume", "ls", "-q"),
+		runtime,
+	)
+}
+
+// Rename renames the profile to name. The VM must be stopped.
+func (c colimaApp) Rename(name string) error {
+	from, to := config.Profile(), config.ProfileFromName(name)
+	if from.ID == to.ID {
+		return fmt.Errorf("%s is already named '%s'", from.DisplayName, name)
+	}
+	if c.guest.Running() {
+		return fmt.Errorf("%s is running, stop it before renaming", from.DisplayName)
+	}
+	if c, err := config.LoadProfile(name); err == nil && !c.Empty() {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+
+	log.Println("renaming", from.DisplayName, "to", to.DisplayName)
+
+	// other profiles referencing the old name, checked before the rename
+	// as the references cannot be loaded afterwards.
+	var references []string
+	profiles, _ := config.Profiles()
+	for _, p := range profiles {
+		conf, err := config.LoadProfile(p)
+		if err != nil {
+			continue
+		}
+		for _, ref := range []string{conf.Kubernetes.Join, conf.Extends} {
+			if ref != "" && config.ProfileFromName(ref).ID == from.ID && config.ProfileFromName(p).ID != from.ID {
+				references = append(references, p)
+				break
+			}
+		}
+	}
+
+	// host configurations are recreated for the new name on the next startup.
+	// failures are not fatal.
+	h := host.New()
+	if h.RunQuiet("docker", "context", "inspect", from.ID) == nil {
+		if err := h.RunQuiet("docker", "context", "rm", "--force", from.ID); err != nil {
+			log.Warnln(fmt.Errorf("error removing docker context: %w", err))
+		}
+	}
+	if h.RunQuiet("kubectl", "config", "get-contexts", from.ID) == nil {
+		for _, entry := range []string{"users.", "contexts.", "clusters."} {
+			if err := h.RunQuiet("kubectl", "config", "unset", entry+from.ID); err != nil {
+				log.Warnln(fmt.Errorf("error removing kubeconfig entry: %w", err))
+			}
+		}
+	}
+
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := lima.RenameInstance(from, to); err != nil {
+		return err
+	}
+	if err := config.RenameProfile(name); err != nil {
+		return err
+	}
+
+	// the configs of the instance have the paths and the ssh host of the previous name
+	config.SetProfile(name)
+	if err := os.Remove(filepath.Join(config.Dir(), "ssh_config")); err != nil && !os.IsNotExist(err) {
+		log.Warnln(fmt.Errorf("error removing ssh config: %w", err))
+	}
+	if conf.Remote == "" {
+		if err := lima.WriteInstanceConfig(conf); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range references {
+		log.Warnf("profile '%s' references '%s', update it to '%s'", p, from.ShortName, name)
+	}
+
+	log.Println("done")
+	return nil
+}
+
 func (c colimaApp) SSH(args ...string) error {
 	if !c.guest.Running() {
 		return fmt.Errorf("%s not running", config.Profile().DisplayName)
 	}
 
-	return c.guest.RunInteractive(args...)
+	// the corresponding directory in the VM if the current directory is mounted
+	var dir string
+	if conf, err := config.Load(); err == nil {
+		if cwd, err := os.Getwd(); err == nil {
+			dir, _ = guestDir(conf, cwd)
+		}
+	}
+	return c.guest.SSH(dir, args...)
 }
 
-func (c colimaApp) Status() error {
+// GuestPathPrefix is the prefix of the paths in the VM for Copy.
+const GuestPathPrefix = "vm:"
+
+// Copy copies the files at src to dst with scp, through the SSH connection to the VM.
+func (c colimaApp) Copy(recursive, preserve bool, src []string, dst string) error {
 	if !c.guest.Running() {
-		return fmt.Errorf("%s is not running", config.Profile().DisplayName)
+		return fmt.Errorf("%s not running", config.Profile().DisplayName)
+	}
+	sshConfig, err := sshConfigFile()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"scp", "-F", sshConfig}
+	if recursive {
+		args = append(args, "-r")
+	}
+	if preserve {
+		args = append(args, "-p")
+	}
+	for _, p := range append(src, dst) {
+		switch {
+		case strings.HasPrefix(p, GuestPathPrefix):
+			p = config.Profile().ID + ":" + strings.TrimPrefix(p, GuestPathPrefix)
+		case strings.Contains(p, ":") && !filepath.IsAbs(p):
+			// not to be mistaken for a remote path by scp
+			p = "./" + p
+		}
+		args = append(args, p)
 	}
+	return host.New().RunInteractive(args...)
+}
 
-	currentRuntime, err := c.currentRuntime()
+func (c colimaApp) Status() error {
+	status, err := c.StatusInfo()
 	if err != nil {
 		return err
 	}
+	if !status.Created {
+		return fmt.Errorf("%s %w", config.Profile().DisplayName, ErrNotCreated)
+	}
+	if !status.Running {
+		return fmt.Errorf("%s %w", config.Profile().DisplayName, ErrNotRunning)
+	}
+
+	if status.Paused {
+		log.Println(config.Profile().DisplayName, "is paused, resumed on the next docker connection or with 'colima start'")
+		return nil
+	}
 
 	log.Println(config.Profile().DisplayName, "is running")
-	log.Println("runtime:", currentRuntime)
-	log.Println("arch:", c.guest.Arch())
+	log.Println("runtime:", status.Runtime)
+	if status.Remote != "" {
+		log.Println("remote:", status.Remote)
+	}
+	if status.Emulated {
+		log.Println("arch:", status.Arch, "(emulated)")
+	} else {
+		log.Println("arch:", status.Arch)
+	}
+	if status.IPAddress != "" {
+		log.Println("address:", status.IPAddress)
+	}
+	switch status.Network {
+	case "up":
+		log.Println("network: up")
+	case "down":
+		log.Warnln("network: down, the address is not reachable from the host, retry with 'colima restart'")
+	}
+	if status.SSHPort > 0 {
+		log.Println("ssh port:", status.SSHPort)
+	}
+	if status.DockerSocket != "" {
+		log.Println("docker socket:", status.DockerSocket)
+	}
+	if status.Uptime != "" {
+		log.Println("uptime:", status.Uptime)
+	}
+	if len(status.Mounts) > 0 {
+		log.Println("mounts:", strings.Join(status.Mounts, ", "))
+	}
 
 	// kubernetes
-	if k, err := c.Kubernetes(); err == nil && k.Running() {
+	if status.Kubernetes {
 		log.Println("kubernetes: enabled")
+		if status.KubeContextCurrent {
+			log.Println("kube context:", status.KubeContext, "(current)")
+		} else {
+			log.Println("kube context:", status.KubeContext, "(switch with 'kubectl config use-context "+status.KubeContext+"')")
+		}
+	}
+
+	if len(status.Restarts) > 0 {
+		var restarts []string
+		for service, n := range status.Restarts {
+			restarts = append(restarts, fmt.Sprintf("%s (%d)", service, n))
+		}
+		sort.Strings(restarts)
+		log.Warnln("crashed and restarted by the watchdog:", strings.Join(restarts, ", "))
+	}
+	for _, problem := range status.Degraded {
+		log.Warnln("degraded:", problem)
 	}
 
 	return nil
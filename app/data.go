@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/container/containerd"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	log "github.com/sirupsen/logrus"
+)
+
+// runtimeDataDirs are the directories in the VM holding the images, containers
+// and volumes of each container runtime.
+var runtimeDataDirs = map[string][]string{
+	docker.Name:     {"/var/lib/docker"},
+	containerd.Name: {"/var/lib/containerd", "/var/lib/nerdctl", "/var/lib/buildkit"},
+}
+
+// dataArchive is the file the runtime data is kept in between VM deletion and recreation.
+// It is in the cache directory which outlives the profile config and is mounted in the VM.
+func dataArchive(runtime string) string {
+	return filepath.Join(config.CacheDir(), "data-"+runtime+".tar.gz")
+}
+
+// keepData archives the data of the container runtime to the host.
+// The runtime must be stopped to get a consistent archive.
+func (c colimaApp) keepData(runtime string) error {
+	var dirs []string
+	for _, dir := range runtimeDataDirs[runtime] {
+		if c.guest.RunQuiet("sudo", "test", "-d", dir) == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	log.Println("keeping", runtime, "data at", dataArchive(runtime))
+
+	// write to a temporary file to not leave a partial archive behind on failure
+	tmp := dataArchive(runtime) + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error creating data archive: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	if err := lima.Archive(config.Profile().ID, f, dirs...); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing data archive: %w", err)
+	}
+
+	return os.Rename(tmp, dataArchive(runtime))
+}
+
+// restoreData restores the data of the container runtime kept from a previous delete, if any.
+// It must be done before the runtime is started.
+func (c colimaApp) restoreData(runtime string) error {
+	archive := dataArchive(runtime)
+	if _, err := os.Stat(archive); err != nil {
+		return nil
+	}
+
+	log.Println("restoring", runtime, "data from", archive)
+
+	// the service may have been started by the VM init
+	_ = c.guest.RunQuiet(environment.ServiceCmd(c.guest, runtime, "stop")...)
+	if err := c.guest.Run("sudo", "tar", "-C", "/", "-xzf", archive); err != nil {
+		return fmt.Errorf("error restoring %s data: %w", runtime, err)
+	}
+
+	return os.Remove(archive)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util"
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+)
+
+// The types of events.
+const (
+	EventStarted        = "started"
+	EventStartFailed    = "start-failed"
+	EventStopped        = "stopped"
+	EventPaused         = "paused"
+	EventResumed        = "resumed"
+	EventRuntimeCrashed = "runtime-crashed"
+	EventDegraded       = "degraded"
+	EventDiskLow        = "disk-low"
+)
+
+// Event is a change of state of a profile.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile"`
+	Type    string    `json:"type"`
+	Message string    `json:"message,omitempty"`
+}
+
+// maxEventsSize is the size the events file is rotated at.
+const maxEventsSize = units.MiB
+
+// EventsFile returns the path to the file of the events of the profile.
+func EventsFile() string { return filepath.Join(config.Dir(), "events.log") }
+
+// emitEvent records the event for the current profile. Failures are not fatal.
+func emitEvent(eventType, message string) {
+	event := Event{Time: time.Now().UTC(), Profile: config.Profile().ShortName, Type: eventType, Message: message}
+	b, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	_ = util.RotateFile(EventsFile(), maxEventsSize, 1)
+	f, err := os.OpenFile(EventsFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Warnln(fmt.Errorf("error recording event: %w", err))
+		return
+	}
+	defer f.Close()
+	// a single write, concurrent writers do not interleave
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		log.Warnln(fmt.Errorf("error recording event: %w", err))
+	}
+}
+
+// ReadEvents calls handle with the recorded events of the current profile, in order.
+// If follow is true, new events are handled as they occur until handle returns an error.
+func ReadEvents(follow bool, handle func(Event) error) error {
+	var offset int64
+	for {
+		n, err := readEvents(offset, handle)
+		if err != nil {
+			return err
+		}
+		offset = n
+		if !follow {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+
+		// rotated, or the profile was deleted and recreated
+		if stat, err := os.Stat(EventsFile()); err != nil || stat.Size() < offset {
+			offset = 0
+		}
+	}
+}
+
+// readEvents handles the events in the file from offset and returns the offset of the end.
+func readEvents(offset int64, handle func(Event) error) (int64, error) {
+	f, err := os.Open(EventsFile())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return offset, fmt.Errorf("error reading events: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("error reading events: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// a partial line is read again on the next call
+			return offset, nil
+		}
+		offset += int64(len(line))
+
+		var event Event
+		if json.Unmarshal(line, &event) != nil {
+			continue
+		}
+		if err := handle(event); err != nil {
+			return offset, err
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/host"
+	"github.com/abiosoft/colima/util"
+	"github.com/abiosoft/colima/util/terminal"
+)
+
+// ExecOptions are the options of Exec.
+type ExecOptions struct {
+	// Root runs the command as root.
+	Root bool
+	// TTY forces the allocation of a terminal, NoTTY disables it.
+	// A terminal is allocated if the input is a terminal otherwise.
+	TTY, NoTTY bool
+}
+
+// Exec runs the command in the VM with ssh, the arguments are passed as is without shell
+// interpretation. The error of a failed command is an *exec.ExitError with its exit code.
+func (c colimaApp) Exec(opts ExecOptions, args ...string) error {
+	if !c.guest.Running() {
+		return fmt.Errorf("%s not running", config.Profile().DisplayName)
+	}
+	sshConfig, err := sshConfigFile()
+	if err != nil {
+		return err
+	}
+
+	if opts.Root {
+		args = append([]string{"sudo"}, args...)
+	}
+	var quoted []string
+	for _, a := range args {
+		quoted = append(quoted, util.ShellQuote(a))
+	}
+	command := "exec " + strings.Join(quoted, " ")
+	// the corresponding directory in the VM if the current directory is mounted
+	if conf, err := config.Load(); err == nil {
+		if cwd, err := os.Getwd(); err == nil {
+			if dir, ok := guestDir(conf, cwd); ok {
+				command = "cd " + util.ShellQuote(dir) + " && " + command
+			}
+		}
+	}
+
+	ssh := []string{"ssh", "-F", sshConfig, "-q"}
+	switch {
+	case opts.NoTTY:
+		ssh = append(ssh, "-T")
+	case opts.TTY:
+		// forced even if the input is not a terminal
+		ssh = append(ssh, "-tt")
+	case terminal.IsInputTerminal():
+		ssh = append(ssh, "-t")
+	}
+	ssh = append(ssh, config.Profile().ID, "--", command)
+
+	return host.New().RunInteractive(ssh...)
+}
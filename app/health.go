@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	log "github.com/sirupsen/logrus"
+)
+
+// Health is the result of the last health checks of the profile by the monitor.
+type Health struct {
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the result of a health check, it passed if Problem is empty.
+type HealthCheck struct {
+	Name     string `json:"name"`
+	Problem  string `json:"problem,omitempty"`
+	Repaired bool   `json:"repaired,omitempty"`
+}
+
+// Degraded returns the problems of the failed checks that were not repaired.
+func (h Health) Degraded() []string {
+	var problems []string
+	for _, c := range h.Checks {
+		if c.Problem != "" && !c.Repaired {
+			problems = append(problems, c.Name+": "+c.Problem)
+		}
+	}
+	return problems
+}
+
+func healthFile() string { return filepath.Join(config.Dir(), "health.json") }
+
+// readHealth returns the result of the last health checks, if recent enough for interval.
+func readHealth(interval time.Duration) (Health, bool) {
+	var h Health
+	b, err := os.ReadFile(healthFile())
+	if err != nil {
+		return h, false
+	}
+	if err := json.Unmarshal(b, &h); err != nil {
+		return h, false
+	}
+	// the monitor is not running
+	if time.Since(h.CheckedAt) > 3*interval {
+		return h, false
+	}
+	return h, true
+}
+
+type healthMonitor struct {
+	app  colimaApp
+	conf config.Config
+	// degraded are the problems of the previous checks, to notify on changes only.
+	degraded map[string]bool
+	// watchdogSince is the time of the last crash reported by the watchdog.
+	watchdogSince int64
+}
+
+func (c colimaApp) newHealthMonitor(conf config.Config) *healthMonitor {
+	log.Printf("health checks: every %v, repairs: %s", conf.Health.IntervalDuration(), strings.Join(conf.Health.Repairs, ", "))
+	return &healthMonitor{app: c, conf: conf, degraded: map[string]bool{}, watchdogSince: time.Now().Unix()}
+}
+
+// check runs the health checks, performs the enabled repairs and saves the result.
+func (m *healthMonitor) check() {
+	// stopped or paused, e.g. by the idle policy
+	if !m.app.guest.Running() || lima.Paused(config.Profile().ID) {
+		_ = os.Remove(healthFile())
+		return
+	}
+
+	checks := []struct {
+		name   string
+		check  func() string
+		repair func() error
+	}{
+		{name: config.HealthRepairRuntime, check: m.checkRuntime, repair: m.restartRuntime},
+		{name: "dns", check: m.checkDNS},
+		{name: config.HealthRepairDisk, check: m.checkDisk, repair: m.pruneDisk},
+	}
+
+	health := Health{CheckedAt: time.Now()}
+	for _, c := range checks {
+		result := HealthCheck{Name: c.name, Problem: c.check()}
+		if result.Problem != "" && c.repair != nil && m.conf.Health.Repair(c.name) {
+			log.Printf("%s check failed: %s, repairing", c.name, result.Problem)
+			if err := c.repair(); err != nil {
+				log.Warnln(fmt.Errorf("error repairing %s: %w", c.name, err))
+			} else {
+				result.Repaired = c.check() == ""
+			}
+		}
+		health.Checks = append(health.Checks, result)
+	}
+
+	degraded := map[string]bool{}
+	for _, c := range health.Checks {
+		if c.Problem == "" || c.Repaired {
+			continue
+		}
+		problem := c.Name + ": " + c.Problem
+		degraded[problem] = true
+		if !m.degraded[problem] {
+			log.Warnln("degraded:", problem)
+			sendNotification(m.conf, "is degraded, "+problem)
+			if c.Name == config.HealthRepairDisk {
+				emitEvent(EventDiskLow, c.Problem)
+			} else {
+				emitEvent(EventDegraded, problem)
+			}
+		}
+	}
+	m.degraded = degraded
+	m.checkCrashes()
+
+	b, err := json.Marshal(health)
+	if err == nil {
+		err = os.WriteFile(healthFile(), b, 0644)
+	}
+	if err != nil {
+		log.Warnln(fmt.Errorf("error saving health checks: %w", err))
+	}
+}
+
+// checkCrashes records, and notifies, the crashes of services reported by the watchdog since the previous check.
+func (m *healthMonitor) checkCrashes() {
+	out, err := m.app.guest.RunOutput("sudo", "cat", lima.WatchdogLogFile)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(out, "\n") {
+		// <unix time> crashed <service>
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "crashed" {
+			continue
+		}
+		t, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || t <= m.watchdogSince {
+			continue
+		}
+		m.watchdogSince = t
+		sendNotification(m.conf, fields[2]+" crashed")
+		emitEvent(EventRuntimeCrashed, fields[2]+" crashed")
+	}
+}
+
+// checkRuntime checks if the container runtime is responding.
+func (m *healthMonitor) checkRuntime() string {
+	if m.conf.Runtime != docker.Name {
+		if err := m.app.guest.RunQuiet("sudo", "nerdctl", "info"); err != nil {
+			return m.conf.Runtime + " is not responding"
+		}
+		return ""
+	}
+
+	// the socket proxied by the monitor would resume the VM
+	socket := docker.HostSocketFile()
+	if socketProxied(m.conf) {
+		socket = docker.VMSocketFile()
+	}
+	return pingDocker(socket)
+}
+
+// pingDocker checks if docker is responding on the socket.
+func pingDocker(socket string) string {
+	client := http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	resp, err := client.Get("http://docker/_ping")
+	if err != nil {
+		return "docker socket is not reachable"
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "docker is not responding, status " + strconv.Itoa(resp.StatusCode)
+	}
+	return ""
+}
+
+// restartRuntime restarts the container runtime in the VM.
+func (m *healthMonitor) restartRuntime() error {
+	if err := m.app.guest.RunQuiet(environment.ServiceCmd(m.app.guest, m.conf.Runtime, "restart")...); err != nil {
+		return err
+	}
+	// give the socket time to be forwarded
+	time.Sleep(5 * time.Second)
+	return nil
+}
+
+// checkDNS checks if the VM can resolve external hosts.
+func (m *healthMonitor) checkDNS() string {
+	if err := m.app.guest.RunQuiet("nslookup", "github.com"); err != nil {
+		return "github.com cannot be resolved in the VM"
+	}
+	return ""
+}
+
+// checkDisk checks if the disk space for the container runtime data is low.
+func (m *healthMonitor) checkDisk() string {
+	free, err := m.app.diskFree(m.conf.Runtime)
+	if err != nil || free >= lowDiskPercent {
+		return ""
+	}
+	return fmt.Sprintf("low on disk space, %d%% free", free)
+}
+
+// pruneDisk removes the build cache and dangling images of the container runtime.
+func (m *healthMonitor) pruneDisk() error {
+	client := "nerdctl"
+	if m.conf.Runtime == docker.Name {
+		client = "docker"
+		if err := m.app.guest.RunQuiet("sudo", "docker", "builder", "prune", "-f"); err != nil {
+			return err
+		}
+	}
+	return m.app.guest.RunQuiet("sudo", client, "image", "prune", "-f")
+}
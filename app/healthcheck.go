@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
+	"github.com/abiosoft/colima/environment/vm/lima"
+)
+
+// Names of the checks of Healthcheck.
+const (
+	HealthcheckSocket     = "socket"
+	HealthcheckImage      = "image"
+	HealthcheckDNS        = "dns"
+	HealthcheckKubectl    = "kubectl"
+	HealthcheckKubernetes = "kubernetes"
+)
+
+// healthcheckImage is the image of the container the DNS resolution is checked in.
+const healthcheckImage = "busybox:1.35"
+
+// Healthcheck verifies end to end that the profile is usable: the runtime responds on its socket,
+// DNS resolves in a container and the Kubernetes API answers if enabled.
+// The checks are run in order, a check is skipped if the check it depends on failed,
+// e.g. the DNS is not checked if the image of the container cannot be pulled.
+func (c colimaApp) Healthcheck() ([]HealthCheck, error) {
+	if !c.guest.Running() {
+		if !c.guest.Created() {
+			return nil, fmt.Errorf("%s %w", config.Profile().DisplayName, ErrNotCreated)
+		}
+		return nil, fmt.Errorf("%s %w", config.Profile().DisplayName, ErrNotRunning)
+	}
+	if lima.Paused(config.Profile().ID) {
+		return nil, fmt.Errorf("%s %w", config.Profile().DisplayName, ErrPaused)
+	}
+
+	runtime, err := c.currentRuntime()
+	if err != nil {
+		return nil, err
+	}
+	client := "nerdctl"
+	socket := HealthCheck{Name: HealthcheckSocket}
+	if runtime == docker.Name {
+		client = "docker"
+		// the socket used on the host
+		socket.Problem = pingDocker(docker.HostSocketFile())
+	} else if err := c.guest.RunQuiet("sudo", "nerdctl", "info"); err != nil {
+		socket.Problem = runtime + " is not responding"
+	}
+	checks := []HealthCheck{socket}
+
+	if socket.Problem == "" {
+		// pulled separately, a registry that is not reachable is not reported as DNS
+		image := HealthCheck{Name: HealthcheckImage}
+		if err := c.guest.RunQuiet("sudo", client, "pull", healthcheckImage); err != nil {
+			image.Problem = healthcheckImage + " cannot be pulled"
+		}
+		checks = append(checks, image)
+
+		if image.Problem == "" {
+			dns := HealthCheck{Name: HealthcheckDNS}
+			if err := c.guest.RunQuiet("sudo", client, "run", "--rm", healthcheckImage, "nslookup", "github.com"); err != nil {
+				dns.Problem = "github.com cannot be resolved in a container"
+			}
+			checks = append(checks, dns)
+		}
+	}
+
+	if k, err := c.containerEnvironment(kubernetes.Name); err == nil && k.Running() {
+		kubectl := HealthCheck{Name: HealthcheckKubectl}
+		if _, err := exec.LookPath("kubectl"); err != nil {
+			kubectl.Problem = "kubectl is not installed on the host"
+		}
+		checks = append(checks, kubectl)
+
+		if kubectl.Problem == "" {
+			api := HealthCheck{Name: HealthcheckKubernetes}
+			if err := exec.Command("kubectl", "--context", config.Profile().ID, "get", "--raw", "/readyz").Run(); err != nil {
+				api.Problem = "the Kubernetes API is not answering"
+			}
+			checks = append(checks, api)
+		}
+	}
+	return checks, nil
+}
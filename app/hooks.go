@@ -0,0 +1,45 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/host"
+	log "github.com/sirupsen/logrus"
+)
+
+// runHooks runs the host commands of the lifecycle event in order,
+// with the details of the profile in the environment.
+// The first failing command terminates the hooks and its error is returned.
+func runHooks(conf config.Config, event string, commands []string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	env := append(ProfileEnv(conf), "COLIMA_HOOK="+event)
+	h := host.New().WithEnv(env...)
+	for _, command := range commands {
+		log.Println("running", event, "hook:", command)
+		if err := h.RunInteractive("sh", "-c", command); err != nil {
+			return fmt.Errorf("error running %s hook '%s': %w", event, command, err)
+		}
+	}
+	return nil
+}
+
+// ProfileEnv returns the environment variables with the details of the current profile,
+// for the host commands run on its behalf. conf is the config of the profile.
+func ProfileEnv(conf config.Config) []string {
+	profile := config.Profile()
+	env := []string{
+		"COLIMA_PROFILE=" + profile.ShortName,
+		"COLIMA_PROFILE_ID=" + profile.ID,
+		"COLIMA_RUNTIME=" + conf.Runtime,
+		"COLIMA_DIR=" + config.Dir(),
+	}
+	if conf.Runtime == docker.Name {
+		env = append(env, "DOCKER_HOST="+docker.Host(conf))
+	}
+	return env
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	log "github.com/sirupsen/logrus"
+)
+
+// idleCheckInterval is the interval between the activity checks of the idle monitor.
+const idleCheckInterval = time.Minute
+
+// resumePaused resumes the VM if it was paused by the idle monitor.
+func (c colimaApp) resumePaused() {
+	if !lima.Paused(config.Profile().ID) {
+		return
+	}
+	log.Println("resuming", config.Profile().DisplayName)
+	if err := lima.Resume(config.Profile().ID); err != nil {
+		log.Warnln(err)
+		return
+	}
+	c.syncClock()
+	emitEvent(EventResumed, "")
+}
+
+// syncClock sets the clock of the VM from the hardware clock, which keeps up with the host
+// while the VM is paused or suspended.
+// The watchdog in the VM does the same periodically, this avoids the delay.
+func (c colimaApp) syncClock() {
+	if err := c.guest.RunQuiet("sudo", "hwclock", "--hctosys", "--utc"); err != nil {
+		log.Warnln(fmt.Errorf("error syncing the clock: %w", err))
+	}
+}
+
+// idleConfigured returns if the idle policy or the lazy start is enabled in conf.
+func idleConfigured(conf config.Config) bool { return conf.Idle.Enabled() || conf.LazyStart }
+
+// socketProxied returns if the docker socket of the profile is proxied by the monitor.
+// The socket forwarding of a running VM is set on start, the idle policy and the lazy start
+// enabled or disabled since apply to the socket after a restart.
+func socketProxied(conf config.Config) bool {
+	if conf.Runtime != docker.Name || conf.Remote != "" {
+		return false
+	}
+	if i, err := lima.Instance(config.Profile().ID); err == nil && i.Status == "Running" {
+		return lima.DockerSocketProxied(config.Profile().ID)
+	}
+	return idleConfigured(conf)
+}
+
+// newIdleMonitor creates the monitor of the idle policy and the lazy start. For the docker runtime, the docker socket
+// is proxied and the proxy errors are sent to errCh. close must be called to remove the socket.
+func (c colimaApp) newIdleMonitor(conf config.Config, errCh chan<- error) (m *idleMonitor, close func(), err error) {
+	m = &idleMonitor{
+		app:        c,
+		conf:       conf,
+		timeout:    time.Duration(conf.Idle.Timeout) * time.Minute,
+		lastActive: time.Now(),
+		// started for a stopped profile with lazy start, the first connection starts it
+		idle: !c.guest.Running() || lima.Paused(config.Profile().ID),
+	}
+	close = func() {}
+
+	if socketProxied(conf) {
+		// stale socket from a previous run
+		_ = os.Remove(docker.HostSocketFile())
+		l, err := net.Listen("unix", docker.HostSocketFile())
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listening on docker socket: %w", err)
+		}
+		// closing removes the socket
+		close = func() { _ = l.Close() }
+		go func() { errCh <- m.proxy(l, docker.VMSocketFile()) }()
+	}
+
+	if conf.Idle.Enabled() {
+		log.Printf("idle policy: %s after %v of inactivity", conf.Idle.IdleAction(), m.timeout)
+	}
+	if m.idle {
+		log.Println("listening on the docker socket, starting on the next connection")
+	}
+	return m, close, nil
+}
+
+type idleMonitor struct {
+	app     colimaApp
+	conf    config.Config
+	timeout time.Duration
+
+	sync.Mutex
+	lastActive time.Time
+	conns      int
+	// idle is true if the idle action has been applied.
+	idle bool
+}
+
+// check applies the idle action if the timeout has elapsed without activity.
+func (m *idleMonitor) check() {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.idle {
+		return
+	}
+	if m.conns > 0 || m.app.workloads() > 0 {
+		m.lastActive = time.Now()
+		return
+	}
+	if time.Since(m.lastActive) < m.timeout {
+		return
+	}
+	if !m.app.guest.Running() {
+		return
+	}
+
+	log.Printf("no activity for %v, applying idle action: %s", m.timeout, m.conf.Idle.IdleAction())
+	var err error
+	switch m.conf.Idle.IdleAction() {
+	case config.IdleActionPause:
+		err = lima.Pause(config.Profile().ID)
+	default:
+		err = runColima("stop")
+	}
+	if err != nil {
+		log.Warnln(fmt.Errorf("error applying idle action: %w", err))
+		// retried after another timeout
+		m.lastActive = time.Now()
+		return
+	}
+	m.idle = true
+	if m.conf.Idle.IdleAction() == config.IdleActionPause {
+		emitEvent(EventPaused, fmt.Sprintf("idle for %v", m.timeout))
+	}
+}
+
+// wake resumes the VM if the idle action has been applied.
+func (m *idleMonitor) wake() error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.lastActive = time.Now()
+	if !m.idle {
+		return nil
+	}
+
+	log.Println("connection received, resuming")
+	var err error
+	switch {
+	case lima.Paused(config.Profile().ID):
+		if err = lima.Resume(config.Profile().ID); err == nil {
+			m.app.syncClock()
+			emitEvent(EventResumed, "")
+		}
+	case !m.app.guest.Running():
+		err = runColima("start")
+	}
+	if err != nil {
+		return fmt.Errorf("error resuming: %w", err)
+	}
+	m.idle = false
+	return nil
+}
+
+// proxy forwards the connections of l to the target socket forwarded from the VM.
+func (m *idleMonitor) proxy(l net.Listener, target string) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return fmt.Errorf("error accepting connection: %w", err)
+		}
+		go m.forward(conn, target)
+	}
+}
+
+func (m *idleMonitor) forward(conn net.Conn, target string) {
+	defer conn.Close()
+
+	m.Lock()
+	m.conns++
+	m.Unlock()
+	defer func() {
+		m.Lock()
+		m.conns--
+		m.lastActive = time.Now()
+		m.Unlock()
+	}()
+
+	if err := m.wake(); err != nil {
+		log.Warnln(err)
+		return
+	}
+
+	var upstream net.Conn
+	// the socket may take a moment to be forwarded after the VM is resumed
+	err := cli.Backoff{Attempts: 10, Delay: time.Second, MaxDelay: 5 * time.Second}.Do(log.NewEntry(log.StandardLogger()), "connecting to docker socket", func() (err error) {
+		upstream, err = net.Dial("unix", target)
+		return err
+	})
+	if err != nil {
+		log.Warnln(err)
+		return
+	}
+	defer upstream.Close()
+
+	done := make(chan struct{}, 2)
+	copyConn := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// signal the end of the stream without terminating the response
+		if c, ok := dst.(*net.UnixConn); ok {
+			_ = c.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go copyConn(upstream, conn)
+	go copyConn(conn, upstream)
+	<-done
+	<-done
+}
+
+// workloads returns the number of running containers and Kubernetes pods,
+// excluding the Kubernetes system workloads that are always running.
+func (c colimaApp) workloads() int {
+	runtime, err := c.currentRuntime()
+	if err != nil {
+		return 0
+	}
+
+	var scripts []string
+	switch runtime {
+	case docker.Name:
+		scripts = append(scripts, `docker ps --format '{{.Label "io.kubernetes.pod.namespace"}}' | grep -vc '^kube-system$'`)
+	default:
+		scripts = append(scripts, `nerdctl --namespace default ps -q | wc -l`)
+	}
+	if k, err := c.containerEnvironment(kubernetes.Name); err == nil && k.Running() {
+		scripts = append(scripts, `k3s kubectl get pods -A --no-headers --field-selector=status.phase=Running 2>/dev/null | grep -vc '^kube-system '`)
+	}
+
+	count := 0
+	for _, script := range scripts {
+		// grep -c exits non-zero without matches
+		out, _ := c.guest.RunOutput("sudo", "sh", "-c", script+" || true")
+		n, err := strconv.Atoi(strings.TrimSpace(out))
+		if err != nil {
+			// considered active if the check fails, to not stop a busy VM
+			return 1
+		}
+		count += n
+	}
+	return count
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/util"
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxLogSize is the size a log file is rotated at.
+	maxLogSize = 10 * units.MiB
+	// maxLogFiles is the number of rotated files kept for each log.
+	maxLogFiles = 3
+)
+
+// logRotateInterval is the interval the monitor rotates the log files at.
+const logRotateInterval = time.Hour
+
+// hostLogFiles returns the log files on the host written by the VM and the port forwarder of the profile.
+// They are not written in append mode.
+func hostLogFiles() []string {
+	var files []string
+	if dir, err := lima.InstanceDir(config.Profile().ID); err == nil {
+		for _, f := range []string{"serial.log", "ha.stdout.log", "ha.stderr.log"} {
+			files = append(files, filepath.Join(dir, f))
+		}
+	}
+	return files
+}
+
+// networkLogFiles returns the log files on the host written by the networking daemon of the profile.
+// They are written in append mode.
+func networkLogFiles() []string {
+	dir := filepath.Join(config.Dir(), "network")
+	return []string{filepath.Join(dir, "vmnet.stdout"), filepath.Join(dir, "vmnet.stderr")}
+}
+
+// rotateLogs rotates the host log files of the profile.
+// It must be done while the VM is stopped, the files are held open by the running processes.
+func rotateLogs() {
+	for _, f := range append(hostLogFiles(), networkLogFiles()...) {
+		if err := util.RotateFile(f, maxLogSize, maxLogFiles); err != nil {
+			// not fatal
+			log.Warnln(err)
+		}
+	}
+}
+
+// rotateOpenLogs rotates the log files written in append mode while the VM is running,
+// of the networking daemon and the monitor. The log files of the VM are rotated on start and stop.
+func rotateOpenLogs() {
+	for _, f := range append(networkLogFiles(), MonitorLogFile()) {
+		if err := util.RotateOpenFile(f, maxLogSize, maxLogFiles); err != nil {
+			// not fatal
+			log.Warnln(err)
+		}
+	}
+}
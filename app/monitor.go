@@ -0,0 +1,239 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// monitorEnvVar is set for the colima processes started by the monitor.
+const monitorEnvVar = "COLIMA_MONITOR"
+
+func monitorPidFile() string { return filepath.Join(config.Dir(), "monitor.pid") }
+
+// MonitorLogFile returns the path to the log file of the monitor.
+func MonitorLogFile() string { return filepath.Join(config.Dir(), "monitor.log") }
+
+// monitorEnabled returns if a policy applied by the monitor is enabled in conf.
+func monitorEnabled(conf config.Config) bool {
+	return idleConfigured(conf) || socketProxied(conf) || conf.Health.Enabled() || len(conf.Tunnels) > 0 || conf.VM.ForwardAgent ||
+		followTimezone(conf) || conf.Remote != ""
+}
+
+// followTimezone returns if the timezone of the host is followed in the VM by the monitor,
+// the timezone of a remote host is left as is.
+func followTimezone(conf config.Config) bool { return conf.VM.Timezone == "" && conf.Remote == "" }
+
+// startedByMonitor returns if the current process was started by the monitor.
+func startedByMonitor() bool { return os.Getenv(monitorEnvVar) != "" }
+
+// monitorPid returns the pid of the running monitor of the profile, or 0 if not running.
+func monitorPid() int {
+	b, err := os.ReadFile(monitorPidFile())
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || syscall.Kill(pid, 0) != nil {
+		return 0
+	}
+	return pid
+}
+
+// RestartMonitor (re)starts the monitor of the profile in the background to apply conf,
+// or stops it if no policy is enabled.
+func RestartMonitor(conf config.Config) error {
+	if startedByMonitor() {
+		return nil
+	}
+	stopMonitor()
+	if !monitorEnabled(conf) {
+		return nil
+	}
+
+	executable, err := colimaExecutable()
+	if err != nil {
+		return err
+	}
+
+	// not held open while the monitor is stopped
+	_ = util.RotateFile(MonitorLogFile(), maxLogSize, maxLogFiles)
+	f, err := os.OpenFile(MonitorLogFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening monitor log file: %w", err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command(executable, "monitor", "--profile", config.Profile().ShortName)
+	cmd.Stdout = f
+	cmd.Stderr = f
+	// detach from the terminal session
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting monitor: %w", err)
+	}
+	if err := os.WriteFile(monitorPidFile(), []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("error writing monitor pid file: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// stopMonitor stops the monitor of the profile and waits for it to exit,
+// unless the current process was started by the monitor.
+func stopMonitor() {
+	if startedByMonitor() {
+		return
+	}
+	if pid := monitorPid(); pid > 0 {
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+			log.Warnln(fmt.Errorf("error stopping monitor: %w", err))
+		}
+		// the sockets are removed on exit
+		for i := 0; i < 50 && syscall.Kill(pid, 0) == nil; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	_ = os.Remove(monitorPidFile())
+}
+
+// Monitor applies the idle policy, the lazy start, the health checks, the tunnels, the ssh agent forwarding,
+// the host timezone and the socket forwarding of a remote host of the profile in the foreground,
+// until the process is terminated.
+func (c colimaApp) Monitor() error {
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !monitorEnabled(conf) {
+		return fmt.Errorf("no idle policy, lazy start, health checks, tunnels, ssh agent forwarding, host timezone or remote host are configured for %s", config.Profile().DisplayName)
+	}
+
+	sessions, err := tunnelSessions(conf)
+	if err != nil {
+		return err
+	}
+	if conf.VM.ForwardAgent {
+		sessions = append(sessions, agentSession())
+	}
+	if conf.Remote != "" {
+		sessions = append(sessions, socketSession())
+	}
+	if len(sessions) > 0 {
+		stopSessions, err := startSessions(sessions)
+		if err != nil {
+			return err
+		}
+		defer stopSessions()
+	}
+
+	errCh := make(chan error, 1)
+
+	var idle *idleMonitor
+	var idleTick <-chan time.Time
+	if idleConfigured(conf) && conf.Runtime == docker.Name && !socketProxied(conf) {
+		// the docker socket of the VM is not proxied, it would not resume the VM
+		log.Warnln("the idle policy and lazy start apply to", config.Profile().DisplayName, "after 'colima restart'")
+	} else if idleConfigured(conf) || socketProxied(conf) {
+		m, closeIdle, err := c.newIdleMonitor(conf, errCh)
+		if err != nil {
+			return err
+		}
+		defer closeIdle()
+		idle = m
+	}
+	if idle != nil && conf.Idle.Enabled() {
+		ticker := time.NewTicker(idleCheckInterval)
+		defer ticker.Stop()
+		idleTick = ticker.C
+	}
+
+	var health *healthMonitor
+	var healthTick <-chan time.Time
+	if conf.Health.Enabled() {
+		health = c.newHealthMonitor(conf)
+		ticker := time.NewTicker(conf.Health.IntervalDuration())
+		defer ticker.Stop()
+		healthTick = ticker.C
+	}
+
+	var timezone *timezoneMonitor
+	var timezoneTick <-chan time.Time
+	if followTimezone(conf) {
+		// applied on start
+		zone, _ := hostTimezone()
+		timezone = &timezoneMonitor{app: c, zone: zone}
+		ticker := time.NewTicker(timezoneCheckInterval)
+		defer ticker.Stop()
+		timezoneTick = ticker.C
+	}
+
+	// the VM may run for long without a stop
+	logTicker := time.NewTicker(logRotateInterval)
+	defer logTicker.Stop()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+
+	log.Println("monitor started")
+	for {
+		select {
+		case err := <-errCh:
+			return err
+		case <-sigCh:
+			log.Println("monitor stopped")
+			return nil
+		case <-idleTick:
+			idle.check()
+		case <-healthTick:
+			health.check()
+		case <-timezoneTick:
+			timezone.check()
+		case <-logTicker.C:
+			rotateOpenLogs()
+		}
+	}
+}
+
+// Executable is the path to the colima executable run by the monitor and its commands.
+// If empty, it is the current executable if it is colima, or colima in PATH, e.g. for programs
+// embedding the profile management.
+var Executable string
+
+// colimaExecutable returns the path to the colima executable.
+func colimaExecutable() (string, error) {
+	if Executable != "" {
+		return Executable, nil
+	}
+	if executable, err := os.Executable(); err == nil && filepath.Base(executable) == config.AppName {
+		return executable, nil
+	}
+	executable, err := exec.LookPath(config.AppName)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving colima executable: %w", err)
+	}
+	return executable, nil
+}
+
+// runColima runs the colima command for the profile in a separate process.
+func runColima(command string) error {
+	executable, err := colimaExecutable()
+	if err != nil {
+		return err
+	}
+	cmd := cli.Command(executable, command, "--profile", config.Profile().ShortName)
+	cmd.Env = append(os.Environ(), monitorEnvVar+"=1")
+	return cmd.Run()
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/abiosoft/colima/config"
+)
+
+func Test_monitorEnabled(t *testing.T) {
+	tests := []struct {
+		conf config.Config
+		want bool
+	}{
+		// the timezone of the host is followed by default
+		{conf: config.Config{}, want: true},
+		{conf: config.Config{VM: config.VM{Timezone: "UTC"}}},
+		{conf: config.Config{VM: config.VM{Timezone: "UTC", ForwardAgent: true}}, want: true},
+		{conf: config.Config{Remote: "user@host"}, want: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := monitorEnabled(tt.conf); got != tt.want {
+				t.Errorf("monitorEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util/notify"
+	log "github.com/sirupsen/logrus"
+)
+
+// lowDiskPercent is the percentage of free disk space in the VM considered low.
+const lowDiskPercent = 10
+
+// sendNotification posts a desktop notification for the profile if enabled in conf.
+// Failures are not fatal.
+func sendNotification(conf config.Config, message string) {
+	if !conf.Notify {
+		return
+	}
+	if err := notify.Send("Colima", config.Profile().DisplayName+" "+message); err != nil {
+		log.Warnln(fmt.Errorf("error sending notification: %w", err))
+	}
+}
+
+// checkDisk warns, and notifies, if the free disk space for the container runtime data is low.
+func (c colimaApp) checkDisk(conf config.Config) {
+	free, err := c.diskFree(conf.Runtime)
+	if err != nil {
+		log.Warnln(fmt.Errorf("error checking disk usage: %w", err))
+		return
+	}
+	if free < lowDiskPercent {
+		message := fmt.Sprintf("is low on disk space, %d%% free", free)
+		log.Warnln(config.Profile().DisplayName, message+", prune unused images and volumes to free up space")
+		sendNotification(conf, message)
+		emitEvent(EventDiskLow, fmt.Sprintf("%d%% free", free))
+	}
+}
+
+// diskFree returns the percentage of free disk space for the data of the container runtime.
+func (c colimaApp) diskFree(runtime string) (int, error) {
+	dirs := runtimeDataDirs[runtime]
+	if len(dirs) == 0 {
+		return 100, nil
+	}
+	// Filesystem 1024-blocks Used Available Capacity Mounted on
+	out, err := c.guest.RunOutput("df", "-P", dirs[0])
+	if err != nil {
+		return 0, err
+	}
+	lines := strings.Split(out, "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 5 {
+		return 0, fmt.Errorf("invalid df output: %s", out)
+	}
+	used, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid df output: %w", err)
+	}
+	return 100 - used, nil
+}
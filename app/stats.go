@@ -0,0 +1,178 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+)
+
+// Stats is the resource usage of the VM. Sizes are in bytes.
+type Stats struct {
+	Profile string `json:"profile"`
+	// CPU is the CPU usage in percent of all CPUs, sampled over a second.
+	CPU         float64 `json:"cpu_percent"`
+	CPUs        int     `json:"cpus"`
+	MemoryUsed  int64   `json:"memory_used"`
+	MemoryTotal int64   `json:"memory_total"`
+	// Disk is the usage of the disk holding the container runtime data.
+	DiskUsed  int64 `json:"disk_used"`
+	DiskTotal int64 `json:"disk_total"`
+	// NetworkRx and NetworkTx are the bytes received and sent since boot, excluding loopback.
+	NetworkRx int64 `json:"network_rx"`
+	NetworkTx int64 `json:"network_tx"`
+	// Uptime is the uptime of the VM in seconds.
+	Uptime int64 `json:"uptime"`
+	// Ports are the TCP ports listening in the VM, forwarded to the host.
+	Ports []int `json:"ports,omitempty"`
+}
+
+// statsSeparator separates the outputs of the commands of the stats script.
+const statsSeparator = "---"
+
+func (c colimaApp) Stats() (Stats, error) {
+	stats := Stats{Profile: config.Profile().ShortName}
+	if !c.guest.Running() {
+		return stats, fmt.Errorf("%s %w", config.Profile().DisplayName, ErrNotRunning)
+	}
+
+	disk := "/"
+	if r, err := c.currentRuntime(); err == nil && len(runtimeDataDirs[r]) > 0 {
+		disk = runtimeDataDirs[r][0]
+	}
+
+	// a single invocation for all stats, every command is a round trip to the VM.
+	script := strings.Join([]string{
+		"head -n 1 /proc/stat; sleep 1; head -n 1 /proc/stat",
+		"nproc",
+		"cat /proc/meminfo",
+		"df -Pk " + disk + " | tail -n 1",
+		"cat /proc/net/dev",
+		"cat /proc/uptime",
+		"netstat -tln",
+	}, "; echo "+statsSeparator+"; ")
+	out, err := c.guest.RunOutput("sh", "-c", script)
+	if err != nil {
+		return stats, fmt.Errorf("error retrieving stats: %w", err)
+	}
+	sections := strings.Split(out, statsSeparator+"\n")
+	if len(sections) != 7 {
+		return stats, fmt.Errorf("error retrieving stats: unexpected output")
+	}
+
+	stats.CPU = parseCPUUsage(sections[0])
+	stats.CPUs, _ = strconv.Atoi(strings.TrimSpace(sections[1]))
+	stats.MemoryTotal, stats.MemoryUsed = parseMeminfo(sections[2])
+
+	// Filesystem 1024-blocks Used Available Capacity Mounted on
+	if fields := strings.Fields(sections[3]); len(fields) >= 4 {
+		total, _ := strconv.ParseInt(fields[1], 10, 64)
+		used, _ := strconv.ParseInt(fields[2], 10, 64)
+		stats.DiskTotal, stats.DiskUsed = total*1024, used*1024
+	}
+
+	stats.NetworkRx, stats.NetworkTx = parseNetDev(sections[4])
+	if fields := strings.Fields(sections[5]); len(fields) > 0 {
+		uptime, _ := strconv.ParseFloat(fields[0], 64)
+		stats.Uptime = int64(uptime)
+	}
+	stats.Ports = parseListeningPorts(sections[6])
+	return stats, nil
+}
+
+// parseListeningPorts returns the ports listening on IPv4 addresses in the output of netstat -tln.
+// Lima forwards these ports to the host, except for SSH.
+func parseListeningPorts(s string) []int {
+	seen := map[int]bool{}
+	var ports []int
+	for _, line := range strings.Split(s, "\n") {
+		// tcp 0 0 0.0.0.0:6443 0.0.0.0:* LISTEN
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[len(fields)-1] != "LISTEN" {
+			continue
+		}
+		addr := fields[3]
+		i := strings.LastIndex(addr, ":")
+		if i < 0 {
+			continue
+		}
+		if strings.Contains(addr[:i], ":") {
+			// IPv6
+			continue
+		}
+		port, err := strconv.Atoi(addr[i+1:])
+		if err != nil || port == 22 || seen[port] {
+			continue
+		}
+		seen[port] = true
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// parseCPUUsage returns the CPU usage in percent between two samples of the cpu line of /proc/stat.
+func parseCPUUsage(s string) float64 {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) != 2 {
+		return 0
+	}
+	// cpu user nice system idle iowait irq softirq steal ...
+	sample := func(line string) (idle, total int64) {
+		for i, f := range strings.Fields(line)[1:] {
+			v, _ := strconv.ParseInt(f, 10, 64)
+			total += v
+			if i == 3 || i == 4 { // idle, iowait
+				idle += v
+			}
+		}
+		return idle, total
+	}
+	idle1, total1 := sample(lines[0])
+	idle2, total2 := sample(lines[1])
+	if total2 <= total1 {
+		return 0
+	}
+	return 100 * float64((total2-total1)-(idle2-idle1)) / float64(total2-total1)
+}
+
+// parseMeminfo returns the total and used memory in /proc/meminfo.
+func parseMeminfo(s string) (total, used int64) {
+	var available int64
+	for _, line := range strings.Split(s, "\n") {
+		// MemTotal:        2035024 kB
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		v, _ := strconv.ParseInt(fields[1], 10, 64)
+		switch fields[0] {
+		case "MemTotal:":
+			total = v * 1024
+		case "MemAvailable:":
+			available = v * 1024
+		}
+	}
+	return total, total - available
+}
+
+// parseNetDev returns the bytes received and sent by all interfaces in /proc/net/dev, excluding loopback.
+func parseNetDev(s string) (rx, tx int64) {
+	for _, line := range strings.Split(s, "\n") {
+		// eth0: 1234 12 0 0 0 0 0 0 5678 34 0 0 0 0 0 0
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "lo" {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) < 9 {
+			continue
+		}
+		r, _ := strconv.ParseInt(fields[0], 10, 64)
+		t, _ := strconv.ParseInt(fields[8], 10, 64)
+		rx, tx = rx+r, tx+t
+	}
+	return rx, tx
+}
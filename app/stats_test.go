@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_parseCPUUsage(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		// 100 jiffies, 75 idle and iowait
+		{s: "cpu  100 0 50 800 50 0 0 0 0 0\ncpu  110 0 60 870 55 0 5 0 0 0", want: 25},
+		{s: "cpu  100 0 50 800 50 0 0 0 0 0\ncpu  100 0 50 900 50 0 0 0 0 0", want: 0},
+		{s: "cpu  100 0 50 800 50 0 0 0 0 0\ncpu  200 0 50 800 50 0 0 0 0 0", want: 100},
+		// no elapsed time
+		{s: "cpu  100 0 50 800 50 0 0 0 0 0\ncpu  100 0 50 800 50 0 0 0 0 0", want: 0},
+		{s: "cpu  100 0 50 800 50 0 0 0 0 0", want: 0},
+		{s: "", want: 0},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := parseCPUUsage(tt.s); got != tt.want {
+				t.Errorf("parseCPUUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseMeminfo(t *testing.T) {
+	tests := []struct {
+		s               string
+		total, wantUsed int64
+	}{
+		{
+			s:        "MemTotal:        2000 kB\nMemFree:          500 kB\nMemAvailable:    1500 kB\nBuffers:          100 kB",
+			total:    2000 * 1024,
+			wantUsed: 500 * 1024,
+		},
+		{s: "MemTotal:        2000 kB", total: 2000 * 1024, wantUsed: 2000 * 1024},
+		{s: ""},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			total, used := parseMeminfo(tt.s)
+			if total != tt.total || used != tt.wantUsed {
+				t.Errorf("parseMeminfo() = %d, %d, want %d, %d", total, used, tt.total, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func Test_parseNetDev(t *testing.T) {
+	header := "Inter-|   Receive                                                |  Transmit\n" +
+		" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n"
+	tests := []struct {
+		s      string
+		rx, tx int64
+	}{
+		{
+			s: header +
+				"    lo:     900      10    0    0    0     0          0         0      900      10    0    0    0     0       0          0\n" +
+				"  eth0:    1234      12    0    0    0     0          0         0     5678      34    0    0    0     0       0          0\n" +
+				"  col0:     100       1    0    0    0     0          0         0      200       2    0    0    0     0       0          0",
+			rx: 1334,
+			tx: 5878,
+		},
+		{s: header},
+		{s: ""},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			rx, tx := parseNetDev(tt.s)
+			if rx != tt.rx || tx != tt.tx {
+				t.Errorf("parseNetDev() = %d, %d, want %d, %d", rx, tx, tt.rx, tt.tx)
+			}
+		})
+	}
+}
+
+func Test_parseListeningPorts(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []int
+	}{
+		{
+			s: "Active Internet connections (only servers)\n" +
+				"Proto Recv-Q Send-Q Local Address           Foreign Address         State\n" +
+				"tcp        0      0 0.0.0.0:22              0.0.0.0:*               LISTEN\n" +
+				"tcp        0      0 127.0.0.1:6443          0.0.0.0:*               LISTEN\n" +
+				"tcp        0      0 0.0.0.0:8080            0.0.0.0:*               LISTEN\n" +
+				"tcp        0      0 0.0.0.0:80              0.0.0.0:*               LISTEN\n" +
+				"tcp        0      0 :::8080                 :::*                    LISTEN\n" +
+				"tcp        0      0 :::9000                 :::*                    LISTEN\n" +
+				"tcp        0      0 10.0.2.15:41234         10.0.2.2:443            ESTABLISHED",
+			want: []int{80, 6443, 8080},
+		},
+		{s: ""},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := parseListeningPorts(tt.s); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseListeningPorts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
+	"github.com/abiosoft/colima/environment/vm/lima"
+)
+
+var (
+	// ErrNotCreated is returned when the profile has not been created.
+	ErrNotCreated = errors.New("does not exist")
+	// ErrNotRunning is returned when the profile is not running.
+	ErrNotRunning = errors.New("is not running")
+	// ErrPaused is returned when the profile is paused.
+	ErrPaused = errors.New("is paused")
+)
+
+// StatusInfo is the status of a Colima profile.
+type StatusInfo struct {
+	Profile    string            `json:"profile" yaml:"profile"`
+	Created    bool              `json:"created" yaml:"created"`
+	Running    bool              `json:"running" yaml:"running"`
+	Paused     bool              `json:"paused,omitempty" yaml:"paused,omitempty"`
+	Runtime    string            `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	Arch       string            `json:"arch,omitempty" yaml:"arch,omitempty"`
+	Emulated   bool              `json:"emulated,omitempty" yaml:"emulated,omitempty"`
+	CPU        int               `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory     int64             `json:"memory,omitempty" yaml:"memory,omitempty"`
+	Disk       int64             `json:"disk,omitempty" yaml:"disk,omitempty"`
+	IPAddress  string            `json:"address,omitempty" yaml:"address,omitempty"`
+	SSHPort    int               `json:"ssh_port,omitempty" yaml:"ssh_port,omitempty"`
+	Kubernetes bool              `json:"kubernetes" yaml:"kubernetes"`
+	Mounts     []string          `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+	Uptime     string            `json:"uptime,omitempty" yaml:"uptime,omitempty"`
+	Versions   map[string]string `json:"versions,omitempty" yaml:"versions,omitempty"`
+	// Restarts are the number of restarts of crashed services by the watchdog since the VM started.
+	Restarts map[string]int `json:"restarts,omitempty" yaml:"restarts,omitempty"`
+	// Degraded are the unrepaired problems found by the health checks.
+	Degraded []string `json:"degraded,omitempty" yaml:"degraded,omitempty"`
+	// Network is the state of the network with the reachable address, up or down, empty if not configured.
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+	// DockerSocket is the Docker host of the docker context, for the docker runtime.
+	DockerSocket string `json:"docker_socket,omitempty" yaml:"docker_socket,omitempty"`
+	// KubeContext is the kubeconfig context of the Kubernetes cluster, and KubeContextCurrent
+	// if it is the current context of kubectl.
+	KubeContext        string `json:"kube_context,omitempty" yaml:"kube_context,omitempty"`
+	KubeContextCurrent bool   `json:"kube_context_current,omitempty" yaml:"kube_context_current,omitempty"`
+	// Remote is the remote host that backs the profile instead of a VM, if any.
+	Remote string `json:"remote,omitempty" yaml:"remote,omitempty"`
+}
+
+func (c colimaApp) StatusInfo() (StatusInfo, error) {
+	status := StatusInfo{Profile: config.Profile().ShortName}
+	if !c.guest.Running() {
+		status.Created = c.guest.Created()
+		return status, nil
+	}
+	status.Created = true
+	status.Running = true
+
+	// the VM cannot be queried while paused
+	if lima.Paused(config.Profile().ID) {
+		status.Paused = true
+		return status, nil
+	}
+
+	currentRuntime, err := c.currentRuntime()
+	if err != nil {
+		return status, err
+	}
+	status.Runtime = currentRuntime
+	status.Arch = string(c.guest.Arch())
+	status.Emulated = c.guest.Arch().Emulated()
+
+	if inst, err := lima.Instance(config.Profile().ID); err == nil {
+		status.CPU = inst.CPU
+		status.Memory = inst.Memory
+		status.Disk = inst.Disk
+		status.IPAddress = inst.IPAddress
+		status.SSHPort = inst.SSHPort
+		if len(inst.Network) > 0 {
+			// the address is retrieved from the interface in the VM
+			status.Network = "down"
+			if inst.IPAddress != "" {
+				status.Network = "up"
+			}
+		}
+	}
+	if conf, err := config.Load(); err == nil {
+		if currentRuntime == docker.Name {
+			// the endpoint of the docker context, ssh for the ssh transport
+			status.DockerSocket = docker.Host(conf)
+		}
+		status.Remote = conf.Remote
+		if conf.Remote != "" {
+			// a remote host is not emulated, regardless of the architecture of the host
+			status.Emulated = false
+		}
+		status.Mounts = conf.VM.Mounts
+		if h, ok := readHealth(conf.Health.IntervalDuration()); ok && conf.Health.Enabled() {
+			status.Degraded = h.Degraded()
+		}
+	}
+	if len(status.Mounts) == 0 && status.Remote == "" {
+		// default mounts
+		status.Mounts = []string{"~:w", "/tmp/" + config.Profile().ID + ":w"}
+	}
+
+	if uptime, err := c.uptime(); err == nil {
+		status.Uptime = uptime.String()
+	}
+
+	status.Versions = map[string]string{}
+	if containers, err := c.currentContainerEnvironments(); err == nil {
+		for _, cont := range containers {
+			if cont.Name() == kubernetes.Name {
+				status.Kubernetes = true
+				status.KubeContext = config.Profile().ID
+				current, _ := exec.Command("kubectl", "config", "current-context").Output()
+				status.KubeContextCurrent = strings.TrimSpace(string(current)) == status.KubeContext
+			}
+			status.Versions[cont.Name()] = cont.Version()
+		}
+	}
+
+	if out, err := c.guest.RunOutput("sudo", "cat", lima.WatchdogLogFile); err == nil {
+		status.Restarts = parseWatchdogLog(out)
+	}
+
+	return status, nil
+}
+
+// parseWatchdogLog returns the number of restarts per service in the watchdog log,
+// since the last start of the watchdog.
+func parseWatchdogLog(out string) map[string]int {
+	restarts := map[string]int{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[1] {
+		case "started":
+			restarts = map[string]int{}
+		case "restarted":
+			if len(fields) > 2 {
+				restarts[fields[2]]++
+			}
+		}
+	}
+	if len(restarts) == 0 {
+		return nil
+	}
+	return restarts
+}
+
+func (c colimaApp) uptime() (time.Duration, error) {
+	out, err := c.guest.RunOutput("cat", "/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid uptime: %s", out)
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid uptime: %w", err)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
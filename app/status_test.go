@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_parseWatchdogLog(t *testing.T) {
+	tests := []struct {
+		out  string
+		want map[string]int
+	}{
+		{out: ""},
+		{out: "1650000000 started\n1650000010 crashed docker\n1650000011 restarted docker\n" +
+			"1650000020 restarted k3s\n1650000030 restarted docker\n",
+			want: map[string]int{"docker": 2, "k3s": 1}},
+		// since the last start of the watchdog
+		{out: "1650000000 started\n1650000011 restarted docker\n1650000100 started\n1650000111 restarted k3s\n",
+			want: map[string]int{"k3s": 1}},
+		// failed restarts and clock syncs are not restarts
+		{out: "1650000000 started\n1650000010 failed docker\n1650000020 clock-synced 12s\n1650000030 restarted\n"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := parseWatchdogLog(tt.out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWatchdogLog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
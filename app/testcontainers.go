@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/host"
+	"github.com/abiosoft/colima/environment/vm/lima"
+)
+
+// ryukImage is the image of the Ryuk container started by Testcontainers to remove the test containers.
+const ryukImage = "testcontainers/ryuk:0.3.4"
+
+// TestcontainersEnv returns ProfileEnv with the environment variables for Testcontainers with the docker runtime of the
+// current profile. Ryuk mounts the docker socket of the VM, the socket on the host cannot be mounted into containers.
+func TestcontainersEnv(conf config.Config) ([]string, error) {
+	if conf.Runtime != docker.Name {
+		return nil, fmt.Errorf("testcontainers requires the %s runtime", docker.Name)
+	}
+	env := ProfileEnv(conf)
+	if conf.DockerTransport == config.DockerTransportSSH {
+		// ssh is not supported by Testcontainers, the socket is forwarded regardless
+		for i, e := range env {
+			if strings.HasPrefix(e, "DOCKER_HOST=") {
+				env[i] = "DOCKER_HOST=unix://" + docker.HostSocketFile()
+			}
+		}
+	}
+	env = append(env, "TESTCONTAINERS_DOCKER_SOCKET_OVERRIDE=/var/run/docker.sock")
+	// the mapped ports are reachable on the address of the VM, forwarded to localhost otherwise
+	if address := lima.IPAddress(config.Profile().ID); address != "127.0.0.1" {
+		env = append(env, "TESTCONTAINERS_HOST_OVERRIDE="+address)
+	}
+	return env, nil
+}
+
+// VerifyRyuk starts the Ryuk container of Testcontainers with the docker socket of the VM,
+// through the docker socket on the host as the tests would, and waits for it to be ready.
+func (c colimaApp) VerifyRyuk() error {
+	if !c.guest.Running() {
+		return fmt.Errorf("%s %w", config.Profile().DisplayName, ErrNotRunning)
+	}
+	h := host.New()
+	dockerHost := "unix://" + docker.HostSocketFile()
+
+	id, err := h.RunOutput("docker", "--host", dockerHost, "run", "-d", "--rm",
+		"-v", "/var/run/docker.sock:/var/run/docker.sock",
+		// exits if nothing connects, after the check
+		"-e", "RYUK_CONNECTION_TIMEOUT=10s",
+		ryukImage)
+	if err != nil {
+		return fmt.Errorf("error starting ryuk: %w", err)
+	}
+	defer func() { _ = h.RunQuiet("docker", "--host", dockerHost, "rm", "-f", id) }()
+
+	// Ryuk logs Started! once connected to docker and listening
+	var logs string
+	for i := 0; i < 30; i++ {
+		logs, _ = h.RunOutput("docker", "--host", dockerHost, "logs", id)
+		if strings.Contains(logs, "Started") {
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("ryuk did not start: %s", logs)
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+)
+
+func TestTestcontainersEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		transport string
+		want      string
+	}{
+		{transport: config.DockerTransportSSH, want: "DOCKER_HOST=unix://" + docker.HostSocketFile()},
+		{want: "DOCKER_HOST=unix://" + docker.HostSocketFile()},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			env, err := TestcontainersEnv(config.Config{Runtime: docker.Name, DockerTransport: tt.transport})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var hosts []string
+			for _, e := range env {
+				if strings.HasPrefix(e, "DOCKER_HOST=") {
+					hosts = append(hosts, e)
+				}
+			}
+			if len(hosts) != 1 || hosts[0] != tt.want {
+				t.Errorf("TestcontainersEnv() DOCKER_HOST = %v, want [%s]", hosts, tt.want)
+			}
+		})
+	}
+
+	if _, err := TestcontainersEnv(config.Config{Runtime: "containerd"}); err == nil {
+		t.Error("TestcontainersEnv() error = nil for containerd")
+	}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// timezoneCheckInterval is the interval between the checks of the host timezone by the monitor.
+const timezoneCheckInterval = time.Minute
+
+// zoneinfoDirs are the directories of the tz database on the host.
+var zoneinfoDirs = []string{"/usr/share/zoneinfo", "/var/db/timezone/zoneinfo"}
+
+// zoneFromPath returns the timezone of a path in a zoneinfo directory, e.g. /usr/share/zoneinfo/Europe/Berlin.
+func zoneFromPath(path string) (string, bool) {
+	i := strings.Index(path, "zoneinfo/")
+	if i < 0 {
+		return "", false
+	}
+	zone := path[i+len("zoneinfo/"):]
+	return zone, util.ValidTimezone(zone)
+}
+
+// hostTimezone returns the timezone of the host, from $TZ, the /etc/localtime symlink or /etc/timezone.
+func hostTimezone() (string, error) {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if zone, ok := zoneFromPath(tz); ok {
+			return zone, nil
+		}
+		if util.ValidTimezone(tz) {
+			return tz, nil
+		}
+	}
+	if link, err := os.Readlink("/etc/localtime"); err == nil {
+		if zone, ok := zoneFromPath(link); ok {
+			return zone, nil
+		}
+	}
+	// copied rather than linked on some Linux distributions
+	if b, err := os.ReadFile("/etc/timezone"); err == nil {
+		if zone := strings.TrimSpace(string(b)); util.ValidTimezone(zone) {
+			return zone, nil
+		}
+	}
+	return "", fmt.Errorf("error retrieving host timezone, set it with --timezone")
+}
+
+// vmTimezone returns the timezone of the VM for conf, the host timezone unless set in conf.
+func vmTimezone(conf config.Config) (string, error) {
+	if conf.VM.Timezone != "" {
+		return conf.VM.Timezone, nil
+	}
+	return hostTimezone()
+}
+
+// syncTimezone sets the timezone of the VM to zone, if not already set.
+func (c colimaApp) syncTimezone(zone string) error {
+	if !util.ValidTimezone(zone) {
+		return fmt.Errorf("invalid timezone '%s'", zone)
+	}
+	if current, _ := c.guest.RunOutput("cat", "/etc/timezone"); strings.TrimSpace(current) == zone {
+		return nil
+	}
+
+	var data []byte
+	var err error
+	for _, dir := range zoneinfoDirs {
+		if data, err = os.ReadFile(filepath.Join(dir, zone)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error reading timezone '%s': %w", zone, err)
+	}
+
+	if err := environment.WriteFile(c.guest, "/etc/localtime", 0644, string(data)); err != nil {
+		return fmt.Errorf("error setting timezone: %w", err)
+	}
+	if err := environment.WriteFile(c.guest, "/etc/timezone", 0644, zone+"\n"); err != nil {
+		return fmt.Errorf("error setting timezone: %w", err)
+	}
+	log.Println("timezone set to", zone)
+	return nil
+}
+
+// applyTimezone sets the timezone of the VM for conf.
+// It is not fatal, the VM defaults to UTC.
+func (c colimaApp) applyTimezone(conf config.Config) {
+	if conf.Remote != "" {
+		// the timezone of a remote host is left as is
+		return
+	}
+	zone, err := vmTimezone(conf)
+	if err == nil {
+		err = c.syncTimezone(zone)
+	}
+	if err != nil {
+		log.Warnln(err)
+	}
+}
+
+// timezoneMonitor follows the timezone of the host in the VM.
+type timezoneMonitor struct {
+	app  colimaApp
+	zone string
+}
+
+// check applies the timezone of the host to the VM if it has changed.
+func (m *timezoneMonitor) check() {
+	zone, err := hostTimezone()
+	if err != nil || zone == m.zone {
+		return
+	}
+	// stopped or paused, e.g. by the idle policy
+	if !m.app.guest.Running() || lima.Paused(config.Profile().ID) {
+		return
+	}
+	if err := m.app.syncTimezone(zone); err != nil {
+		log.Warnln(err)
+		return
+	}
+	m.zone = zone
+}
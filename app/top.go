@@ -0,0 +1,112 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/containerd"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/docker/go-units"
+)
+
+// ContainerStats is the resource usage of a container. Memory is in bytes.
+type ContainerStats struct {
+	// Namespace is the containerd namespace, or the Kubernetes namespace of pods run by docker.
+	Namespace     string  `json:"namespace,omitempty"`
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	CPU           float64 `json:"cpu_percent"`
+	Memory        int64   `json:"memory"`
+	MemoryPercent float64 `json:"memory_percent"`
+}
+
+// runtimeStats is a line of the json output of 'docker stats' and 'nerdctl stats'.
+type runtimeStats struct {
+	ID       string `json:"ID"`
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"`
+	MemPerc  string `json:"MemPerc"`
+}
+
+func (c colimaApp) Top() ([]ContainerStats, error) {
+	r, err := c.currentRuntime()
+	if err != nil {
+		return nil, err
+	}
+
+	switch r {
+	case docker.Name:
+		stats, err := c.runtimeStats("docker")
+		if err != nil {
+			return nil, err
+		}
+		for i, s := range stats {
+			stats[i].Namespace = kubernetesNamespace(s.Name)
+		}
+		return stats, nil
+
+	case containerd.Name:
+		out, err := c.guest.RunOutput("sudo", "nerdctl", "namespace", "ls", "-q")
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving namespaces: %w", err)
+		}
+		var all []ContainerStats
+		for _, ns := range strings.Fields(out) {
+			stats, err := c.runtimeStats("nerdctl", "--namespace", ns)
+			if err != nil {
+				return nil, err
+			}
+			for i := range stats {
+				stats[i].Namespace = ns
+			}
+			all = append(all, stats...)
+		}
+		return all, nil
+	}
+
+	return nil, fmt.Errorf("container stats not supported for %s runtime", r)
+}
+
+// runtimeStats returns the container stats reported by the runtime command in the VM.
+func (c colimaApp) runtimeStats(command ...string) ([]ContainerStats, error) {
+	args := append(append([]string{"sudo"}, command...), "stats", "--no-stream", "--format", "{{json .}}")
+	out, err := c.guest.RunOutput(args...)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving container stats for %s: %w", config.Profile().DisplayName, err)
+	}
+
+	var stats []ContainerStats
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var s runtimeStats
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			return nil, fmt.Errorf("error parsing container stats: %w", err)
+		}
+		stat := ContainerStats{ID: s.ID, Name: s.Name}
+		stat.CPU, _ = strconv.ParseFloat(strings.TrimSuffix(s.CPUPerc, "%"), 64)
+		stat.MemoryPercent, _ = strconv.ParseFloat(strings.TrimSuffix(s.MemPerc, "%"), 64)
+		// 12.5MiB / 1.944GiB
+		if usage := strings.SplitN(s.MemUsage, "/", 2); len(usage) > 0 {
+			stat.Memory, _ = units.RAMInBytes(strings.TrimSpace(usage[0]))
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// kubernetesNamespace returns the Kubernetes namespace of a container run by docker
+// for a pod, or an empty string for other containers.
+// The names are of the form k8s_<container>_<pod>_<namespace>_<uid>_<attempt>.
+func kubernetesNamespace(name string) string {
+	parts := strings.Split(name, "_")
+	if len(parts) != 6 || parts[0] != "k8s" {
+		return ""
+	}
+	return parts[3]
+}
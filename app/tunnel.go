@@ -0,0 +1,139 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+// sessionMaxDelay is the maximum delay before an ssh session that exited is reopened.
+const sessionMaxDelay = 30 * time.Second
+
+// TunnelActive returns if the local address of the tunnel is accepting connections.
+func TunnelActive(t config.Tunnel) bool {
+	conn, err := net.DialTimeout("tcp", t.Local, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// sshSession is a connection to the VM kept open by the monitor.
+type sshSession struct {
+	// name describes the session in the logs.
+	name string
+	// args are the ssh arguments, prior to the host and the command.
+	args []string
+	// command is the command run in the VM, if any.
+	command string
+}
+
+// tunnelSessions returns the sessions for the tunnels of conf.
+func tunnelSessions(conf config.Config) ([]sshSession, error) {
+	var sessions []sshSession
+	for _, spec := range conf.Tunnels {
+		t, err := config.ParseTunnel(spec)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sshSession{
+			name: "tunnel " + t.Local + " -> " + t.Remote,
+			// exit if the port cannot be forwarded, to be reopened
+			args: []string{"-N", "-o", "ExitOnForwardFailure=yes", "-L", t.Local + ":" + t.Remote},
+		})
+	}
+	return sessions, nil
+}
+
+// socketSession returns the session forwarding the docker socket of the remote host to the host.
+func socketSession() sshSession {
+	return sshSession{
+		name: "docker socket forwarding",
+		// the socket of a previous session is replaced
+		args: []string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "StreamLocalBindUnlink=yes",
+			"-L", docker.HostSocketFile() + ":/var/run/docker.sock"},
+	}
+}
+
+// sshArgs returns the arguments of ssh for the session with the ssh config file of the profile.
+func (s sshSession) sshArgs(sshConfig string) []string {
+	args := []string{"-F", sshConfig,
+		// a dedicated connection, not multiplexed with the other ssh sessions
+		"-o", "ControlMaster=no", "-o", "ControlPath=none",
+		// exit if the VM stops responding, to be reopened
+		"-o", "ServerAliveInterval=10", "-o", "ServerAliveCountMax=3",
+	}
+	args = append(append(args, s.args...), config.Profile().ID)
+	if s.command != "" {
+		args = append(args, "--", s.command)
+	}
+	return args
+}
+
+// startSessions opens the ssh sessions, and reopens them when they exit.
+// stop closes the sessions.
+func startSessions(sessions []sshSession) (stop func(), err error) {
+	sshConfig, err := sshConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, s := range sessions {
+		log.Println("opening", s.name)
+		wg.Add(1)
+		go func(s sshSession) {
+			defer wg.Done()
+			keepSession(ctx, sshConfig, s)
+		}(s)
+	}
+
+	return func() {
+		cancel()
+		// the ssh processes are killed before exiting
+		wg.Wait()
+	}, nil
+}
+
+// keepSession keeps the session open until ctx is done.
+func keepSession(ctx context.Context, sshConfig string, s sshSession) {
+	delay := time.Second
+	for {
+		opened := time.Now()
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "ssh", s.sshArgs(sshConfig)...)
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(opened) > time.Minute {
+			delay = time.Second
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" && err != nil {
+			message = err.Error()
+		}
+		log.Warnf("%s closed: %s, reopening in %v", s.name, message, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > sessionMaxDelay {
+			delay = sessionMaxDelay
+		}
+	}
+}
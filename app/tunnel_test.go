@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+)
+
+func Test_sshSession_sshArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config.SetProfile("buildbox")
+	options := []string{"-F", "ssh_config", "-o", "ControlMaster=no", "-o", "ControlPath=none",
+		"-o", "ServerAliveInterval=10", "-o", "ServerAliveCountMax=3"}
+
+	tests := []struct {
+		session sshSession
+		want    []string
+	}{
+		// the docker socket of the remote host
+		{session: socketSession(), want: append(append([]string{}, options...), "-N", "-o", "ExitOnForwardFailure=yes",
+			"-o", "StreamLocalBindUnlink=yes", "-L", docker.HostSocketFile()+":/var/run/docker.sock", "colima-buildbox")},
+		{session: sshSession{command: "echo hello"}, want: append(append([]string{}, options...), "colima-buildbox", "--", "echo hello")},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := tt.session.sshArgs("ssh_config"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sshArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tunnelSessions(t *testing.T) {
+	tests := []struct {
+		tunnels []string
+		want    [][]string
+		wantErr bool
+	}{
+		{tunnels: []string{"5432:db:5432", "0.0.0.0:8080:web:80"}, want: [][]string{
+			{"-N", "-o", "ExitOnForwardFailure=yes", "-L", "127.0.0.1:5432:db:5432"},
+			{"-N", "-o", "ExitOnForwardFailure=yes", "-L", "0.0.0.0:8080:web:80"},
+		}},
+		{tunnels: []string{"5432:db"}, wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			sessions, err := tunnelSessions(config.Config{Tunnels: tt.tunnels})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tunnelSessions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got [][]string
+			for _, s := range sessions {
+				got = append(got, s.args)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tunnelSessions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/vm/lima"
+)
+
+// VersionInfo is the version of Colima and of the components of the profile.
+type VersionInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Lima     string `json:"lima,omitempty"`
+	// Guest are the versions of the components in the VM, if running.
+	Guest *GuestVersions `json:"guest,omitempty"`
+}
+
+// GuestVersions are the versions of the components in the VM, empty if not installed.
+type GuestVersions struct {
+	Arch       string `json:"arch"`
+	Runtime    string `json:"runtime"`
+	OS         string `json:"os,omitempty"`
+	Kernel     string `json:"kernel,omitempty"`
+	Docker     string `json:"docker,omitempty"`
+	Containerd string `json:"containerd,omitempty"`
+	Nerdctl    string `json:"nerdctl,omitempty"`
+	Buildkit   string `json:"buildkit,omitempty"`
+	K3s        string `json:"k3s,omitempty"`
+}
+
+// guestVersionsScript prints the versions of the components in the VM as key=value lines, in a single ssh session.
+// The versions are the third field of the --version output, e.g. "containerd github.com/containerd/containerd v1.5.8".
+const guestVersionsScript = `echo "os=$(cat /etc/alpine-release 2>/dev/null)"
+echo "kernel=$(uname -r)"
+command -v docker >/dev/null && echo "docker=$(sudo docker version --format '{{.Server.Version}}' 2>/dev/null)"
+for b in containerd nerdctl buildkitd k3s; do
+  command -v $b >/dev/null && echo "$b=$($b --version 2>/dev/null | head -1 | awk '{print $3}')"
+done
+true`
+
+func (c colimaApp) VersionInfo() (VersionInfo, error) {
+	version := config.AppVersion()
+	info := VersionInfo{Version: version.Version, Revision: version.Revision}
+
+	// limactl version 0.8.3
+	if out, err := exec.Command("limactl", "--version").Output(); err == nil {
+		if fields := strings.Fields(string(out)); len(fields) > 0 {
+			info.Lima = fields[len(fields)-1]
+		}
+	}
+
+	// the VM cannot be queried while paused
+	if !c.guest.Running() || lima.Paused(config.Profile().ID) {
+		return info, nil
+	}
+	out, err := c.guest.RunOutput("sh", "-c", guestVersionsScript)
+	if err != nil {
+		return info, err
+	}
+	values := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
+			values[parts[0]] = strings.TrimSpace(parts[1])
+		}
+	}
+	info.Guest = &GuestVersions{
+		Arch:       string(c.guest.Arch()),
+		Runtime:    c.guest.Get(environment.ContainerRuntimeKey),
+		OS:         values["os"],
+		Kernel:     values["kernel"],
+		Docker:     values["docker"],
+		Containerd: values["containerd"],
+		Nerdctl:    values["nerdctl"],
+		Buildkit:   values["buildkitd"],
+		K3s:        values["k3s"],
+	}
+	return info, nil
+}
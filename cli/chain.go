@@ -2,8 +2,12 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -33,6 +37,8 @@ type cFunc struct {
 	f  func() error
 	fc func(Context) error
 	s  string
+	// e is the event name of the stage
+	e string
 }
 
 // CommandChain is a chain of commands.
@@ -81,15 +87,61 @@ func (a *ActiveCommandChain) AddCtx(f func(Context) error) {
 	a.funcs = append(a.funcs, cFunc{fc: f})
 }
 
+// Parallel adds functions that are independent of each other to the runner, executed concurrently.
+// The chain proceeds after all the functions return, and is terminated with the first error in the order of funcs.
+// There is no dependency graph, the functions of a chain run in the order they are added.
+func (a *ActiveCommandChain) Parallel(funcs ...func() error) {
+	a.Add(func() error {
+		errs := make([]error, len(funcs))
+		var wg sync.WaitGroup
+		for i, f := range funcs {
+			wg.Add(1)
+			go func(i int, f func() error) {
+				defer wg.Done()
+				errs[i] = f()
+			}(i, f)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // Stage sets the current stage of the runner.
 func (a *ActiveCommandChain) Stage(s string) {
-	a.funcs = append(a.funcs, cFunc{s: s})
+	a.funcs = append(a.funcs, cFunc{s: s, e: eventName(s)})
 }
 
 // Stagef is like stage with string format.
 func (a *ActiveCommandChain) Stagef(format string, s ...interface{}) {
 	f := fmt.Sprintf(format, s...)
-	a.Stage(f)
+	// the event name is derived from the format to remain stable
+	a.funcs = append(a.funcs, cFunc{s: f, e: eventName(format)})
+}
+
+// eventName returns the stable event name of a stage for structured logs.
+// e.g. "creating and starting" -> creating_and_starting, "loading %s" -> loading.
+func eventName(stage string) string {
+	var words []string
+	for _, w := range strings.Fields(stage) {
+		if strings.Contains(w, "%") {
+			continue
+		}
+		w = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, w)
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, "_")
 }
 
 // Exec executes the command chain.
@@ -97,10 +149,23 @@ func (a *ActiveCommandChain) Stagef(format string, s ...interface{}) {
 // error is returned. Otherwise, returns nil.
 func (a ActiveCommandChain) Exec() error {
 	ctx := &cCtx{context.Background()}
+
+	// time the stages for profiling
+	done := func() {}
+	defer func() { done() }()
+
 	for _, f := range a.funcs {
 		if f.f == nil && f.fc == nil {
 			if f.s != "" {
-				a.log.Println(f.s, "...")
+				done()
+				done = timeStage(fmt.Sprint(a.log.Data["context"], ": ", f.s))
+
+				entry := a.log
+				if Settings.LogFormat == "json" {
+					// e.g. vm.creating_and_starting, docker.provisioning
+					entry = entry.WithField("event", fmt.Sprint(a.log.Data["context"], ".", f.e))
+				}
+				entry.Println(f.s, "...")
 				a.lastStage = f.s
 			}
 			continue
@@ -142,3 +207,27 @@ func (a *ActiveCommandChain) Retry(stage string, interval time.Duration, count i
 		return err
 	})
 }
+
+// ErrTimeout is the error of a command chain terminated by a Wait that did not succeed in time.
+var ErrTimeout = errors.New("timed out")
+
+// Wait retries `f` at interval until it succeeds or timeout elapses.
+// If `f` does not succeed within timeout, the command chain is terminated with an error wrapping ErrTimeout.
+func (a *ActiveCommandChain) Wait(stage string, interval, timeout time.Duration, f func() error) {
+	a.Add(func() error {
+		deadline := time.Now().Add(timeout)
+		for {
+			err := f()
+			if err == nil {
+				return nil
+			}
+			if !time.Now().Before(deadline) {
+				return fmt.Errorf("%w after %v: %v", ErrTimeout, timeout, err)
+			}
+			if stage != "" {
+				a.log.Println(stage, "...")
+			}
+			time.Sleep(interval)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_eventName(t *testing.T) {
+	tests := []struct {
+		stage string
+		want  string
+	}{
+		{stage: "starting", want: "starting"},
+		{stage: "creating and starting", want: "creating_and_starting"},
+		{stage: "loading %s", want: "loading"},
+		{stage: "Provisioning k3s v1.23", want: "provisioning_k3s_v123"},
+		{stage: "waiting for startup to complete ...", want: "waiting_for_startup_to_complete"},
+		{stage: "copying %s to %s", want: "copying_to"},
+		{stage: "", want: ""},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := eventName(tt.stage); got != tt.want {
+				t.Errorf("eventName(%s) = %s, want %s", tt.stage, got, tt.want)
+			}
+		})
+	}
+}
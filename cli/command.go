@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
 )
 
 var runner commandRunner = &defaultCommandRunner{}
@@ -12,16 +18,42 @@ var runner commandRunner = &defaultCommandRunner{}
 var Settings = struct {
 	// Verbose toggles verbose output for commands.
 	Verbose bool
+	// Quiet suppresses the output of commands.
+	Quiet bool
+	// LogFormat is the format of the log output, text or json.
+	LogFormat string
+	// CI disables prompts and host niceties for predictable non-interactive runs.
+	CI bool
 }{}
 
+// ErrNonInteractive is returned when user input is required in CI mode.
+var ErrNonInteractive = errors.New("user input is not possible in CI mode")
+
+// DetectCI returns if the process is running in a CI environment, i.e. $CI is set to true.
+func DetectCI() bool {
+	ci, _ := strconv.ParseBool(os.Getenv("CI"))
+	return ci
+}
+
 // Command creates a new command.
-func Command(command string, args ...string) *exec.Cmd { return runner.Command(command, args...) }
+func Command(command string, args ...string) *exec.Cmd {
+	logCommand(command, args)
+	return runner.Command(command, args...)
+}
 
 // CommandInteractive creates a new interactive command.
 func CommandInteractive(command string, args ...string) *exec.Cmd {
+	logCommand(command, args)
 	return runner.CommandInteractive(command, args...)
 }
 
+// logCommand logs the command at debug level for troubleshooting.
+func logCommand(command string, args []string) {
+	if log.IsLevelEnabled(log.DebugLevel) {
+		log.WithField("context", "exec").Debugln(command, strings.Join(args, " "))
+	}
+}
+
 type commandRunner interface {
 	Command(command string, args ...string) *exec.Cmd
 	CommandInteractive(command string, args ...string) *exec.Cmd
@@ -46,17 +78,60 @@ func (d defaultCommandRunner) CommandInteractive(command string, args ...string)
 	return cmd
 }
 
+// OpenEditor opens file in the user's editor specified by $EDITOR, falling back to vi.
+func OpenEditor(file string) error {
+	if Settings.CI {
+		return ErrNonInteractive
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// $EDITOR may include args e.g. 'code --wait'
+	return CommandInteractive("sh", "-c", editor+" "+strconv.Quote(file)).Run()
+}
+
+// stdin is shared by prompts to not lose buffered input between them.
+var stdin = bufio.NewReader(os.Stdin)
+
 // Prompt prompts for input with a question. It returns true only if answer is y or Y.
+// In CI mode, the answer is assumed to be no.
 func Prompt(question string) bool {
 	fmt.Print(question)
 	fmt.Print("? [y/N] ")
+	if Settings.CI {
+		fmt.Println("N (CI mode)")
+		return false
+	}
 
-	var answer string
-	_, _ = fmt.Scanln(&answer)
-
+	answer := readLine()
 	if answer == "" {
 		return false
 	}
 
 	return answer[0] == 'Y' || answer[0] == 'y'
 }
+
+// PromptString prompts for input with a question.
+// It returns def if no answer is given, or in CI mode.
+func PromptString(question, def string) string {
+	fmt.Print(question)
+	if def != "" {
+		fmt.Print(" [" + def + "]")
+	}
+	fmt.Print(": ")
+	if Settings.CI {
+		fmt.Println(def + " (CI mode)")
+		return def
+	}
+
+	if answer := readLine(); answer != "" {
+		return answer
+	}
+	return def
+}
+
+func readLine() string {
+	line, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(line)
+}
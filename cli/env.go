@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix is the prefix of environment variables that set flags.
+const EnvPrefix = "COLIMA_"
+
+// EnvName returns the environment variable name for the flag.
+// e.g. kubernetes-version -> COLIMA_KUBERNETES_VERSION
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// ApplyEnv sets the flags that are not explicitly set to the value
+// of their environment variable, if set.
+// Explicitly set flags take precedence over environment variables.
+func ApplyEnv(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Name == "help" {
+			return
+		}
+		val, ok := os.LookupEnv(EnvName(f.Name))
+		if !ok {
+			return
+		}
+		if e := flags.Set(f.Name, val); e != nil {
+			err = fmt.Errorf("invalid value for %s: %w", EnvName(f.Name), e)
+		}
+	})
+	return err
+}
@@ -0,0 +1,65 @@
+package cli
+
+import "errors"
+
+// ExitError is an error that terminates the process with a specific exit code.
+type ExitError struct {
+	Code int
+	Err  error
+	// Silent is set if the failure is already reported, e.g. by the command run, and err is not printed.
+	Silent bool
+}
+
+func (e ExitError) Error() string { return e.Err.Error() }
+func (e ExitError) Unwrap() error { return e.Err }
+
+// Exit codes of the error categories.
+// 1 is for uncategorized errors, 2-7 are reserved for the status and healthcheck commands.
+const (
+	ExitConfig = 10 + iota
+	ExitDependency
+	ExitVM
+	ExitRuntime
+	ExitKubernetes
+	ExitNetwork
+	ExitTimeout
+	ExitNonInteractive
+)
+
+// Error is an error of a category with a short remediation hint.
+type Error struct {
+	// Code is the exit code of the category.
+	Code int
+	// Hint is the remediation hint e.g. "run 'colima doctor'".
+	Hint string
+	Err  error
+}
+
+func (e Error) Error() string { return e.Err.Error() }
+func (e Error) Unwrap() error { return e.Err }
+
+// NewError creates an error of the category with exit code and a remediation hint.
+func NewError(code int, err error, hint string) error {
+	return Error{Code: code, Hint: hint, Err: err}
+}
+
+// ExitCode returns the exit code and the remediation hint (if any) for err.
+// The innermost category in the chain of wrapped errors is the most specific and takes precedence.
+func ExitCode(err error) (code int, hint string) {
+	code = 1
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch e := err.(type) {
+		case ExitError:
+			code, hint = e.Code, ""
+		case Error:
+			code, hint = e.Code, e.Hint
+		}
+		switch err {
+		case ErrTimeout:
+			code, hint = ExitTimeout, "increase the timeout with 'colima start --wait-timeout <seconds>'"
+		case ErrNonInteractive:
+			code, hint = ExitNonInteractive, "pass --force, or disable CI mode with --ci=false"
+		}
+	}
+	return code, hint
+}
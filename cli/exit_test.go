@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	errVM := NewError(ExitVM, errors.New("vm failed"), "run 'colima doctor'")
+	tests := []struct {
+		err      error
+		wantCode int
+		wantHint string
+	}{
+		{err: errors.New("failed"), wantCode: 1},
+		{err: errVM, wantCode: ExitVM, wantHint: "run 'colima doctor'"},
+		{err: fmt.Errorf("error starting: %w", errVM), wantCode: ExitVM, wantHint: "run 'colima doctor'"},
+		// the innermost category takes precedence
+		{err: NewError(ExitRuntime, fmt.Errorf("wrapped: %w", errVM), "restart"), wantCode: ExitVM, wantHint: "run 'colima doctor'"},
+		{err: NewError(ExitNetwork, ExitError{Code: 3, Err: errors.New("exited")}, "check the network"), wantCode: 3},
+		{err: ExitError{Code: 3, Err: errVM}, wantCode: ExitVM, wantHint: "run 'colima doctor'"},
+		{err: fmt.Errorf("waiting: %w", ErrTimeout), wantCode: ExitTimeout, wantHint: "increase the timeout with 'colima start --wait-timeout <seconds>'"},
+		{err: NewError(ExitVM, ErrNonInteractive, "hint"), wantCode: ExitNonInteractive, wantHint: "pass --force, or disable CI mode with --ci=false"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			code, hint := ExitCode(tt.err)
+			if code != tt.wantCode || hint != tt.wantHint {
+				t.Errorf("ExitCode() = %d, %q, want %d, %q", code, hint, tt.wantCode, tt.wantHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abiosoft/colima/util/terminal"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
+
+// Progress reports the phases of a long running operation and their duration.
+// Output is colored on a terminal and plain otherwise.
+type Progress struct {
+	phases  []phaseDuration
+	current string
+	started time.Time
+	begin   time.Time
+}
+
+type phaseDuration struct {
+	name     string
+	duration time.Duration
+}
+
+// NewProgress creates a new progress.
+func NewProgress() *Progress {
+	return &Progress{begin: time.Now()}
+}
+
+// Phase ends the current phase, if any, and starts the phase name.
+func (p *Progress) Phase(name string) {
+	p.end()
+	p.current, p.started = name, time.Now()
+
+	switch {
+	case Settings.Quiet:
+	case Settings.LogFormat == "json":
+		log.WithField("event", "phase.started").WithField("phase", name).Println(name)
+	case terminal.IsTerminal():
+		fmt.Fprintln(os.Stderr, color.New(color.Bold).Sprint("==> "+name))
+	default:
+		fmt.Fprintln(os.Stderr, "==> "+name)
+	}
+}
+
+func (p *Progress) end() {
+	if p.current == "" {
+		return
+	}
+	d := time.Since(p.started)
+	p.phases = append(p.phases, phaseDuration{name: p.current, duration: d})
+
+	switch {
+	case Settings.Quiet:
+	case Settings.LogFormat == "json":
+		log.WithField("event", "phase.done").WithField("phase", p.current).
+			WithField("duration", d.Seconds()).Println(p.current, "done")
+	default:
+		line := fmt.Sprintf("    %s done in %s", p.current, formatDuration(d))
+		if terminal.IsTerminal() {
+			line = color.HiBlackString(line)
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	p.current = ""
+}
+
+// Done ends the current phase and prints the summary of all phases.
+func (p *Progress) Done() {
+	p.end()
+	total := time.Since(p.begin)
+
+	switch {
+	case Settings.Quiet:
+	case Settings.LogFormat == "json":
+		log.WithField("event", "phase.summary").WithField("duration", total.Seconds()).Println("done")
+	default:
+		w := tabwriter.NewWriter(os.Stderr, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "summary:")
+		for _, phase := range p.phases {
+			fmt.Fprintf(w, "    %s\t%s\n", phase.name, formatDuration(phase.duration))
+		}
+		fmt.Fprintf(w, "    total\t%s\n", formatDuration(total))
+		_ = w.Flush()
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
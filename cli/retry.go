@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Backoff is a retry policy with exponential backoff.
+type Backoff struct {
+	// Attempts is the maximum number of attempts.
+	Attempts int
+	// Delay is the delay before the first retry, doubled for each subsequent retry.
+	Delay time.Duration
+	// MaxDelay caps the delay between retries.
+	MaxDelay time.Duration
+}
+
+// DefaultBackoff is the retry policy for network dependent operations e.g. downloads,
+// to not fail on transient network errors.
+var DefaultBackoff = Backoff{Attempts: 5, Delay: 2 * time.Second, MaxDelay: 30 * time.Second}
+
+// Do calls f until it succeeds or the attempts are exhausted, in which case the final error is returned.
+// Failed attempts are logged with desc to entry.
+func (b Backoff) Do(entry *log.Entry, desc string, f func() error) error {
+	delay := b.Delay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= b.Attempts {
+			if err != nil && b.Attempts > 1 {
+				return fmt.Errorf("failed after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+		entry.Warnf("%s failed (attempt %d/%d), retrying in %v: %v", desc, attempt, b.Attempts, delay, err)
+		time.Sleep(delay)
+		if delay *= 2; delay > b.MaxDelay {
+			delay = b.MaxDelay
+		}
+	}
+}
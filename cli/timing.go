@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// timings records the durations of stages and commands for profiling, if enabled.
+var timings struct {
+	sync.Mutex
+	enabled  bool
+	stages   []timing
+	commands []timing
+}
+
+type timing struct {
+	name     string
+	duration time.Duration
+}
+
+// EnableTimings enables recording the durations of stages and commands.
+func EnableTimings() {
+	timings.Lock()
+	defer timings.Unlock()
+	timings.enabled = true
+}
+
+// TimeCommand starts timing the command and returns the func to call when it completes.
+func TimeCommand(args ...string) func() { return record(&timings.commands, strings.Join(args, " ")) }
+
+// timeStage starts timing the stage and returns the func to call when it completes.
+func timeStage(name string) func() { return record(&timings.stages, name) }
+
+func record(list *[]timing, name string) func() {
+	timings.Lock()
+	enabled := timings.enabled
+	timings.Unlock()
+	if !enabled {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		timings.Lock()
+		defer timings.Unlock()
+		*list = append(*list, timing{name: name, duration: time.Since(start)})
+	}
+}
+
+// maxTimedCommands is the number of the slowest commands printed.
+const maxTimedCommands = 15
+
+// PrintTimings writes the recorded durations of the stages in order,
+// and of the slowest commands, to w.
+func PrintTimings(w io.Writer) {
+	timings.Lock()
+	defer timings.Unlock()
+
+	tw := tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(tw, "stages:")
+	for _, s := range timings.stages {
+		fmt.Fprintf(tw, "    %s\t%s\n", s.name, formatDuration(s.duration))
+	}
+
+	commands := append([]timing{}, timings.commands...)
+	sort.SliceStable(commands, func(i, j int) bool { return commands[i].duration > commands[j].duration })
+	if len(commands) > maxTimedCommands {
+		commands = commands[:maxTimedCommands]
+	}
+	fmt.Fprintf(tw, "slowest commands (%d run):\n", len(timings.commands))
+	for _, c := range commands {
+		name := c.name
+		if len(name) > 100 {
+			name = name[:97] + "..."
+		}
+		fmt.Fprintf(tw, "    %s\t%s\n", formatDuration(c.duration), name)
+	}
+	_ = tw.Flush()
+}
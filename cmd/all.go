@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// checkAllFlags returns an error if flags other than allowed are set alongside --all.
+func checkAllFlags(cmd *cobra.Command, args []string, allowed ...string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("profile cannot be specified with --all")
+	}
+	var err error
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if cmd.LocalFlags().Lookup(f.Name) == nil {
+			// global flags
+			return
+		}
+		if err == nil && f.Name != "all" && !contains(allowed, f.Name) {
+			err = fmt.Errorf("--%s cannot be used with --all", f.Name)
+		}
+	})
+	return err
+}
+
+// runAll runs colima with args for every profile with a saved config.
+// Each profile is run in a separate colima process, in parallel if parallel is true.
+func runAll(parallel bool, args ...string) error {
+	profiles, err := config.Profiles()
+	if err != nil {
+		return fmt.Errorf("error listing profiles: %w", err)
+	}
+	if len(profiles) == 0 {
+		log.Warnln("no profile found")
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error retrieving colima executable: %w", err)
+	}
+
+	var (
+		failed []string
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+	)
+	run := func(profile string) {
+		defer wg.Done()
+
+		cmdArgs := append(append([]string{}, args...), "--profile", profile)
+		cmd := cli.Command(executable, cmdArgs...)
+		if parallel {
+			// prefix the output to differentiate the profiles
+			stdout := &prefixWriter{prefix: "[" + profile + "] ", w: os.Stdout}
+			stderr := &prefixWriter{prefix: "[" + profile + "] ", w: os.Stderr}
+			defer stdout.Flush()
+			defer stderr.Flush()
+			cmd.Stdout, cmd.Stderr = stdout, stderr
+		}
+		if err := cmd.Run(); err != nil {
+			mu.Lock()
+			failed = append(failed, profile)
+			mu.Unlock()
+		}
+	}
+
+	for _, profile := range profiles {
+		wg.Add(1)
+		if parallel {
+			go run(profile)
+		} else {
+			run(profile)
+		}
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s failed for profiles: %s", args[0], strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// prefixWriter prefixes every line written to w.
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+	buf    bytes.Buffer
+	mu     sync.Mutex
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf.Write(b)
+	for {
+		line, err := p.buf.ReadBytes('\n')
+		if err != nil {
+			// incomplete line, wait for the rest
+			p.buf.Reset()
+			p.buf.Write(line)
+			return len(b), nil
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes the remaining incomplete line, if any.
+func (p *prefixWriter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.buf.Len() > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf.String())
+		p.buf.Reset()
+	}
+}
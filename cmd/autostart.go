@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util/autostart"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// autostartCmd represents the autostart command
+var autostartCmd = &cobra.Command{
+	Use:   "autostart",
+	Short: "manage starting at login",
+	Long: `Manage starting the profile at login.
+
+A launchd agent (macOS) or systemd user unit (Linux) starts the profile at login
+and stops it cleanly at logout or shutdown.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// if an arg is passed, assume it to be the profile
+		if len(args) > 0 {
+			if err := cmd.Flags().Set("profile", args[0]); err != nil {
+				return err
+			}
+		}
+		// cobra overrides PersistentPreRunE when redeclared.
+		// re-run rootCmd's.
+		return root.Cmd().PersistentPreRunE(cmd, args)
+	},
+}
+
+// autostartEnableCmd represents the autostart enable command
+var autostartEnableCmd = &cobra.Command{
+	Use:   "enable [profile]",
+	Short: "start the profile at login",
+	Long: `Start the profile at login, effective from the next login.
+
+With 'lazy_start', only the Docker socket is listened on at login and the profile
+is started on the first connection. Re-enable after changing 'lazy_start'.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := config.Load()
+		if err != nil || conf.Empty() {
+			return fmt.Errorf("%s has no configuration, start with 'colima start'", config.Profile().DisplayName)
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("error retrieving colima binary: %w", err)
+		}
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return fmt.Errorf("error retrieving colima binary: %w", err)
+		}
+
+		if err := autostart.Enable(executable, conf.LazyStart); err != nil {
+			return err
+		}
+		if conf.LazyStart {
+			log.Println(config.Profile().DisplayName, "starts on the first Docker connection after login, configured in", autostart.File())
+			return nil
+		}
+		log.Println(config.Profile().DisplayName, "starts at login, configured in", autostart.File())
+		return nil
+	},
+}
+
+// autostartDisableCmd represents the autostart disable command
+var autostartDisableCmd = &cobra.Command{
+	Use:               "disable [profile]",
+	Short:             "do not start the profile at login",
+	Long:              `Do not start the profile at login. A running profile is not stopped.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !autostart.Enabled() {
+			log.Println("autostart is not enabled for", config.Profile().DisplayName)
+			return nil
+		}
+		if err := autostart.Disable(); err != nil {
+			return err
+		}
+		log.Println(config.Profile().DisplayName, "no longer starts at login")
+		return nil
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(autostartCmd)
+	autostartCmd.AddCommand(autostartEnableCmd)
+	autostartCmd.AddCommand(autostartDisableCmd)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+func Test_commandFlags(t *testing.T) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		t.Run(cmd.CommandPath(), func(t *testing.T) {
+			defer func() {
+				// shorthand collisions with the persistent flags panic
+				if r := recover(); r != nil {
+					t.Errorf("flags of '%s': %v", cmd.CommandPath(), fmt.Sprint(r))
+				}
+			}()
+			cmd.Flags()
+			cmd.InheritedFlags()
+			cmd.LocalFlags()
+		})
+		for _, c := range cmd.Commands() {
+			walk(c)
+		}
+	}
+	walk(root.Cmd())
+}
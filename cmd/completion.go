@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"os"
+	"sort"
 
 	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
 	"github.com/spf13/cobra"
 )
 
@@ -69,6 +73,68 @@ PowerShell:
 	return cmd
 }
 
+// completeProfiles completes the names of existing profiles.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := config.Profiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileArg completes the optional profile arg.
+func completeProfileArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProfiles(cmd, args, toComplete)
+}
+
+// completeRuntimes completes the registered container runtimes.
+func completeRuntimes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	runtimes := environment.ContainerRuntimes()
+	sort.Strings(runtimes)
+	return runtimes, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeKubernetesVersions completes the default and the saved Kubernetes versions.
+func completeKubernetesVersions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	versions := []string{kubernetes.DefaultVersion, kubernetes.EdgeVersion}
+	profiles, _ := config.Profiles()
+	for _, p := range profiles {
+		if c, err := config.LoadProfile(p); err == nil && c.Kubernetes.Version != "" && !contains(versions, c.Kubernetes.Version) {
+			versions = append(versions, c.Kubernetes.Version)
+		}
+	}
+	return versions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplates completes the names of templates.
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	templates, err := config.Templates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, t := range templates {
+		names = append(names, t.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKeys completes the config keys.
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+}
+
 func init() {
 	root.Cmd().AddCommand(completionCmd())
+
+	_ = root.Cmd().RegisterFlagCompletionFunc("profile", completeProfiles)
 }
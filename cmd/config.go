@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/pkg/colima"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "read or modify the configuration",
+	Long: `Read or modify the saved configuration of the profile.
+
+Keys are dot separated e.g. kubernetes.enabled, keys of the VM can be
+specified without the 'vm.' prefix e.g. memory.`,
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "print the value of a configuration",
+	Long: `Print the value of a configuration.
+
+The default value is printed if the configuration is not set.`,
+	Example: "  colima config get kubernetes.enabled\n" +
+		"  colima config get memory",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := loadConfigOrDefault()
+		if err != nil {
+			return err
+		}
+
+		val, err := config.GetKey(conf, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(val)
+		return nil
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "modify the value of a configuration",
+	Long: `Modify the value of a configuration.
+
+The value is parsed as YAML e.g. 'true' for booleans, '[a, b]' for lists.
+Changes take effect on the next 'colima start'.`,
+	Example: "  colima config set memory 8\n" +
+		"  colima config set kubernetes.enabled true\n" +
+		"  colima config set registry.mirrors '[https://mirror.gcr.io]'",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		current, err := config.LoadInto(colima.DefaultConfig())
+		if err != nil {
+			return err
+		}
+		conf := current
+		if conf.Empty() {
+			conf = colima.DefaultConfig()
+		}
+
+		conf, err = config.SetKey(conf, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if errs := colima.Validate(conf); len(errs) > 0 {
+			return errs[0]
+		}
+
+		if !current.Empty() {
+			if conf.Runtime != current.Runtime || conf.VM.Disk != current.VM.Disk || conf.VM.Arch != current.VM.Arch {
+				log.Warnln("runtime, disk and arch are only effective on VM create, delete the VM for the change to take effect")
+			}
+		}
+
+		return config.Save(conf)
+	},
+}
+
+// loadConfigOrDefault loads the config of the current profile,
+// the default config is returned if none is saved.
+func loadConfigOrDefault() (config.Config, error) {
+	conf, err := config.LoadInto(colima.DefaultConfig())
+	if err != nil {
+		return conf, err
+	}
+	if conf.Empty() {
+		return colima.DefaultConfig(), nil
+	}
+	return conf, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	root.Cmd().AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+}
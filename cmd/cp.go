@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+var cpCmdArgs struct {
+	recursive bool
+	preserve  bool
+}
+
+// cpCmd represents the cp command
+var cpCmd = &cobra.Command{
+	Use:   "cp <source>... <destination>",
+	Short: "copy files to and from the VM",
+	Long: `Copy files and directories between the host and the VM.
+
+The paths in the VM are prefixed with 'vm:', relative paths in the VM are relative to the home directory.`,
+	Example: "  colima cp ./config.json vm:/tmp/\n" +
+		"  colima cp -r vm:/var/log/ ./vm-logs\n" +
+		"  colima cp -rp ./build vm:build",
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		guest := false
+		for _, a := range args {
+			if strings.HasPrefix(a, app.GuestPathPrefix) {
+				guest = true
+			}
+		}
+		if !guest {
+			return fmt.Errorf("the source or destination must be a path in the VM, prefixed with '%s'", app.GuestPathPrefix)
+		}
+		src, dst := args[:len(args)-1], args[len(args)-1]
+		return newApp().Copy(cpCmdArgs.recursive, cpCmdArgs.preserve, src, dst)
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(cpCmd)
+
+	cpCmd.Flags().BoolVarP(&cpCmdArgs.recursive, "recursive", "r", false, "copy directories recursively")
+	cpCmd.Flags().BoolVar(&cpCmdArgs.preserve, "preserve", false, "preserve the modification times and modes of the files")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/daemon"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmdArgs struct {
+	socket string
+}
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "serve the control API",
+	Long: `Serve an HTTP API on a unix socket in the foreground, to manage the profiles
+without parsing the output of the commands e.g. from GUIs, IDE plugins and scripts.
+
+The endpoints are:
+  GET  /v1/version
+  GET  /v1/profiles
+  GET  /v1/profiles/{name}/status
+  POST /v1/profiles/{name}/start
+  POST /v1/profiles/{name}/stop[?force=true]
+  GET  /v1/profiles/{name}/config
+  PUT  /v1/profiles/{name}/config/{key}   the value is the request body`,
+	Example: "  colima daemon\n" +
+		"  curl --unix-socket ~/.colima/_shared/daemon.sock http://colima/v1/profiles/default/status",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket := daemonCmdArgs.socket
+		if socket == "" {
+			socket = daemon.SocketFile()
+		}
+		return daemon.Serve(socket)
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&daemonCmdArgs.socket, "socket", "", "path to the unix socket (default is daemon.sock in the colima config directory)")
+}
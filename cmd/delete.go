@@ -1,14 +1,19 @@
 package cmd
 
 import (
-	"github.com/abiosoft/colima/cli"
+	"strings"
+
 	"github.com/abiosoft/colima/cmd/root"
 	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util/autostart"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var deleteCmdArgs struct {
-	force bool
+	force    bool
+	all      bool
+	keepData bool
 }
 
 // deleteCmd represents the delete command
@@ -20,17 +25,52 @@ var deleteCmd = &cobra.Command{
 Use with caution. This deletes everything and a startup afterwards is like the
 initial startup of Colima.
 
+With --keep-data, the images, containers and volumes of the container runtime
+are kept on the host and restored on the next start of the profile with the same runtime.
+
 If you simply want to reset the Kubernetes cluster, run 'colima kubernetes reset'.`,
-	Args: cobra.MaximumNArgs(1),
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if deleteCmdArgs.all {
+			if err := checkAllFlags(cmd, args, "force", "yes", "keep-data"); err != nil {
+				return err
+			}
+			if !deleteCmdArgs.force {
+				profiles, err := config.Profiles()
+				if err != nil {
+					return err
+				}
+				summary := []string{"profiles " + strings.Join(profiles, ", "), "all settings"}
+				if ok, err := confirmDestructive("all profiles", summary); !ok {
+					return err
+				}
+			}
+			runArgs := []string{"delete", "--force"}
+			if deleteCmdArgs.keepData {
+				runArgs = append(runArgs, "--keep-data")
+			}
+			return runAll(false, runArgs...)
+		}
+
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		app := newApp()
 		if !deleteCmdArgs.force {
-			y := cli.Prompt("are you sure you want to delete " + config.Profile().DisplayName + " and all settings")
-			if !y {
-				return nil
+			if ok, err := confirmDestructive(config.Profile().DisplayName, app.DeleteSummary(deleteCmdArgs.keepData)); !ok {
+				return err
 			}
 		}
 
-		return newApp().Delete()
+		if err := app.Delete(deleteCmdArgs.keepData); err != nil {
+			return err
+		}
+		// a deleted profile cannot be started at login
+		if err := autostart.Disable(); err != nil {
+			log.Warnln(err)
+		}
+		return nil
 	},
 }
 
@@ -38,4 +78,7 @@ func init() {
 	root.Cmd().AddCommand(deleteCmd)
 
 	deleteCmd.Flags().BoolVarP(&deleteCmdArgs.force, "force", "f", false, "do not prompt for yes/no")
+	deleteCmd.Flags().BoolVarP(&deleteCmdArgs.force, "yes", "y", false, "alias for --force")
+	deleteCmd.Flags().BoolVar(&deleteCmdArgs.all, "all", false, "delete all profiles")
+	deleteCmd.Flags().BoolVar(&deleteCmdArgs.keepData, "keep-data", false, "keep the container runtime data for the next start")
 }
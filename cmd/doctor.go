@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/host"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/environment/vm/lima/network"
+	"github.com/abiosoft/colima/util/release"
+	"github.com/spf13/cobra"
+)
+
+// minLimaVersion is the minimum supported version of Lima.
+const minLimaVersion = "0.8.0"
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "diagnose common problems",
+	Long: `Diagnose common problems with the host and the VM.
+
+The host is checked for virtualization support, the required Lima and QEMU versions,
+the vmnet helper, a conflicting Docker Desktop and port collisions between profiles.
+The DNS resolution in the VM is checked if it is running. A fix is suggested for every
+problem found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if problems := runDoctorChecks(os.Stdout); problems > 0 {
+			return fmt.Errorf("%d problem(s) found", problems)
+		}
+		return nil
+	},
+}
+
+// runDoctorChecks runs the checks, writes the results to w and returns the number of problems found.
+func runDoctorChecks(w io.Writer) (problems int) {
+	for _, check := range doctorChecks() {
+		r := check.run()
+		switch {
+		case r.skipped != "":
+			fmt.Fprintf(w, "[skip] %s: %s\n", check.name, r.skipped)
+		case r.problem != "":
+			problems++
+			fmt.Fprintf(w, "[fail] %s: %s\n", check.name, r.problem)
+			if r.fix != "" {
+				fmt.Fprintf(w, "       fix: %s\n", r.fix)
+			}
+		default:
+			fmt.Fprintf(w, "[ok]   %s\n", check.name)
+		}
+	}
+	return problems
+}
+
+type doctorCheck struct {
+	name string
+	run  func() doctorResult
+}
+
+// doctorResult is the result of a check, it passes if problem and skipped are empty.
+type doctorResult struct {
+	problem string
+	fix     string
+	skipped string
+}
+
+func doctorChecks() []doctorCheck {
+	return []doctorCheck{
+		{name: "virtualization support", run: checkVirtualization},
+		{name: "lima", run: checkLima},
+		{name: "qemu", run: checkQemu},
+		{name: "vmnet helper", run: checkVmnet},
+		{name: "docker desktop", run: checkDockerDesktop},
+		{name: "ports", run: checkPorts},
+		{name: "DNS in the VM", run: checkVMDNS},
+	}
+}
+
+func checkVirtualization() doctorResult {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.Command("sysctl", "-n", "kern.hv_support").Output()
+		if err != nil || strings.TrimSpace(string(out)) != "1" {
+			return doctorResult{
+				problem: "hypervisor framework is not supported, the VM would be emulated and very slow",
+				fix:     "use a Mac with virtualization support, or enable nested virtualization if running in a VM",
+			}
+		}
+	case "linux":
+		if _, err := os.Stat("/dev/kvm"); err != nil {
+			return doctorResult{
+				problem: "/dev/kvm not found, the VM would be emulated and very slow",
+				fix:     "enable virtualization in the BIOS and load the kvm kernel module",
+			}
+		}
+	}
+	return doctorResult{}
+}
+
+func checkLima() doctorResult {
+	if _, err := exec.LookPath("limactl"); err != nil {
+		return doctorResult{problem: "limactl not found", fix: "run 'brew install lima'"}
+	}
+	out, err := exec.Command("limactl", "--version").Output()
+	if err != nil {
+		return doctorResult{problem: "error retrieving lima version: " + err.Error(), fix: "reinstall lima"}
+	}
+	// limactl version 0.8.3
+	version, err := release.OutputVersion(string(out))
+	if err != nil {
+		return doctorResult{problem: "error retrieving lima version: " + err.Error(), fix: "reinstall lima"}
+	}
+	older, err := release.Older(version, minLimaVersion)
+	if err != nil {
+		return doctorResult{problem: "error retrieving lima version: " + err.Error(), fix: "reinstall lima"}
+	}
+	if older {
+		return doctorResult{
+			problem: fmt.Sprintf("lima version %s is not supported, minimum is %s", version, minLimaVersion),
+			fix:     "run 'brew upgrade lima'",
+		}
+	}
+	return doctorResult{}
+}
+
+func checkQemu() doctorResult {
+	// the binary and the architecture of the profile, if configured
+	conf, _ := config.Load()
+	if err := lima.CheckQEMU(conf); err != nil {
+		fix := "run 'brew install qemu'"
+		if conf.VM.QEMUBinary != "" {
+			fix = "check 'vm.qemu_binary' in the config"
+		}
+		return doctorResult{problem: err.Error(), fix: fix}
+	}
+	// preferably of the same build as the configured binary
+	img := "qemu-img"
+	if conf.VM.QEMUBinary != "" {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(conf.VM.QEMUBinary), img)); err == nil {
+			img = filepath.Join(filepath.Dir(conf.VM.QEMUBinary), img)
+		}
+	}
+	if _, err := exec.LookPath(img); err != nil {
+		return doctorResult{problem: "qemu-img not found", fix: "run 'brew install qemu'"}
+	}
+	return doctorResult{}
+}
+
+func checkVmnet() doctorResult {
+	if runtime.GOOS != "darwin" {
+		return doctorResult{skipped: "only applicable to macOS"}
+	}
+	if network.NewManager(host.New()).DependenciesInstalled() {
+		return doctorResult{}
+	}
+	return doctorResult{
+		problem: "the vmnet helper for VM networking is not installed",
+		fix:     "run 'colima start', the helper is installed with the sudo password",
+	}
+}
+
+func checkDockerDesktop() doctorResult {
+	if runtime.GOOS != "darwin" {
+		return doctorResult{skipped: "only applicable to macOS"}
+	}
+	if exec.Command("pgrep", "-x", "com.docker.backend").Run() != nil {
+		return doctorResult{}
+	}
+	return doctorResult{
+		problem: "Docker Desktop is running, the docker client may not be using colima",
+		fix:     "quit Docker Desktop, or run 'docker context use " + config.Profile().ID + "'",
+	}
+}
+
+func checkPorts() doctorResult {
+	profiles, err := config.Profiles()
+	if err != nil {
+		return doctorResult{problem: err.Error()}
+	}
+
+	running := map[string]bool{}
+	if _, err := exec.LookPath("limactl"); err == nil {
+		instances, _ := lima.Instances()
+		for _, i := range instances {
+			running[config.ProfileFromName(i.Name).ID] = i.Status == "Running"
+		}
+	}
+
+	var problems []string
+	owners := map[int][]string{}
+	for _, p := range profiles {
+		conf, err := config.LoadProfile(p)
+		if err != nil {
+			continue
+		}
+		ports := map[string]int{"ssh": conf.VM.SSHPort}
+		if conf.Kubernetes.Enabled {
+			ports["kubernetes"] = conf.Kubernetes.Port
+		}
+		for name, port := range ports {
+			if port == 0 {
+				continue
+			}
+			owners[port] = append(owners[port], p)
+			// the ports of running profiles are expected to be in use
+			if !running[config.ProfileFromName(p).ID] && !config.PortAvailable(port) {
+				problems = append(problems, fmt.Sprintf("%s port %d of profile '%s' is in use by another process", name, port, p))
+			}
+		}
+	}
+	for port, o := range owners {
+		if len(o) > 1 {
+			problems = append(problems, fmt.Sprintf("port %d is used by profiles %s", port, strings.Join(o, ", ")))
+		}
+	}
+
+	if len(problems) == 0 {
+		return doctorResult{}
+	}
+	sort.Strings(problems)
+	return doctorResult{
+		problem: strings.Join(problems, "; "),
+		fix:     "stop the conflicting process, or set a free port with 'colima config set ssh_port <port>'",
+	}
+}
+
+func checkVMDNS() doctorResult {
+	if _, err := exec.LookPath("limactl"); err != nil {
+		return doctorResult{skipped: "lima not installed"}
+	}
+	instance, err := lima.Instance(config.Profile().ShortName)
+	if err != nil || instance.Status != "Running" {
+		return doctorResult{skipped: config.Profile().DisplayName + " is not running"}
+	}
+
+	if err := host.New().RunQuiet("limactl", "shell", config.Profile().ID, "nslookup", "github.com"); err != nil {
+		return doctorResult{
+			problem: "github.com cannot be resolved in the VM",
+			fix:     "check the host network and VPN, or set custom DNS servers with 'colima start --dns 8.8.8.8'",
+		}
+	}
+	return doctorResult{}
+}
+
+func init() {
+	root.Cmd().AddCommand(doctorCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var envCmdArgs struct {
+	testcontainers bool
+	verify         bool
+}
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env [profile]",
+	Short: "print the environment variables for the profile",
+	Long: `Print the environment variables for the profile as shell exports, e.g. DOCKER_HOST for the docker runtime.
+
+With --testcontainers, the variables required by Testcontainers are included and --verify
+checks that the Ryuk container of Testcontainers can start.`,
+	Example: "  eval \"$(colima env)\"\n" +
+		"  eval \"$(colima env --testcontainers --verify)\"",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if envCmdArgs.verify && !envCmdArgs.testcontainers {
+			return fmt.Errorf("--verify requires --testcontainers")
+		}
+		conf, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if conf.Empty() {
+			return fmt.Errorf("%s has no configuration, start with 'colima start'", config.Profile().DisplayName)
+		}
+
+		env := app.ProfileEnv(conf)
+		if envCmdArgs.testcontainers {
+			if env, err = app.TestcontainersEnv(conf); err != nil {
+				return err
+			}
+		}
+		if envCmdArgs.verify {
+			// printed to stderr, the output is evaluated by the shell
+			log.Println("verifying that ryuk can start ...")
+			if err := newApp().VerifyRyuk(); err != nil {
+				return err
+			}
+			log.Println("ryuk started")
+		}
+
+		for _, e := range env {
+			kv := strings.SplitN(e, "=", 2)
+			fmt.Fprintf(cmd.OutOrStdout(), "export %s=%s\n", kv[0], util.ShellQuote(kv[1]))
+		}
+		return nil
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(envCmd)
+
+	envCmd.Flags().BoolVar(&envCmdArgs.testcontainers, "testcontainers", false, "print the environment variables for Testcontainers")
+	envCmd.Flags().BoolVar(&envCmdArgs.verify, "verify", false, "verify that the Ryuk container of Testcontainers can start")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+var eventsCmdArgs struct {
+	json   bool
+	follow bool
+}
+
+// eventsCmd represents the events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "print the lifecycle and health events",
+	Long: `Print the lifecycle and health events of the profile, for tools to react to changes of state.
+
+The types of events are:
+  started, start-failed, stopped   the profile is started or stopped
+  paused, resumed                  the VM is paused by the idle policy and resumed
+  runtime-crashed                  a service in the VM crashed and was restarted by the watchdog
+  degraded, disk-low               a health check failed
+
+runtime-crashed, degraded and disk-low (other than at startup) are detected by the health checks,
+enabled with 'health.interval' in the configuration.`,
+	Example: "  colima events\n" +
+		"  colima events --follow --json",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		return app.ReadEvents(eventsCmdArgs.follow, func(e app.Event) error {
+			if eventsCmdArgs.json {
+				return encoder.Encode(e)
+			}
+			line := e.Time.Local().Format(time.RFC3339) + " " + e.Profile + " " + e.Type
+			if e.Message != "" {
+				line += ": " + e.Message
+			}
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), line)
+			return err
+		})
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(eventsCmd)
+
+	eventsCmd.Flags().BoolVar(&eventsCmdArgs.json, "json", false, "print the events as JSON lines")
+	eventsCmd.Flags().BoolVarP(&eventsCmdArgs.follow, "follow", "f", false, "print new events as they occur")
+}
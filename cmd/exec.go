@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+var execCmdArgs app.ExecOptions
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec -- <command> [args...]",
+	Short: "run a command in the VM",
+	Long: `Run a command in the VM.
+
+The arguments are passed to the command as is, without interpretation by a shell in the VM,
+and colima exits with the exit code of the command.
+A terminal is allocated if the input is a terminal, unless overridden with --tty or --no-tty.
+
+When run from a mounted directory, the command runs in the same directory in the VM.`,
+	Example: "  colima exec -- uname -a\n" +
+		"  colima exec --root -- apk add htop\n" +
+		"  colima exec --no-tty -- cat /etc/os-release > os-release",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if execCmdArgs.TTY && execCmdArgs.NoTTY {
+			return fmt.Errorf("--tty and --no-tty cannot be used together")
+		}
+		err := newApp().Exec(execCmdArgs, args...)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// the failure is reported by the command
+			return cli.ExitError{Code: exitErr.ExitCode(), Err: err, Silent: true}
+		}
+		return err
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(execCmd)
+
+	execCmd.Flags().BoolVar(&execCmdArgs.Root, "root", false, "run the command as root")
+	execCmd.Flags().BoolVarP(&execCmdArgs.TTY, "tty", "t", false, "allocate a terminal")
+	execCmd.Flags().BoolVarP(&execCmdArgs.NoTTY, "no-tty", "T", false, "do not allocate a terminal")
+}
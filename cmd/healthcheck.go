@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+// exit codes of the failed checks of the healthcheck command, the first failed check determines the code.
+var healthcheckExitCodes = map[string]int{
+	app.HealthcheckSocket:     5,
+	app.HealthcheckDNS:        6,
+	app.HealthcheckKubernetes: 7,
+	app.HealthcheckImage:      8,
+	app.HealthcheckKubectl:    9,
+}
+
+var healthcheckCmdArgs struct {
+	json bool
+}
+
+// healthcheckCmd represents the healthcheck command
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck [profile]",
+	Short: "check that the profile is usable",
+	Long: `Check end to end that the profile is usable: the container runtime responds on its socket,
+DNS resolves from inside a container and the Kubernetes API answers, if enabled.
+
+The exit code is 0 if healthy, 2 if stopped or paused, 3 if the profile does not exist,
+5 if the runtime is not responding, 6 if DNS does not resolve, 7 if the Kubernetes API
+is not answering, 8 if the image of the DNS check cannot be pulled and 9 if kubectl is not
+installed on the host.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks, err := newApp().Healthcheck()
+		if errors.Is(err, app.ErrPaused) {
+			return cli.ExitError{Code: statusExitStopped, Err: err}
+		}
+		if err != nil {
+			return statusExitError(err)
+		}
+
+		if healthcheckCmdArgs.json {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(checks); err != nil {
+				return err
+			}
+		} else {
+			for _, c := range checks {
+				if c.Problem != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "[fail] %s: %s\n", c.Name, c.Problem)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "[ok]   %s\n", c.Name)
+				}
+			}
+		}
+
+		for _, c := range checks {
+			if c.Problem != "" {
+				return cli.ExitError{Code: healthcheckExitCodes[c.Name], Err: fmt.Errorf("%s: %s", c.Name, c.Problem)}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(healthcheckCmd)
+
+	healthcheckCmd.Flags().BoolVarP(&healthcheckCmdArgs.json, "json", "j", false, "print json output")
+}
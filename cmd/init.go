@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/pkg/colima"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init [profile]",
+	Short: "interactively create the configuration",
+	Long: `Interactively create the configuration of the profile.
+
+The runtime, resources, mounts and Kubernetes are prompted for and the
+configuration is saved. Start the profile afterwards with 'colima start'.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cli.Settings.CI {
+			return fmt.Errorf("init is interactive, configure with 'colima start' flags instead: %w", cli.ErrNonInteractive)
+		}
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		if _, err := os.Stat(config.File()); err == nil {
+			if !cli.Prompt(config.Profile().DisplayName + " is already configured, overwrite the configuration") {
+				return nil
+			}
+		}
+
+		conf := colima.DefaultConfig()
+		if template, err := config.LoadTemplate(colima.DefaultConfig()); err != nil {
+			log.Warnln(fmt.Errorf("template load failed: %w", err))
+		} else if !template.Empty() {
+			conf = template
+		}
+
+		runtimes := environment.ContainerRuntimes()
+		sort.Strings(runtimes)
+		for {
+			runtime := cli.PromptString("container runtime ("+strings.Join(runtimes, ", ")+")", conf.Runtime)
+			if contains(runtimes, runtime) {
+				conf.Runtime = runtime
+				break
+			}
+			fmt.Println("invalid runtime", runtime)
+		}
+
+		conf.VM.CPU = promptInt("number of CPUs", conf.VM.CPU)
+		conf.VM.Memory = promptInt("memory in GiB", conf.VM.Memory)
+		conf.VM.Disk = promptInt("disk size in GiB", conf.VM.Disk)
+
+		mounts := cli.PromptString("directories to mount, comma separated, suffix ':w' for writable (default home directory)", strings.Join(conf.VM.Mounts, ","))
+		conf.VM.Mounts = nil
+		for _, m := range strings.Split(mounts, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				conf.VM.Mounts = append(conf.VM.Mounts, m)
+			}
+		}
+
+		conf.Kubernetes.Enabled = cli.Prompt("enable Kubernetes")
+
+		for _, err := range colima.Validate(conf) {
+			log.Warnln(err)
+		}
+
+		if err := config.Save(conf); err != nil {
+			return fmt.Errorf("error saving config: %w", err)
+		}
+
+		fmt.Println("configuration saved at", config.File())
+		fmt.Println("run 'colima start" + profileArg() + "' to start")
+		return nil
+	},
+}
+
+// promptInt prompts for a positive integer until a valid value is given.
+func promptInt(question string, def int) int {
+	for {
+		answer := cli.PromptString(question, strconv.Itoa(def))
+		if n, err := strconv.Atoi(answer); err == nil && n > 0 {
+			return n
+		}
+		fmt.Println("invalid value", answer)
+	}
+}
+
+// profileArg returns the profile name as a command argument,
+// or an empty string for the default profile.
+func profileArg() string {
+	if config.Profile().ShortName == config.AppName {
+		return ""
+	}
+	return " " + config.Profile().ShortName
+}
+
+func init() {
+	root.Cmd().AddCommand(initCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+// kubectlCmd represents the kubectl command
+var kubectlCmd = &cobra.Command{
+	Use:   "kubectl",
+	Short: "run kubectl (requires Kubernetes)",
+	Long: `Run kubectl against the Kubernetes cluster of the profile.
+This requires Kubernetes to be enabled.
+
+The host kubeconfig and its current context are not used.
+
+It is recommended to specify '--' to differentiate from Colima flags.
+`,
+	Example: "  colima kubectl -- get pods -A\n" +
+		"  colima kubectl --profile work -- apply -f deployment.yaml",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := newApp()
+		k, err := app.Kubernetes()
+		if err != nil {
+			return err
+		}
+		if !k.Running() {
+			return fmt.Errorf("%s is not enabled", kubernetes.Name)
+		}
+
+		kubectlArgs := append([]string{"kubectl", "--kubeconfig", "/etc/rancher/k3s/k3s.yaml"}, args...)
+		return app.SSH(kubectlArgs...)
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(kubectlCmd)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/abiosoft/colima/cmd/root"
@@ -22,6 +23,9 @@ var kubernetesCmd = &cobra.Command{
 		if err := root.Cmd().PersistentPreRunE(cmd, args); err != nil {
 			return err
 		}
+		if err := lockProfile(); err != nil {
+			return err
+		}
 		if !newApp().Active() {
 			return fmt.Errorf("%s is not running", config.Profile().DisplayName)
 		}
@@ -34,7 +38,7 @@ var kubernetesStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "start the Kubernetes cluster",
 	Long:  `Start the Kubernetes cluster.`,
-	Args: cobra.NoArgs,
+	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app := newApp()
 		k, err := app.Kubernetes()
@@ -42,11 +46,19 @@ var kubernetesStartCmd = &cobra.Command{
 			return err
 		}
 
-		if err := k.Provision(); err != nil {
+		ctx, err := kubernetesCtx()
+		if err != nil {
+			return err
+		}
+		if err := k.Provision(ctx); err != nil {
+			return err
+		}
+
+		if err := k.Start(ctx); err != nil {
 			return err
 		}
 
-		return k.Start()
+		return setKubernetesEnabled(true)
 	},
 }
 
@@ -54,7 +66,9 @@ var kubernetesStartCmd = &cobra.Command{
 var kubernetesStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "stop the Kubernetes cluster",
-	Long:  `Stop the Kubernetes cluster.`,
+	Long: `Stop the Kubernetes cluster.
+
+The VM and container runtime are left running.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app := newApp()
@@ -66,7 +80,15 @@ var kubernetesStopCmd = &cobra.Command{
 			return fmt.Errorf("%s is not enabled", kubernetes.Name)
 		}
 
-		return k.Stop()
+		ctx, err := kubernetesCtx()
+		if err != nil {
+			return err
+		}
+		if err := k.Stop(ctx); err != nil {
+			return err
+		}
+
+		return setKubernetesEnabled(false)
 	},
 }
 
@@ -75,7 +97,7 @@ var kubernetesDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "delete the Kubernetes cluster",
 	Long:  `Delete the Kubernetes cluster.`,
-	Args: cobra.NoArgs,
+	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app := newApp()
 		k, err := app.Kubernetes()
@@ -86,10 +108,18 @@ var kubernetesDeleteCmd = &cobra.Command{
 			return fmt.Errorf("%s is not enabled", kubernetes.Name)
 		}
 
-		return k.Teardown()
+		if err := k.Teardown(); err != nil {
+			return err
+		}
+
+		return setKubernetesEnabled(false)
 	},
 }
 
+var kubernetesResetCmdArgs struct {
+	force bool
+}
+
 // kubernetesResetCmd represents the kubernetes reset command
 var kubernetesResetCmd = &cobra.Command{
 	Use:   "reset",
@@ -108,26 +138,143 @@ The Kubernetes images are cached making the startup (after reset) much faster.`,
 			return err
 		}
 
+		if !kubernetesResetCmdArgs.force {
+			summary := []string{"all Kubernetes objects, including persistent volumes"}
+			if ok, err := confirmDestructive(config.Profile().DisplayName, summary); !ok {
+				return err
+			}
+		}
+
 		if err := k.Teardown(); err != nil {
 			return fmt.Errorf("error deleting %s: %w", kubernetes.Name, err)
 		}
 
-		if err := k.Provision(); err != nil {
+		ctx, err := kubernetesCtx()
+		if err != nil {
+			return err
+		}
+		if err := k.Provision(ctx); err != nil {
 			return err
 		}
 
-		if err := k.Start(); err != nil {
+		if err := k.Start(ctx); err != nil {
 			return fmt.Errorf("error starting %s: %w", kubernetes.Name, err)
 		}
 
-		return nil
+		return setKubernetesEnabled(true)
+	},
+}
+
+var kubernetesUpgradeCmdArgs struct {
+	version   string
+	downgrade bool
+}
+
+// kubernetesUpgradeCmd represents the kubernetes upgrade command
+var kubernetesUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "upgrade the Kubernetes cluster",
+	Long: `Upgrade the Kubernetes cluster to the specified version in place.
+
+A snapshot of the cluster state is saved in the VM prior to the upgrade.
+An older version than the current version requires --downgrade.`,
+	Example: "  colima kubernetes upgrade --version v1.23.4\n" +
+		"  colima kubernetes upgrade --version v1.23.4+k3s1",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := newApp()
+		k, err := app.Kubernetes()
+		if err != nil {
+			return err
+		}
+		u, ok := k.(kubernetes.Upgrader)
+		if !ok {
+			return fmt.Errorf("%s does not support upgrades", kubernetes.Name)
+		}
+
+		conf, err := config.Load()
+		if err != nil {
+			return err
+		}
+		ctx := context.WithValue(context.Background(), config.CtxKey(), conf)
+		if err := u.Upgrade(ctx, kubernetesUpgradeCmdArgs.version, kubernetesUpgradeCmdArgs.downgrade); err != nil {
+			return fmt.Errorf("error upgrading %s: %w", kubernetes.Name, err)
+		}
+
+		// persist the version for subsequent startups
+		if conf.Empty() {
+			return nil
+		}
+		conf.Kubernetes.Version = kubernetesUpgradeCmdArgs.version
+		return config.Save(conf)
+	},
+}
+
+// kubernetesLoadCmd represents the kubernetes load command
+var kubernetesLoadCmd = &cobra.Command{
+	Use:   "load <image>...",
+	Short: "make local images available to the Kubernetes cluster",
+	Long: `Make locally built or pulled images available to the Kubernetes cluster.
+
+For Docker runtime, images are shared with Kubernetes and this is a no-op.
+For Containerd runtime, images are copied to the 'k8s.io' namespace.`,
+	Example: "  colima kubernetes load myapp:latest",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := newApp()
+		k, err := app.Kubernetes()
+		if err != nil {
+			return err
+		}
+		if !k.Running() {
+			return fmt.Errorf("%s is not enabled", kubernetes.Name)
+		}
+		l, ok := k.(kubernetes.ImageLoader)
+		if !ok {
+			return fmt.Errorf("%s does not support image loading", kubernetes.Name)
+		}
+
+		return l.LoadImages(args...)
 	},
 }
 
+// kubernetesCtx returns a context with the saved config for provisioning.
+func kubernetesCtx() (context.Context, error) {
+	conf, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(context.Background(), config.CtxKey(), conf), nil
+}
+
+// setKubernetesEnabled persists the Kubernetes state for subsequent startups
+// of the VM. Otherwise, 'colima start' reverts to the previous state.
+func setKubernetesEnabled(enabled bool) error {
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if conf.Empty() || conf.Kubernetes.Enabled == enabled {
+		return nil
+	}
+	conf.Kubernetes.Enabled = enabled
+	return config.Save(conf)
+}
+
 func init() {
 	root.Cmd().AddCommand(kubernetesCmd)
 	kubernetesCmd.AddCommand(kubernetesStartCmd)
 	kubernetesCmd.AddCommand(kubernetesStopCmd)
 	kubernetesCmd.AddCommand(kubernetesDeleteCmd)
 	kubernetesCmd.AddCommand(kubernetesResetCmd)
+	kubernetesCmd.AddCommand(kubernetesUpgradeCmd)
+	kubernetesCmd.AddCommand(kubernetesLoadCmd)
+
+	kubernetesUpgradeCmd.Flags().StringVar(&kubernetesUpgradeCmdArgs.version, "version", "", "the Kubernetes version to upgrade to")
+	kubernetesUpgradeCmd.Flags().BoolVar(&kubernetesUpgradeCmdArgs.downgrade, "downgrade", false, "allow an older version than the current version")
+	_ = kubernetesUpgradeCmd.MarkFlagRequired("version")
+	_ = kubernetesUpgradeCmd.RegisterFlagCompletionFunc("version", completeKubernetesVersions)
+
+	kubernetesResetCmd.Flags().BoolVarP(&kubernetesResetCmdArgs.force, "force", "f", false, "do not prompt for yes/no")
+	kubernetesResetCmd.Flags().BoolVarP(&kubernetesResetCmdArgs.force, "yes", "y", false, "alias for --force")
 }
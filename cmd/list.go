@@ -16,7 +16,7 @@ var listCmdArgs struct {
 	json bool
 }
 
-// listCmd represents the version command
+// listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
@@ -43,16 +43,17 @@ A new instance can be created during 'colima start' by specifying the '--profile
 		}
 
 		w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
-		fmt.Fprintln(w, "PROFILE\tSTATUS\tARCH\tCPUS\tMEMORY\tDISK\tADDRESS")
+		fmt.Fprintln(w, "PROFILE\tSTATUS\tRUNTIME\tARCH\tCPUS\tMEMORY\tDISK\tADDRESS")
 
 		if len(instances) == 0 {
 			logrus.Warn("No instance found. Run `colima start` to create an instance.")
 		}
 
 		for _, inst := range instances {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
 				inst.Name,
 				inst.Status,
+				inst.Runtime,
 				inst.Arch,
 				inst.CPU,
 				units.BytesSize(float64(inst.Memory)),
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/spf13/cobra"
+)
+
+var logsCmdArgs struct {
+	follow bool
+	source string
+	lines  int
+}
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "print the logs",
+	Long: `Print the logs of the VM, the container runtimes or Colima.
+
+The logs of the container runtime of the profile are printed by default.
+The sources are:
+  vm          the VM console (serial) and Lima host agent logs
+  docker      the Docker daemon in the VM
+  containerd  containerd and buildkitd in the VM
+  k3s         Kubernetes in the VM
+  watchdog    the restarts of crashed services in the VM
+  colima      the networking daemons and the monitor managed by Colima`,
+	Example: "  colima logs\n" +
+		"  colima logs --follow --source k3s\n" +
+		"  colima logs -s vm -n 500",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := logsCmdArgs.source
+		if name == "" {
+			conf, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if conf.Empty() {
+				return fmt.Errorf("%s has no configuration, start with 'colima start'", config.Profile().DisplayName)
+			}
+			name = conf.Runtime
+		}
+		source, ok := logSources[name]
+		if !ok {
+			return fmt.Errorf("invalid log source '%s', valid values are %s", name, strings.Join(logSourceNames(), ", "))
+		}
+
+		files, err := source.files()
+		if err != nil {
+			return err
+		}
+
+		args = []string{"tail", "-n", strconv.Itoa(logsCmdArgs.lines)}
+		if logsCmdArgs.follow {
+			// follow through rotations
+			args = append(args, "-F")
+		}
+
+		if source.guest {
+			args = append(args, files...)
+			return newApp().SSH(append([]string{"sudo"}, args...)...)
+		}
+
+		// missing files are waited for when following
+		if !logsCmdArgs.follow {
+			var existing []string
+			for _, f := range files {
+				if _, err := os.Stat(f); err == nil {
+					existing = append(existing, f)
+				}
+			}
+			if len(existing) == 0 {
+				return fmt.Errorf("no %s logs found for %s", name, config.Profile().DisplayName)
+			}
+			files = existing
+		}
+		args = append(args, files...)
+		return cli.CommandInteractive(args[0], args[1:]...).Run()
+	},
+}
+
+// logSource is a source of logs.
+type logSource struct {
+	// guest is true if the files are in the VM.
+	guest bool
+	files func() ([]string, error)
+}
+
+func guestLogFiles(files ...string) func() ([]string, error) {
+	return func() ([]string, error) { return files, nil }
+}
+
+var logSources = map[string]logSource{
+	"vm": {files: func() ([]string, error) {
+		dir, err := lima.InstanceDir(config.Profile().ID)
+		if err != nil {
+			return nil, err
+		}
+		return []string{filepath.Join(dir, "serial.log"), filepath.Join(dir, "ha.stderr.log")}, nil
+	}},
+	"docker":     {guest: true, files: guestLogFiles("/var/log/docker.log")},
+	"containerd": {guest: true, files: guestLogFiles("/var/log/containerd.log", "/var/log/buildkitd.log")},
+	"k3s":        {guest: true, files: guestLogFiles("/var/log/k3s.log")},
+	"watchdog":   {guest: true, files: guestLogFiles(lima.WatchdogLogFile)},
+	"colima": {files: func() ([]string, error) {
+		dir := filepath.Join(config.Dir(), "network")
+		return []string{filepath.Join(dir, "vmnet.stdout"), filepath.Join(dir, "vmnet.stderr"), app.MonitorLogFile()}, nil
+	}},
+}
+
+func logSourceNames() []string {
+	var names []string
+	for name := range logSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	root.Cmd().AddCommand(logsCmd)
+
+	logsCmd.Flags().BoolVarP(&logsCmdArgs.follow, "follow", "f", false, "follow the log output")
+	logsCmd.Flags().StringVarP(&logsCmdArgs.source, "source", "s", "", "the source of the logs ("+strings.Join(logSourceNames(), ", ")+")")
+	logsCmd.Flags().IntVarP(&logsCmdArgs.lines, "lines", "n", 100, "number of lines to print from the end of the logs")
+
+	_ = logsCmd.RegisterFlagCompletionFunc("source", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return logSourceNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var metricsCmdArgs struct {
+	listen string
+}
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "print or serve metrics in Prometheus format",
+	Long: `Print the metrics of the VM and the container runtime in the Prometheus text format.
+
+The metrics include the resource usage, uptime, disk free, forwarded ports and the usage
+of each container. With --listen, the metrics are served at /metrics for scraping until
+interrupted, otherwise they are printed once e.g. for the node exporter textfile collector.`,
+	Example: "  colima metrics\n" +
+		"  colima metrics --listen 127.0.0.1:9101",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		colimaApp := newApp()
+		if metricsCmdArgs.listen == "" {
+			return writeMetrics(cmd.OutOrStdout(), colimaApp)
+		}
+
+		http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			var buf bytes.Buffer
+			if err := writeMetrics(&buf, colimaApp); err != nil {
+				log.Warnln(fmt.Errorf("error collecting metrics: %w", err))
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			_, _ = buf.WriteTo(w)
+		})
+		log.Println("serving metrics of", config.Profile().DisplayName, "at http://"+metricsCmdArgs.listen+"/metrics")
+		return http.ListenAndServe(metricsCmdArgs.listen, nil)
+	},
+}
+
+// writeMetrics writes the metrics of the current profile to w.
+// Only colima_up is written if the VM is not running.
+func writeMetrics(w io.Writer, colimaApp app.App) error {
+	m := metricsWriter{w: w, profile: config.Profile().ShortName}
+
+	stats, err := colimaApp.Stats()
+	if errors.Is(err, app.ErrNotRunning) {
+		m.metric("colima_up", "gauge", "Whether the VM is running.", sample{value: 0})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.metric("colima_up", "gauge", "Whether the VM is running.", sample{value: 1})
+	m.metric("colima_uptime_seconds", "gauge", "Uptime of the VM.", sample{value: float64(stats.Uptime)})
+	m.metric("colima_cpus", "gauge", "Number of CPUs of the VM.", sample{value: float64(stats.CPUs)})
+	m.metric("colima_cpu_usage_percent", "gauge", "CPU usage of the VM in percent of all CPUs.", sample{value: stats.CPU})
+	m.metric("colima_memory_used_bytes", "gauge", "Memory in use in the VM.", sample{value: float64(stats.MemoryUsed)})
+	m.metric("colima_memory_total_bytes", "gauge", "Memory of the VM.", sample{value: float64(stats.MemoryTotal)})
+	m.metric("colima_disk_used_bytes", "gauge", "Used space of the disk holding the container runtime data.", sample{value: float64(stats.DiskUsed)})
+	m.metric("colima_disk_free_bytes", "gauge", "Free space of the disk holding the container runtime data.", sample{value: float64(stats.DiskTotal - stats.DiskUsed)})
+	m.metric("colima_disk_total_bytes", "gauge", "Size of the disk holding the container runtime data.", sample{value: float64(stats.DiskTotal)})
+	m.metric("colima_network_receive_bytes_total", "counter", "Bytes received by the VM.", sample{value: float64(stats.NetworkRx)})
+	m.metric("colima_network_transmit_bytes_total", "counter", "Bytes sent by the VM.", sample{value: float64(stats.NetworkTx)})
+
+	var ports []sample
+	for _, p := range stats.Ports {
+		ports = append(ports, sample{labels: []string{"port", strconv.Itoa(p)}, value: 1})
+	}
+	m.metric("colima_forwarded_port", "gauge", "TCP ports forwarded from the VM to the host.", ports...)
+
+	containers, err := colimaApp.Top()
+	if err != nil {
+		// not fatal, the VM metrics remain useful
+		log.Warnln(err)
+		return nil
+	}
+	var cpu, memory []sample
+	for _, c := range containers {
+		labels := []string{"namespace", c.Namespace, "name", c.Name, "id", c.ID}
+		cpu = append(cpu, sample{labels: labels, value: c.CPU})
+		memory = append(memory, sample{labels: labels, value: float64(c.Memory)})
+	}
+	m.metric("colima_containers", "gauge", "Number of running containers.", sample{value: float64(len(containers))})
+	m.metric("colima_container_cpu_usage_percent", "gauge", "CPU usage of the container.", cpu...)
+	m.metric("colima_container_memory_bytes", "gauge", "Memory usage of the container.", memory...)
+	return nil
+}
+
+// sample is a sample of a metric, labels are name value pairs.
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// metricsWriter writes metrics in the Prometheus text format, labelled with the profile.
+type metricsWriter struct {
+	w       io.Writer
+	profile string
+}
+
+func (m metricsWriter) metric(name, kind, help string, samples ...sample) {
+	if len(samples) == 0 {
+		return
+	}
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, s := range samples {
+		labels := []string{`profile="` + escapeLabel(m.profile) + `"`}
+		for i := 0; i+1 < len(s.labels); i += 2 {
+			labels = append(labels, s.labels[i]+`="`+escapeLabel(s.labels[i+1])+`"`)
+		}
+		fmt.Fprintf(m.w, "%s{%s} %s\n", name, strings.Join(labels, ","), strconv.FormatFloat(s.value, 'f', -1, 64))
+	}
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func init() {
+	root.Cmd().AddCommand(metricsCmd)
+
+	metricsCmd.Flags().StringVar(&metricsCmdArgs.listen, "listen", "", "address to serve the metrics at, e.g. 127.0.0.1:9101")
+}
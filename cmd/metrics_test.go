@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func Test_metricsWriter_metric(t *testing.T) {
+	tests := []struct {
+		samples []sample
+		want    string
+	}{
+		{
+			samples: []sample{{value: 1.5}},
+			want:    "# HELP colima_test Test.\n# TYPE colima_test gauge\ncolima_test{profile=\"dev\"} 1.5\n",
+		},
+		{
+			samples: []sample{{labels: []string{"name", `web "1"`}, value: 2}, {labels: []string{"name", "a\\b\nc"}, value: 0}},
+			want: "# HELP colima_test Test.\n# TYPE colima_test gauge\n" +
+				"colima_test{profile=\"dev\",name=\"web \\\"1\\\"\"} 2\n" +
+				"colima_test{profile=\"dev\",name=\"a\\\\b\\nc\"} 0\n",
+		},
+		// not written without samples
+		{want: ""},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			metricsWriter{w: &buf, profile: "dev"}.metric("colima_test", "gauge", "Test.", tt.samples...)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("metric() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
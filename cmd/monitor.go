@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/spf13/cobra"
+)
+
+var monitorCmdArgs struct {
+	background bool
+}
+
+// monitorCmd represents the monitor command
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "run the idle policy and health checks of the profile",
+	Long: `Run the idle policy and health checks of the profile in the foreground.
+
+It is started in the background by 'colima start' when 'idle.timeout' or 'health.interval' is set,
+and by 'colima stop' and autostart when 'lazy_start' is set.`,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if monitorCmdArgs.background {
+			conf, err := config.Load()
+			if err != nil {
+				return err
+			}
+			return app.RestartMonitor(conf)
+		}
+		return newApp().Monitor()
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(monitorCmd)
+
+	monitorCmd.Flags().BoolVar(&monitorCmdArgs.background, "background", false, "start the monitor in the background and exit")
+}
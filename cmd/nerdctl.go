@@ -13,6 +13,7 @@ import (
 	"github.com/abiosoft/colima/cmd/root"
 	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment/container/containerd"
+	"github.com/abiosoft/colima/util"
 	"github.com/spf13/cobra"
 )
 
@@ -43,11 +44,32 @@ It is recommended to specify '--' to differentiate from Colima flags.
 			return fmt.Errorf("nerdctl only supports %s runtime", containerd.Name)
 		}
 
-		nerdctlArgs := append([]string{"sudo", "nerdctl"}, args...)
+		nerdctlArgs := append([]string{"sudo", "nerdctl"}, withContainerLocale(args, util.HostLocale())...)
 		return app.SSH(nerdctlArgs...)
 	},
 }
 
+// withContainerLocale returns the nerdctl args with LANG of the containers created by run and create
+// defaulting to locale. A LANG set with the args takes precedence.
+func withContainerLocale(args []string, locale string) []string {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		// the subcommand, the global flags with a separate value are not recognised
+		if arg == "container" {
+			continue
+		}
+		if arg != "run" && arg != "create" {
+			return args
+		}
+		a := append([]string{}, args[:i+1]...)
+		a = append(a, "--env", "LANG="+locale)
+		return append(a, args[i+1:]...)
+	}
+	return args
+}
+
 // nerdctlLinkFunc represents the nerdctl command
 var nerdctlLinkFunc = func() *cobra.Command {
 	return &cobra.Command{
@@ -97,7 +119,7 @@ var nerdctlLinkFunc = func() *cobra.Command {
 				Profile   string
 			}{
 				ColimaApp: os.Args[0],
-				Profile:   strings.TrimPrefix(config.Profile().ID, "colima-"),
+				Profile:   config.Profile().ShortName,
 			}
 			var buf bytes.Buffer
 			if err := t.Execute(&buf, values); err != nil {
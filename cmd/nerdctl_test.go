@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_withContainerLocale(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"run", "alpine"}, want: []string{"run", "--env", "LANG=en_GB.UTF-8", "alpine"}},
+		{args: []string{"--debug", "container", "create", "-e", "LANG=C.UTF-8", "alpine"}, want: []string{"--debug", "container", "create", "--env", "LANG=en_GB.UTF-8", "-e", "LANG=C.UTF-8", "alpine"}},
+		{args: []string{"ps", "-a"}, want: []string{"ps", "-a"}},
+		{args: []string{"container", "ls"}, want: []string{"container", "ls"}},
+		{args: []string{"exec", "web", "run"}, want: []string{"exec", "web", "run"}},
+		{args: nil, want: nil},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := withContainerLocale(tt.args, "en_GB.UTF-8"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withContainerLocale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var pruneCmdArgs struct {
+	force  bool
+	dryRun bool
+	lima   bool
+}
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "prune cached downloads and orphaned files",
+	Long: `Prune cached downloads and orphaned files to reclaim space on the host.
+
+The downloads cached by all profiles e.g. k3s and nerdctl are removed, and are
+downloaded again when needed. The directories of profiles that no longer exist
+are removed, except for the data kept by 'colima delete --keep-data'.
+
+The VM images cached by Lima are only removed with --lima, the cache is shared
+with other Lima instances.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := pruneTargets(pruneCmdArgs.lima)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			log.Println("nothing to prune")
+			return nil
+		}
+
+		var total int64
+		var summary []string
+		for _, t := range targets {
+			total += t.size
+			summary = append(summary, fmt.Sprintf("%s (%s): %s", t.description, units.BytesSize(float64(t.size)), t.path))
+		}
+
+		if pruneCmdArgs.dryRun {
+			for _, s := range summary {
+				fmt.Println(s)
+			}
+			fmt.Println("reclaimable space:", units.BytesSize(float64(total)))
+			return nil
+		}
+
+		if !pruneCmdArgs.force {
+			if ok, err := confirmDestructive("all profiles", summary); !ok {
+				return err
+			}
+		}
+
+		for _, t := range targets {
+			if err := os.RemoveAll(t.path); err != nil {
+				log.Warnln(fmt.Errorf("error removing %s: %w", t.path, err))
+				total -= t.size
+			}
+		}
+		log.Println("reclaimed", units.BytesSize(float64(total)))
+		return nil
+	},
+}
+
+// pruneTarget is a file or directory to prune.
+type pruneTarget struct {
+	path        string
+	description string
+	size        int64
+}
+
+// pruneTargets returns the files and directories that can be pruned.
+// The Lima download cache is included if includeLima is true.
+func pruneTargets(includeLima bool) ([]pruneTarget, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := config.HostDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	// profiles are identified by the config file or the Lima instance
+	exists := map[string]bool{}
+	if profiles, err := config.Profiles(); err == nil {
+		for _, p := range profiles {
+			exists[config.ProfileFromName(p).ID] = true
+		}
+	}
+	if _, err := exec.LookPath("limactl"); err == nil {
+		instances, err := lima.Instances()
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range instances {
+			exists[config.ProfileFromName(i.Name).ID] = true
+		}
+	}
+
+	var targets []pruneTarget
+	add := func(path, description string) {
+		if _, err := os.Stat(path); err == nil {
+			targets = append(targets, pruneTarget{path: path, description: description, size: dirSize(path)})
+		}
+	}
+
+	for _, dir := range dirs {
+		var id string
+		switch filepath.Dir(dir) {
+		case home:
+			id = strings.TrimPrefix(filepath.Base(dir), ".")
+		case cache:
+			if filepath.Base(dir) == config.SharedCacheName {
+				add(dir, "cached downloads shared by all profiles")
+				continue
+			}
+			id = filepath.Base(dir)
+		default:
+			// shared config directory
+			continue
+		}
+
+		if filepath.Dir(dir) == cache {
+			add(filepath.Join(dir, "caches"), "cached downloads of "+id)
+			if kept, _ := filepath.Glob(filepath.Join(dir, "data-*.tar.gz")); len(kept) > 0 {
+				continue
+			}
+		}
+		if !exists[id] {
+			if dir == filepath.Join(home, "."+config.AppName) {
+				// the config directory of the default profile holds the directories shared by all profiles
+				entries, err := config.ProfileEntries(dir)
+				if err != nil {
+					return nil, err
+				}
+				for _, e := range entries {
+					add(filepath.Join(dir, e), "orphaned file of "+id)
+				}
+				continue
+			}
+			add(dir, "orphaned directory of "+id)
+		}
+	}
+
+	if includeLima {
+		add(filepath.Join(cache, "lima", "download"), "VM images cached by Lima")
+	}
+
+	return dedupeTargets(targets), nil
+}
+
+// dedupeTargets removes targets within other targets.
+func dedupeTargets(targets []pruneTarget) []pruneTarget {
+	var deduped []pruneTarget
+	for _, t := range targets {
+		within := false
+		for _, o := range targets {
+			if t.path != o.path && strings.HasPrefix(t.path, o.path+string(filepath.Separator)) {
+				within = true
+				break
+			}
+		}
+		if !within {
+			deduped = append(deduped, t)
+		}
+	}
+	return deduped
+}
+
+// dirSize returns the total size of the files in dir.
+func dirSize(dir string) (size int64) {
+	_ = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func init() {
+	root.Cmd().AddCommand(pruneCmd)
+
+	pruneCmd.Flags().BoolVarP(&pruneCmdArgs.force, "force", "f", false, "do not prompt for yes/no")
+	pruneCmd.Flags().BoolVarP(&pruneCmdArgs.force, "yes", "y", false, "alias for --force")
+	pruneCmd.Flags().BoolVar(&pruneCmdArgs.dryRun, "dry-run", false, "print what would be pruned and the reclaimable space")
+	pruneCmd.Flags().BoolVar(&pruneCmdArgs.lima, "lima", false, "also prune the VM images cached by Lima")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_pruneTargets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	// no Lima instances
+	t.Setenv("PATH", t.TempDir())
+
+	// the default profile without a config, with the directories shared by all profiles
+	for _, file := range []string{
+		".colima/_templates/default.yaml",
+		".colima/_shared/daemon.log",
+		".colima/ssh_config",
+		".colima-orphan/ssh_config",
+		".colima-kept/colima.yaml",
+	} {
+		file = filepath.Join(home, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	targets, err := pruneTargets(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, target := range targets {
+		got = append(got, target.path)
+	}
+	sort.Strings(got)
+	want := []string{filepath.Join(home, ".colima-orphan"), filepath.Join(home, ".colima", "ssh_config")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pruneTargets() = %v, want %v", got, want)
+	}
+}
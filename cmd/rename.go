@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename <profile> <new-name>",
+	Short: "rename a profile",
+	Long: `Rename a profile, including its VM, configuration and cache.
+
+The VM must be stopped. The docker context and kubeconfig entries of the profile
+are removed and recreated with the new name on the next startup.`,
+	Example: "  colima rename default work\n" +
+		"  colima rename dev staging",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		return newApp().Rename(args[1])
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(renameCmd)
+}
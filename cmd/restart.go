@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/pkg/colima"
+	"github.com/spf13/cobra"
+)
+
+// restartCmd represents the restart command
+var restartCmd = &cobra.Command{
+	Use:   "restart [profile]",
+	Short: "restart Colima",
+	Long: `Stop and then start Colima.
+
+The currently saved configuration is used on startup.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		conf, err := config.LoadInto(colima.DefaultConfig())
+		if err != nil {
+			return err
+		}
+		if conf.Empty() {
+			return fmt.Errorf("%s has not been started before, run 'colima start' instead", config.Profile().DisplayName)
+		}
+
+		app := newApp()
+		if err := app.Stop(false); err != nil {
+			return fmt.Errorf("error stopping: %w", err)
+		}
+
+		if err := waitStopped(app); err != nil {
+			return err
+		}
+
+		return app.Start(conf)
+	},
+}
+
+// waitStopped waits for the VM to be stopped and the forwarded sockets to be released,
+// they are forwarded again on start. A socket left behind without a listener is released.
+func waitStopped(a app.App) error {
+	released := func() bool {
+		for _, file := range []string{docker.HostSocketFile(), docker.VMSocketFile()} {
+			if conn, err := net.Dial("unix", file); err == nil {
+				_ = conn.Close()
+				return false
+			}
+		}
+		return true
+	}
+
+	timeout := time.Minute
+	for deadline := time.Now().Add(timeout); a.Active() || !released(); time.Sleep(time.Second) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s did not stop after %v", config.Profile().DisplayName, timeout)
+		}
+	}
+	return nil
+}
+
+func init() {
+	root.Cmd().AddCommand(restartCmd)
+}
@@ -0,0 +1,89 @@
+package root
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+)
+
+// pluginPrefix is the prefix of the executables of plugins, 'colima foo' runs colima-foo.
+const pluginPrefix = "colima-"
+
+// runPlugin runs the plugin for the subcommand in args, if it is not a colima command.
+// It only returns if there is no plugin for args.
+func runPlugin(args []string) error {
+	i := subcommandIndex(args)
+	if i < 0 {
+		return nil
+	}
+	name := args[i]
+	if cmd, _, err := rootCmd.Find(args[i : i+1]); err == nil && cmd != rootCmd {
+		return nil
+	}
+	// help, shell completions and the internal colima-vmnet
+	if name == "help" || name == "vmnet" || strings.HasPrefix(name, "__") {
+		return nil
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return nil
+	}
+
+	// the flags before the subcommand are for colima, the rest for the plugin
+	if err := rootCmd.ParseFlags(args[:i]); err != nil {
+		return err
+	}
+	if err := cli.ApplyEnv(rootCmd.PersistentFlags()); err != nil {
+		return err
+	}
+	if rootCmdArgs.Profile != "" {
+		config.SetProfile(rootCmdArgs.Profile)
+	}
+
+	env := os.Environ()
+	conf, _ := config.LoadProfile(config.Profile().ShortName)
+	env = append(env, app.ProfileEnv(conf)...)
+	if executable, err := os.Executable(); err == nil {
+		env = append(env, "COLIMA_BIN="+executable)
+	}
+	if rootCmdArgs.Verbose || rootCmdArgs.Debug {
+		env = append(env, "COLIMA_VERBOSE=1")
+	}
+
+	if err := syscall.Exec(path, append([]string{path}, args[i+1:]...), env); err != nil {
+		return fmt.Errorf("error running plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+// subcommandIndex returns the index of the first argument that is not a flag of colima
+// or its value, -1 if none.
+func subcommandIndex(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		flag := rootCmd.PersistentFlags().Lookup(strings.TrimLeft(arg, "-"))
+		if !strings.HasPrefix(arg, "--") && len(arg) == 2 {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(arg[1:])
+		}
+		// the next argument is the value of the flag
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return -1
+}
@@ -0,0 +1,33 @@
+package root
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_subcommandIndex(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: []string{"foo"}, want: 0},
+		{args: []string{"foo", "--profile", "dev"}, want: 0},
+		{args: []string{"--profile", "dev", "foo"}, want: 2},
+		{args: []string{"-p", "dev", "foo"}, want: 2},
+		{args: []string{"--profile=dev", "foo"}, want: 1},
+		// boolean flags have no separate value
+		{args: []string{"--verbose", "foo"}, want: 1},
+		{args: []string{"-q", "foo"}, want: 1},
+		{args: []string{"--log-format", "json", "--debug", "foo", "bar"}, want: 3},
+		{args: []string{"--profile", "dev"}, want: -1},
+		{args: []string{"--", "foo"}, want: -1},
+		{args: nil, want: -1},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := subcommandIndex(tt.args); got != tt.want {
+				t.Errorf("subcommandIndex(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package root
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util/release"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -13,30 +18,73 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "colima",
 	Short: "container runtimes on macOS with minimal setup",
-	Long:  `Colima provides container runtimes on macOS with minimal setup.`,
+	Long: `Colima provides container runtimes on macOS with minimal setup.
+
+All commands target the profile specified with --profile, or the COLIMA_PROFILE environment variable.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// usage is meant for invalid flags and args, not failures.
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
 
 		switch cmd.Name() {
-		// special case handling for commands directly interacting with the VM
-		// start, stop, delete, status, version, ssh-config
-		case "start", "stop", "delete", "status", "version", "ssh-config":
-			// if an arg is passed, assume it to be the profile (provided --profile is unset)
+		// special case handling for top-level commands directly interacting with the VM
+		// start, stop, restart, delete, status, version, ssh-config, validate, init, rename, stats
+		case "start", "stop", "restart", "delete", "status", "version", "ssh-config", "validate", "init", "rename", "stats":
+			if cmd.Parent() != cmd.Root() || len(args) == 0 {
+				break
+			}
+			// if an arg is passed, assume it to be the profile
 			// i.e. colima start docker == colima start --profile=docker
-			if len(args) > 0 && !cmd.Flag("profile").Changed {
-				rootCmdArgs.Profile = args[0]
+			if cmd.Flag("profile").Changed {
+				if config.ProfileFromName(args[0]).ID != config.ProfileFromName(rootCmdArgs.Profile).ID {
+					return fmt.Errorf("conflicting profiles '%s' and '%s' specified", args[0], rootCmdArgs.Profile)
+				}
+				break
+			}
+			if err := cmd.Flags().Set("profile", args[0]); err != nil {
+				return err
+			}
+		}
+		// COLIMA_PROFILE, COLIMA_VERBOSE
+		if err := cli.ApplyEnv(cmd.InheritedFlags()); err != nil {
+			return err
+		}
+		// profile of the project-local config file, only for start.
+		// other commands e.g. stop and delete target it with the profile arg.
+		if cmd.Name() == "start" && cmd.Parent() == cmd.Root() && !cmd.Flag("profile").Changed {
+			if err := setProjectProfile(cmd); err != nil {
+				return err
 			}
 		}
 		if rootCmdArgs.Profile != "" {
 			config.SetProfile(rootCmdArgs.Profile)
 		}
+		// CI mode is detected if not explicitly set
+		if !cmd.Flag("ci").Changed {
+			rootCmdArgs.CI = cli.DetectCI()
+		}
+		// log format of the profile config
+		if !cmd.Flag("log-format").Changed {
+			if conf, err := config.LoadProfile(config.Profile().ShortName); err == nil && conf.LogFormat != "" {
+				rootCmdArgs.LogFormat = conf.LogFormat
+			}
+		}
 		if err := initLog(); err != nil {
 			return err
 		}
-
-		cmd.SilenceUsage = true
-		cmd.SilenceErrors = true
 		return nil
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if os.Getenv(cli.EnvPrefix+"UPDATE_NOTICE") != "1" || cli.Settings.CI {
+			return
+		}
+		// not for update itself and shell completions
+		switch cmd.Name() {
+		case "update", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return
+		}
+		updateNotice()
+	},
 }
 
 // Cmd returns the root command.
@@ -46,31 +94,117 @@ func Cmd() *cobra.Command {
 
 // rootCmdArgs holds all flags configured in root Cmd
 var rootCmdArgs struct {
-	Profile string
-	Verbose bool
+	Profile   string
+	Verbose   bool
+	Debug     bool
+	Quiet     bool
+	LogFormat string
+	CI        bool
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	if err := runPlugin(os.Args[1:]); err != nil {
+		Fatal(err)
+	}
 	if err := rootCmd.Execute(); err != nil {
-		logrus.Fatal(err)
+		Fatal(err)
+	}
+}
+
+// Fatal prints err with its remediation hint (if any) and exits with the exit code of its category.
+func Fatal(err error) {
+	code, hint := cli.ExitCode(err)
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	if cli.Settings.CI {
+		// machine-readable error for CI pipelines
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+		entry = entry.WithField("exit_code", code)
+	}
+	if hint != "" {
+		entry = entry.WithField("hint", hint)
+	}
+	if exitErr := (cli.ExitError{}); !errors.As(err, &exitErr) || !exitErr.Silent {
+		entry.Error(err)
 	}
+	os.Exit(code)
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&rootCmdArgs.Verbose, "verbose", rootCmdArgs.Verbose, "enable verbose log")
-	rootCmd.PersistentFlags().StringVarP(&rootCmdArgs.Profile, "profile", "p", "default", "profile name, for multiple instances")
+	rootCmd.PersistentFlags().BoolVar(&rootCmdArgs.Debug, "debug", rootCmdArgs.Debug, "enable debug log, including the commands executed")
+	rootCmd.PersistentFlags().BoolVarP(&rootCmdArgs.Quiet, "quiet", "q", rootCmdArgs.Quiet, "only print errors")
+	rootCmd.PersistentFlags().StringVarP(&rootCmdArgs.Profile, "profile", "p", "default", "profile name, for multiple instances (env COLIMA_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdArgs.LogFormat, "log-format", "text", "log format, text or json")
+	rootCmd.PersistentFlags().BoolVar(&rootCmdArgs.CI, "ci", rootCmdArgs.CI, "non-interactive mode for CI, detected if $CI is true (env COLIMA_CI)")
 }
 
-func initLog() error {
-	// general log output
-	log.SetOutput(logrus.New().Writer())
-	log.SetFlags(0)
+// setProjectProfile sets the profile to the one defined in the project-local config file
+// of the current directory or its parents, if any.
+func setProjectProfile(cmd *cobra.Command) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	file := config.FindProjectFile(dir)
+	if file == "" {
+		return nil
+	}
 
+	profile, err := config.ProjectProfile(file)
+	if err != nil {
+		return err
+	}
+	return cmd.Flags().Set("profile", profile)
+}
+
+// updateNotice notifies of a new version of colima, if any.
+// The latest release is cached for all profiles.
+func updateNotice() {
+	dir := config.SharedCacheDir()
+	if dir == "" {
+		return
+	}
+
+	if latest := release.Notice(config.AppVersion().Version, filepath.Join(dir, "release.json")); latest != "" {
+		logrus.Infof("a new version of colima is available: %s, run 'colima update' to update", latest)
+	}
+}
+
+func initLog() error {
+	if rootCmdArgs.Quiet && (rootCmdArgs.Verbose || rootCmdArgs.Debug) {
+		return fmt.Errorf("--quiet cannot be used with --verbose or --debug")
+	}
 	if rootCmdArgs.Verbose {
 		cli.Settings.Verbose = rootCmdArgs.Verbose
 	}
+	switch {
+	case rootCmdArgs.Quiet:
+		cli.Settings.Quiet = true
+		logrus.SetLevel(logrus.ErrorLevel)
+	case rootCmdArgs.Debug:
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	cli.Settings.CI = rootCmdArgs.CI
+
+	switch rootCmdArgs.LogFormat {
+	case "text":
+		if rootCmdArgs.CI {
+			// plain output for CI logs
+			logrus.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+		}
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("invalid log format '%s', valid values are text, json", rootCmdArgs.LogFormat)
+	}
+	cli.Settings.LogFormat = rootCmdArgs.LogFormat
+
+	// general log output
+	log.SetOutput(logrus.StandardLogger().Writer())
+	log.SetFlags(0)
 
 	return nil
 }
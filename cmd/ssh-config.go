@@ -1,29 +1,149 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/abiosoft/colima/cmd/root"
 	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/util"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// the markers of the section of the SSH config managed by colima.
+const (
+	sshConfigBegin = "# BEGIN colima, managed by 'colima ssh-config --install'"
+	sshConfigEnd   = "# END colima"
+)
+
 // statusCmd represents the status command
 var sshConfigCmd = &cobra.Command{
 	Use:   "ssh-config [profile]",
 	Short: "show SSH connection config",
-	Long:  `Show configuration of the SSH connection to the VM.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Show configuration of the SSH connection to the VM.
+
+The host of a profile is colima-<profile>, e.g. 'ssh colima-default'.
+With --install, the config of all profiles is written to ~/.ssh/config for ssh, rsync and editors
+to connect to the VMs directly. It is to be reinstalled when profiles are created or deleted.`,
+	Example: "  colima ssh-config\n" +
+		"  colima ssh-config --all >> ~/.ssh/config\n" +
+		"  colima ssh-config --install",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return lima.ShowSSH(config.Profile().ID, sshConfigCmdArgs.format)
+		if sshConfigCmdArgs.uninstall {
+			return installSSHConfig("")
+		}
+		if sshConfigCmdArgs.format != "config" {
+			if sshConfigCmdArgs.all || sshConfigCmdArgs.install {
+				return fmt.Errorf("--all and --install are only supported for the config format")
+			}
+			return lima.ShowSSH(config.Profile().ID, sshConfigCmdArgs.format)
+		}
+
+		profiles := []config.ProfileInfo{config.Profile()}
+		if sshConfigCmdArgs.all || sshConfigCmdArgs.install {
+			instances, err := lima.Instances()
+			if err != nil {
+				return err
+			}
+			profiles = nil
+			for _, i := range instances {
+				profiles = append(profiles, config.ProfileFromName(i.Name))
+			}
+		}
+
+		var blocks []string
+		for _, p := range profiles {
+			aliases := []string{p.ID}
+			if p.ID == config.AppName {
+				// colima is the host of the default profile prior to the aliases
+				aliases = []string{config.AppName + "-default", p.ID}
+			}
+			block, err := lima.SSHConfig(p.ID, aliases...)
+			if err != nil {
+				return fmt.Errorf("error retrieving ssh config for %s: %w", p.DisplayName, err)
+			}
+			blocks = append(blocks, block)
+		}
+		out := strings.Join(blocks, "\n")
+
+		if sshConfigCmdArgs.install {
+			return installSSHConfig(out)
+		}
+		fmt.Print(out)
+		return nil
 	},
 }
 
+// installSSHConfig replaces the section of ~/.ssh/config managed by colima with content,
+// the section is removed if content is empty.
+func installSSHConfig(content string) error {
+	file := filepath.Join(util.HomeDir(), ".ssh", "config")
+	b, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading ssh config: %w", err)
+	}
+
+	// the existing section is replaced in place, a new one is prepended
+	// as the first obtained value of each parameter is used by ssh.
+	before, after := string(b), ""
+	if i := strings.Index(before, sshConfigBegin); i >= 0 {
+		rest := before[i:]
+		before = before[:i]
+		if j := strings.Index(rest, sshConfigEnd); j >= 0 {
+			after = rest[j+len(sshConfigEnd):]
+		} else {
+			after = rest[len(sshConfigBegin):]
+		}
+	} else {
+		if content == "" {
+			return nil
+		}
+		before, after = "", before
+	}
+
+	// avoid accumulating blank lines on reinstalls
+	after = strings.TrimLeft(after, "\n")
+	section := ""
+	if content != "" {
+		section = sshConfigBegin + "\n" + content + sshConfigEnd + "\n"
+		if after != "" {
+			section += "\n"
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("error creating ssh config directory: %w", err)
+	}
+	if err := os.WriteFile(file, []byte(before+section+after), 0600); err != nil {
+		return fmt.Errorf("error writing ssh config: %w", err)
+	}
+
+	if content == "" {
+		log.Println("removed colima hosts from", file)
+	} else {
+		log.Println("installed colima hosts in", file)
+	}
+	return nil
+}
+
 var sshConfigCmdArgs struct {
-	format string
+	format    string
+	all       bool
+	install   bool
+	uninstall bool
 }
 
 func init() {
 	root.Cmd().AddCommand(sshConfigCmd)
 
 	sshConfigCmd.Flags().StringVarP(&sshConfigCmdArgs.format, "format", "f", "config", "format (config, cmd)")
+	sshConfigCmd.Flags().BoolVarP(&sshConfigCmdArgs.all, "all", "a", false, "show the config of all profiles")
+	sshConfigCmd.Flags().BoolVar(&sshConfigCmdArgs.install, "install", false, "install the config of all profiles in ~/.ssh/config")
+	sshConfigCmd.Flags().BoolVar(&sshConfigCmdArgs.uninstall, "uninstall", false, "remove the config installed in ~/.ssh/config")
 }
@@ -8,13 +8,15 @@ import (
 // sshCmd represents the ssh command
 var sshCmd = &cobra.Command{
 	Use:     "ssh",
-	Aliases: []string{"exec", "x"},
+	Aliases: []string{"x"},
 	Short:   "SSH into the VM",
 	Long: `SSH into the VM.
 
 Appending additional command runs the command instead.
 e.g. 'colima ssh -- htop' will run htop.
 
+When run from a mounted directory, the command runs in the same directory in the VM.
+
 It is recommended to specify '--' to differentiate from colima flags.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return newApp().SSH(args...)
@@ -1,16 +1,23 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"runtime"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/cmd/root"
 	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment"
 	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/pkg/colima"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // startCmd represents the start command
@@ -19,100 +26,381 @@ var startCmd = &cobra.Command{
 	Short: "start Colima",
 	Long: `Start Colima with the specified container runtime (and kubernetes if --with-kubernetes is passed).
 The --runtime, --disk and --arch flags are only used on initial start and ignored on subsequent starts.
+
+Flags can also be set with environment variables prefixed with COLIMA_, e.g. COLIMA_CPU=4 or COLIMA_WITH_KUBERNETES=true.
+Flags take precedence over environment variables, which take precedence over the config file.
 `,
 	Example: "  colima start\n" +
 		"  colima start --runtime containerd\n" +
 		"  colima start --with-kubernetes\n" +
 		"  colima start --runtime containerd --with-kubernetes\n" +
+		"  colima start worker1 --kubernetes-join default\n" +
 		"  colima start --cpu 4 --memory 8 --disk 100\n" +
 		"  colima start --arch aarch64\n" +
+		"  colima start --edit\n" +
+		"  colima start -f ./colima.yaml\n" +
+		"  colima start --template kubernetes-dev\n" +
+		"  colima start --cpu 4 --dry-run\n" +
+		"  colima start --memory 8 --save=false\n" +
+		"  colima start --all --parallel\n" +
 		"  colima start --dns 1.1.1.1 --dns 8.8.8.8",
-	Args: cobra.MaximumNArgs(1),
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if startCmdArgs.Kubernetes.Join != "" {
+			startCmdArgs.Kubernetes.Enabled = true
+		}
+		if startCmdArgs.all {
+			return runAll(startCmdArgs.parallel, "start")
+		}
+		if startCmdArgs.dryRun {
+			return dryRun(startCmdArgs.Config)
+		}
+		if startCmdArgs.profileStartup {
+			cli.EnableTimings()
+			// printed on failures as well, slow steps are likely to time out
+			defer cli.PrintTimings(os.Stderr)
+		}
 		return newApp().Start(startCmdArgs.Config)
 	},
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		current, err := config.Load()
+		if startCmdArgs.all {
+			// the profiles are started with their saved config
+			return checkAllFlags(cmd, args, "parallel")
+		}
+		if err := cli.ApplyEnv(cmd.LocalFlags()); err != nil {
+			return err
+		}
+		// held until the config is saved
+		if err := lockProfile(); err != nil {
+			return err
+		}
+
+		// the config file is the source of truth, flags are overrides.
+		// settings removed from the file revert to the defaults.
+		current, err := config.LoadInto(colima.DefaultConfig())
+		if errors.Is(err, config.ErrUnsupportedVersion) {
+			// proceeding would overwrite the config and lose settings
+			return cli.NewError(cli.ExitConfig, err, "upgrade colima with 'colima update'")
+		}
 		if err != nil {
 			// not fatal, will proceed with defaults
 			log.Warnln(fmt.Errorf("config load failed: %w", err))
 			log.Warnln("reverting to default settings")
 		}
 
-		// use default config
+		if startCmdArgs.file != "" && startCmdArgs.template != "" {
+			return fmt.Errorf("--file and --template cannot be used together")
+		}
+		// project-local config file for the profile, if any
+		if startCmdArgs.file == "" && startCmdArgs.template == "" {
+			startCmdArgs.file = projectFile()
+		}
+
+		// the config to save if the flags are not saved
+		startCmdArgs.unsaved = current
 		if current.Empty() {
-			return nil
+			startCmdArgs.unsaved = colima.DefaultConfig()
 		}
 
-		// runtime, ssh port, disk size, kubernetes version and arch are only effective on VM create
-		// set it to the current settings
-		startCmdArgs.Runtime = current.Runtime
-		startCmdArgs.VM.Disk = current.VM.Disk
-		startCmdArgs.VM.Arch = current.VM.Arch
-		startCmdArgs.Kubernetes.Version = current.Kubernetes.Version
+		switch {
+		// named template specified, flags take precedence
+		case startCmdArgs.template != "":
+			template, err := config.LoadNamedTemplate(startCmdArgs.template, colima.DefaultConfig())
+			if err != nil {
+				return err
+			}
+			log.Println("using template", startCmdArgs.template)
+			if current.Empty() {
+				startCmdArgs.unsaved = template
+			}
+			applyUnchanged(cmd, template)
+			if !current.Empty() {
+				colima.WarnCreateOnlyChanged(current, startCmdArgs.Config)
+				retainCreateOnly(current)
+			}
 
-		// use current settings for unchanged configs
-		// otherwise may be reverted to their default values.
-		if !cmd.Flag("with-kubernetes").Changed {
-			startCmdArgs.Kubernetes.Enabled = current.Kubernetes.Enabled
-		}
-		if !cmd.Flag("cpu").Changed {
-			startCmdArgs.VM.CPU = current.VM.CPU
+		// config file specified, flags take precedence
+		case startCmdArgs.file != "":
+			fileConf, err := config.LoadFile(startCmdArgs.file, colima.DefaultConfig())
+			if err != nil {
+				return err
+			}
+			log.Println("using config file", startCmdArgs.file)
+			if current.Empty() {
+				startCmdArgs.unsaved = fileConf
+			}
+			applyUnchanged(cmd, fileConf)
+			if !current.Empty() {
+				colima.WarnCreateOnlyChanged(current, startCmdArgs.Config)
+				retainCreateOnly(current)
+			}
+
+		// new instance, use the template (if any) for unchanged configs
+		case current.Empty():
+			template, err := config.LoadTemplate(colima.DefaultConfig())
+			if err != nil {
+				// not fatal, will proceed with defaults
+				log.Warnln(fmt.Errorf("template load failed: %w", err))
+			}
+			if !template.Empty() {
+				log.Println("using template", config.TemplateFile())
+				startCmdArgs.unsaved = template
+				applyUnchanged(cmd, template)
+			}
+
+		default:
+			// use current settings for unchanged configs
+			// otherwise may be reverted to their default values.
+			applyUnchanged(cmd, current)
+			retainCreateOnly(current)
+
+			log.Println("using", current.Runtime, "runtime")
 		}
-		if !cmd.Flag("memory").Changed {
-			startCmdArgs.VM.Memory = current.VM.Memory
+
+		// the flag applies to all components
+		if cmd.Flag("wait-timeout").Changed {
+			t := startCmdArgs.waitTimeout
+			startCmdArgs.WaitTimeout = config.WaitTimeout{Docker: t, Containerd: t, Kubernetes: t}
 		}
-		if !cmd.Flag("mount").Changed {
-			startCmdArgs.VM.Mounts = current.VM.Mounts
+
+		// the Kubernetes version of the channel for a new VM, unless set
+		if current.Empty() && !cmd.Flag("kubernetes-version").Changed && startCmdArgs.Kubernetes.Version == colima.DefaultKubernetesVersion {
+			startCmdArgs.Kubernetes.Version = kubernetes.ChannelVersion(startCmdArgs.Channel)
 		}
-		if !cmd.Flag("ssh-agent").Changed {
-			startCmdArgs.VM.ForwardAgent = current.VM.ForwardAgent
+
+		// relative to the current directory rather than of later starts
+		if cmd.Flag("cloud-init").Changed && startCmdArgs.VM.CloudInit != "" {
+			if startCmdArgs.VM.CloudInit, err = filepath.Abs(startCmdArgs.VM.CloudInit); err != nil {
+				return err
+			}
 		}
-		if !cmd.Flag("dns").Changed {
-			startCmdArgs.VM.DNS = current.VM.DNS
+
+		colima.AllocatePorts(&startCmdArgs.Config, current)
+		// the allocated ports are in use by the profile and saved regardless of the flags
+		startCmdArgs.unsaved.VM.SSHPort = startCmdArgs.VM.SSHPort
+		if startCmdArgs.unsaved.Kubernetes.Port == 0 {
+			startCmdArgs.unsaved.Kubernetes.Port = startCmdArgs.Kubernetes.Port
 		}
 
-		log.Println("using", current.Runtime, "runtime")
+		if startCmdArgs.edit {
+			if err := editConfig(current); err != nil {
+				return err
+			}
+			// edits are saved
+			startCmdArgs.unsaved = startCmdArgs.Config
+		}
 
 		// remaining settings do not survive VM reboots.
 		return nil
 	},
 	PostRunE: func(cmd *cobra.Command, args []string) error {
+		if startCmdArgs.dryRun || startCmdArgs.all {
+			return nil
+		}
+		if !startCmdArgs.save {
+			// settings only effective on VM create must be persisted
+			conf := startCmdArgs.unsaved
+			colima.SetCreateOnly(&conf, startCmdArgs.Config)
+			return config.Save(conf)
+		}
 		return config.Save(startCmdArgs.Config)
 	},
 }
 
-const (
-	defaultCPU               = 2
-	defaultMemory            = 2
-	defaultDisk              = 60
-	defaultKubernetesVersion = "v1.22.2"
-)
+// projectFile returns the project-local config file in the current directory or its parents,
+// if it defines the current profile.
+func projectFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	file := config.FindProjectFile(dir)
+	if file == "" {
+		return ""
+	}
+	if profile, err := config.ProjectProfile(file); err != nil || config.ProfileFromName(profile).ID != config.Profile().ID {
+		return ""
+	}
+	return file
+}
+
+// dryRun prints the resolved config and the generated VM config without starting.
+func dryRun(conf config.Config) error {
+	for _, err := range colima.Validate(conf) {
+		log.Warnln(err)
+	}
+
+	// as it would be saved
+	conf.Version = config.CurrentVersion
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	fmt.Println("# colima configuration")
+	fmt.Print(string(b))
+	if len(conf.VM.DNS) > 0 {
+		fmt.Println("# dns (not persisted):", conf.VM.DNS)
+	}
+	if len(conf.VM.Env) > 0 {
+		fmt.Println("# env (not persisted):", conf.VM.Env)
+	}
+
+	if conf.Remote != "" {
+		// no VM for a remote host
+		return nil
+	}
+	spec, err := lima.Spec(conf)
+	if err != nil {
+		return fmt.Errorf("error generating VM config: %w", err)
+	}
+	fmt.Println("---")
+	fmt.Println("# VM configuration")
+	fmt.Print(string(spec))
+	return nil
+}
+
+// retainCreateOnly resets the settings that are only effective on VM create to the current settings.
+func retainCreateOnly(current config.Config) {
+	colima.SetCreateOnly(&startCmdArgs.Config, current)
+}
+
+// editConfig opens the resolved config in $EDITOR and uses the modified config for startup.
+func editConfig(current config.Config) error {
+	if err := config.Save(startCmdArgs.Config); err != nil {
+		return fmt.Errorf("error saving config for editing: %w", err)
+	}
+	if err := cli.OpenEditor(config.File()); err != nil {
+		return fmt.Errorf("error opening editor: %w", err)
+	}
+
+	edited, err := config.LoadInto(colima.DefaultConfig())
+	if err != nil {
+		return fmt.Errorf("error loading edited config: %w", err)
+	}
+	// not persisted in the config file
+	edited.VM.DNS = startCmdArgs.VM.DNS
+	edited.VM.Env = startCmdArgs.VM.Env
+	startCmdArgs.Config = edited
+
+	if !current.Empty() {
+		colima.WarnCreateOnlyChanged(current, edited)
+		retainCreateOnly(current)
+	}
+	return nil
+}
+
+// applyUnchanged sets the values of conf for flags that are not explicitly set.
+// Every setting of conf is applied, settings only configurable in the config file included,
+// and the flags explicitly set take precedence.
+func applyUnchanged(cmd *cobra.Command, conf config.Config) {
+	flags := startCmdArgs.Config
+	startCmdArgs.Config = conf
+	for name, set := range configFlags {
+		if cmd.Flag(name).Changed {
+			set(&startCmdArgs.Config, flags)
+		}
+	}
+}
+
+// configFlags are the start flags of the settings of the config, and the settings they set.
+var configFlags = map[string]func(c *config.Config, flags config.Config){
+	"runtime":                   func(c *config.Config, f config.Config) { c.Runtime = f.Runtime },
+	"cpu":                       func(c *config.Config, f config.Config) { c.VM.CPU = f.VM.CPU },
+	"memory":                    func(c *config.Config, f config.Config) { c.VM.Memory = f.VM.Memory },
+	"disk":                      func(c *config.Config, f config.Config) { c.VM.Disk = f.VM.Disk },
+	"arch":                      func(c *config.Config, f config.Config) { c.VM.Arch = f.VM.Arch },
+	"mount":                     func(c *config.Config, f config.Config) { c.VM.Mounts = f.VM.Mounts },
+	"cloud-init":                func(c *config.Config, f config.Config) { c.VM.CloudInit = f.VM.CloudInit },
+	"cpu-affinity":              func(c *config.Config, f config.Config) { c.VM.CPUAffinity = f.VM.CPUAffinity },
+	"cgroup":                    func(c *config.Config, f config.Config) { c.VM.Cgroup = f.VM.Cgroup },
+	"timezone":                  func(c *config.Config, f config.Config) { c.VM.Timezone = f.VM.Timezone },
+	"ssh-agent":                 func(c *config.Config, f config.Config) { c.VM.ForwardAgent = f.VM.ForwardAgent },
+	"env":                       func(c *config.Config, f config.Config) { c.VM.Env = f.VM.Env },
+	"dns":                       func(c *config.Config, f config.Config) { c.VM.DNS = f.VM.DNS },
+	"with-kubernetes":           func(c *config.Config, f config.Config) { c.Kubernetes.Enabled = f.Kubernetes.Enabled },
+	"kubernetes-version":        func(c *config.Config, f config.Config) { c.Kubernetes.Version = f.Kubernetes.Version },
+	"kubernetes-metrics-server": func(c *config.Config, f config.Config) { c.Kubernetes.MetricsServer = f.Kubernetes.MetricsServer },
+	"kubernetes-airgap-path":    func(c *config.Config, f config.Config) { c.Kubernetes.AirgapPath = f.Kubernetes.AirgapPath },
+	"kubernetes-port":           func(c *config.Config, f config.Config) { c.Kubernetes.Port = f.Kubernetes.Port },
+	"kubernetes-tls-san":        func(c *config.Config, f config.Config) { c.Kubernetes.TLSSAN = f.Kubernetes.TLSSAN },
+	"kubernetes-join":           func(c *config.Config, f config.Config) { c.Kubernetes.Join = f.Kubernetes.Join },
+	"notify":                    func(c *config.Config, f config.Config) { c.Notify = f.Notify },
+	"idle-timeout":              func(c *config.Config, f config.Config) { c.Idle.Timeout = f.Idle.Timeout },
+	"channel":                   func(c *config.Config, f config.Config) { c.Channel = f.Channel },
+	"lazy-start":                func(c *config.Config, f config.Config) { c.LazyStart = f.LazyStart },
+	"remote":                    func(c *config.Config, f config.Config) { c.Remote = f.Remote },
+	"remote-install":            func(c *config.Config, f config.Config) { c.RemoteInstall = f.RemoteInstall },
+	"remote-manage":             func(c *config.Config, f config.Config) { c.RemoteManage = f.RemoteManage },
+	"docker-transport":          func(c *config.Config, f config.Config) { c.DockerTransport = f.DockerTransport },
+	"registry-mirror":           func(c *config.Config, f config.Config) { c.Registry.Mirrors = f.Registry.Mirrors },
+	"insecure-registry":         func(c *config.Config, f config.Config) { c.Registry.Insecure = f.Registry.Insecure },
+}
 
 var startCmdArgs struct {
 	config.Config
+	edit     bool
+	file     string
+	template string
+	dryRun   bool
+	save     bool
+	all      bool
+	parallel bool
+
+	profileStartup bool
+	waitTimeout    int
+
+	// unsaved is the config without the flags, saved when save is false.
+	unsaved config.Config
 }
 
 func init() {
 	runtimes := strings.Join(environment.ContainerRuntimes(), ", ")
-	defaultArch := string(environment.Arch(runtime.GOARCH).Value())
 
 	root.Cmd().AddCommand(startCmd)
+	startCmd.Flags().BoolVar(&startCmdArgs.edit, "edit", false, "edit the configuration file before starting")
+	startCmd.Flags().StringVarP(&startCmdArgs.template, "template", "t", "", "start with the configuration in the named template, flags take precedence")
+	startCmd.Flags().BoolVar(&startCmdArgs.all, "all", false, "start all profiles with their saved configuration")
+	startCmd.Flags().BoolVar(&startCmdArgs.parallel, "parallel", false, "start the profiles in parallel, requires --all")
+	startCmd.Flags().BoolVar(&startCmdArgs.save, "save", true, "save the flags to the config file, otherwise only applied for this startup")
+	startCmd.Flags().BoolVar(&startCmdArgs.dryRun, "dry-run", false, "print the resolved configuration without starting")
+	startCmd.Flags().BoolVar(&startCmdArgs.profileStartup, "profile-startup", false, "print how long each provisioning step and command took")
+	startCmd.Flags().BoolVar(&startCmdArgs.Notify, "notify", false, "post desktop notifications when started, failed or low on disk space")
+	startCmd.Flags().IntVar(&startCmdArgs.Idle.Timeout, "idle-timeout", 0, "minutes without activity before the VM is stopped, resumed on the next Docker socket connection (0 to disable)")
+	startCmd.Flags().StringVar(&startCmdArgs.Channel, "channel", "", "release channel of the components in the VM (stable, edge)")
+	startCmd.Flags().BoolVar(&startCmdArgs.LazyStart, "lazy-start", false, "keep the Docker socket listening when stopped, the profile starts on the next connection")
+	startCmd.Flags().StringVar(&startCmdArgs.Remote, "remote", "", "remote Linux machine to use over SSH instead of a VM, user@host[:port]")
+	startCmd.Flags().BoolVar(&startCmdArgs.RemoteInstall, "remote-install", false, "install docker on the remote machine with the Docker install script if missing")
+	startCmd.Flags().BoolVar(&startCmdArgs.RemoteManage, "remote-manage", false, "manage the docker daemon of the remote machine: its daemon.json, and stopping it with the profile")
+	startCmd.Flags().StringVar(&startCmdArgs.DockerTransport, "docker-transport", "", "transport of the docker context (unix, ssh)")
+	startCmd.Flags().IntVar(&startCmdArgs.waitTimeout, "wait-timeout", 0, "seconds to wait for the runtime and Kubernetes to become ready (default 60, 120 for Kubernetes)")
+	startCmd.Flags().StringVarP(&startCmdArgs.file, "file", "f", "", "start with the configuration in the file, flags take precedence")
 	startCmd.Flags().StringVarP(&startCmdArgs.Runtime, "runtime", "r", docker.Name, "container runtime ("+runtimes+")")
-	startCmd.Flags().IntVarP(&startCmdArgs.VM.CPU, "cpu", "c", defaultCPU, "number of CPUs")
-	startCmd.Flags().IntVarP(&startCmdArgs.VM.Memory, "memory", "m", defaultMemory, "memory in GiB")
-	startCmd.Flags().IntVarP(&startCmdArgs.VM.Disk, "disk", "d", defaultDisk, "disk size in GiB")
-	startCmd.Flags().StringVarP(&startCmdArgs.VM.Arch, "arch", "a", defaultArch, "architecture (aarch64, x86_64)")
+	startCmd.Flags().IntVarP(&startCmdArgs.VM.CPU, "cpu", "c", colima.DefaultCPU, "number of CPUs")
+	startCmd.Flags().IntVarP(&startCmdArgs.VM.Memory, "memory", "m", colima.DefaultMemory, "memory in GiB")
+	startCmd.Flags().IntVarP(&startCmdArgs.VM.Disk, "disk", "d", colima.DefaultDisk, "disk size in GiB")
+	startCmd.Flags().StringVarP(&startCmdArgs.VM.Arch, "arch", "a", colima.DefaultArch(), "architecture (aarch64, x86_64)")
 
 	// mounts
 	startCmd.Flags().StringSliceVarP(&startCmdArgs.VM.Mounts, "mount", "v", nil, "directories to mount, suffix ':w' for writable")
 
+	// cloud-init
+	startCmd.Flags().StringVar(&startCmdArgs.VM.CloudInit, "cloud-init", "", "cloud-init user-data file applied to the VM (bootcmd, write_files, packages, runcmd)")
+	startCmd.Flags().StringVar(&startCmdArgs.VM.CPUAffinity, "cpu-affinity", "", "host cores to pin the vCPUs to e.g. 0-3, 'efficiency' on macOS")
+	startCmd.Flags().StringVar(&startCmdArgs.VM.Cgroup, "cgroup", "", "cgroup version of the VM, v1 or v2 (default: VM image default)")
+	startCmd.Flags().StringVar(&startCmdArgs.VM.Timezone, "timezone", "", "timezone of the VM e.g. UTC, Europe/Berlin (default: host timezone)")
+
 	// ssh agent
 	startCmd.Flags().BoolVarP(&startCmdArgs.VM.ForwardAgent, "ssh-agent", "s", false, "forward SSH agent to the VM")
 
 	// k8s
 	startCmd.Flags().BoolVarP(&startCmdArgs.Kubernetes.Enabled, "with-kubernetes", "k", false, "start VM with Kubernetes")
-	startCmd.Flags().StringVar(&startCmdArgs.Kubernetes.Version, "kubernetes-version", defaultKubernetesVersion, "the Kubernetes version")
+	startCmd.Flags().StringVar(&startCmdArgs.Kubernetes.Version, "kubernetes-version", colima.DefaultKubernetesVersion, "the Kubernetes version")
+	startCmd.Flags().BoolVar(&startCmdArgs.Kubernetes.MetricsServer, "kubernetes-metrics-server", true, "enable metrics-server for 'kubectl top', changes require 'colima kubernetes reset'")
+	startCmd.Flags().StringVar(&startCmdArgs.Kubernetes.AirgapPath, "kubernetes-airgap-path", "", "directory with k3s release artifacts for offline install")
+	startCmd.Flags().IntVar(&startCmdArgs.Kubernetes.Port, "kubernetes-port", 0, "Kubernetes API server port on the host (default 6443)")
+	startCmd.Flags().StringSliceVar(&startCmdArgs.Kubernetes.TLSSAN, "kubernetes-tls-san", nil, "additional hostnames or IPs for the Kubernetes API server certificate")
+	startCmd.Flags().StringVar(&startCmdArgs.Kubernetes.Join, "kubernetes-join", "", "join the Kubernetes cluster of the profile as an agent node, implies --with-kubernetes")
 	// not so familiar with k3s versioning atm, hide for now.
 	_ = startCmd.Flags().MarkHidden("kubernetes-version")
 
@@ -122,4 +410,23 @@ func init() {
 	_ = startCmd.Flags().MarkHidden("env")
 
 	startCmd.Flags().IPSliceVarP(&startCmdArgs.VM.DNS, "dns", "n", nil, "DNS servers for the VM")
+
+	// registries
+	startCmd.Flags().StringSliceVar(&startCmdArgs.Registry.Mirrors, "registry-mirror", nil, "mirrors for the docker.io registry")
+	startCmd.Flags().StringSliceVar(&startCmdArgs.Registry.Insecure, "insecure-registry", nil, "registries to access without TLS verification")
+
+	// completions
+	_ = startCmd.RegisterFlagCompletionFunc("runtime", completeRuntimes)
+	_ = startCmd.RegisterFlagCompletionFunc("kubernetes-version", completeKubernetesVersions)
+	_ = startCmd.RegisterFlagCompletionFunc("kubernetes-join", completeProfiles)
+	_ = startCmd.RegisterFlagCompletionFunc("template", completeTemplates)
+	_ = startCmd.RegisterFlagCompletionFunc("arch", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(environment.AARCH64), string(environment.X8664)}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = startCmd.RegisterFlagCompletionFunc("channel", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{config.ChannelStable, config.ChannelEdge}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = startCmd.RegisterFlagCompletionFunc("docker-transport", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{config.DockerTransportUnix, config.DockerTransportSSH}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// startFlags are the start flags that are not settings of the config.
+var startFlags = map[string]bool{
+	"edit": true, "template": true, "all": true, "parallel": true, "save": true, "dry-run": true,
+	"profile-startup": true, "wait-timeout": true, "file": true,
+}
+
+func Test_configFlags(t *testing.T) {
+	startCmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if _, ok := configFlags[f.Name]; !ok && !startFlags[f.Name] {
+			t.Errorf("flag '%s' is missing in configFlags", f.Name)
+		}
+	})
+	for name := range configFlags {
+		if startCmd.Flags().Lookup(name) == nil {
+			t.Errorf("configFlags has '%s' that is not a start flag", name)
+		}
+	}
+}
+
+func Test_applyUnchanged(t *testing.T) {
+	conf := config.Config{
+		Extends:   "team",
+		LogFormat: "json",
+		Remote:    "user@host",
+		Runtime:   "containerd",
+		VM:        config.VM{CPU: 4, Memory: 8, ShutdownTimeout: 10},
+		Packages:  []string{"htop"},
+	}
+	flags := config.Config{
+		Remote:  "other@host",
+		Runtime: "docker",
+		VM:      config.VM{CPU: 2},
+	}
+
+	tests := []struct {
+		changed []string
+		want    func() config.Config
+	}{
+		{changed: nil, want: func() config.Config { return conf }},
+		{changed: []string{"cpu"}, want: func() config.Config { c := conf; c.VM.CPU = 2; return c }},
+		{changed: []string{"remote", "runtime"}, want: func() config.Config {
+			c := conf
+			c.Remote = "other@host"
+			c.Runtime = "docker"
+			return c
+		}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			cmd := &cobra.Command{}
+			for name := range configFlags {
+				cmd.Flags().String(name, "", "")
+			}
+			for _, name := range tt.changed {
+				if err := cmd.Flags().Set(name, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			startCmdArgs.Config = flags
+			applyUnchanged(cmd, conf)
+			if got := startCmdArgs.Config; !reflect.DeepEqual(got, tt.want()) {
+				t.Errorf("applyUnchanged() = %+v, want %+v", got, tt.want())
+			}
+		})
+	}
+}
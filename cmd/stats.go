@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+var statsCmdArgs struct {
+	watch    bool
+	interval time.Duration
+	json     bool
+}
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats [profile]",
+	Short: "show the resource usage of the VM",
+	Long: `Show the CPU, memory, disk and network usage of the VM.
+
+The usage helps to right-size the --cpu, --memory and --disk settings of the profile.
+The disk usage is of the disk holding the container runtime data, and the network
+usage is the total since the VM started.`,
+	Example: "  colima stats\n" +
+		"  colima stats --watch\n" +
+		"  colima stats --json",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		colimaApp := newApp()
+		out := cmd.OutOrStdout()
+
+		if statsCmdArgs.json {
+			encoder := json.NewEncoder(out)
+			return watchStats(colimaApp, func(s app.Stats) error { return encoder.Encode(s) })
+		}
+
+		// fixed widths to align the rows printed while watching
+		fmt.Fprintf(out, statsFormat, "CPU", "MEMORY", "DISK", "NET RX", "NET TX")
+		return watchStats(colimaApp, func(s app.Stats) error {
+			printStats(out, s)
+			return nil
+		})
+	},
+}
+
+// watchStats calls print with the stats, repeatedly at the interval if watching.
+func watchStats(colimaApp app.App, print func(app.Stats) error) error {
+	for {
+		stats, err := colimaApp.Stats()
+		if err != nil {
+			return err
+		}
+		if err := print(stats); err != nil {
+			return err
+		}
+		if !statsCmdArgs.watch {
+			return nil
+		}
+		time.Sleep(statsCmdArgs.interval)
+	}
+}
+
+const statsFormat = "%-20s %-28s %-28s %-10s %s\n"
+
+func printStats(w io.Writer, s app.Stats) {
+	percent := func(used, total int64) string {
+		if total == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", 100*float64(used)/float64(total))
+	}
+	fmt.Fprintf(w, statsFormat,
+		fmt.Sprintf("%.1f%% of %d CPUs", s.CPU, s.CPUs),
+		fmt.Sprintf("%s / %s (%s)", units.BytesSize(float64(s.MemoryUsed)), units.BytesSize(float64(s.MemoryTotal)), percent(s.MemoryUsed, s.MemoryTotal)),
+		fmt.Sprintf("%s / %s (%s)", units.BytesSize(float64(s.DiskUsed)), units.BytesSize(float64(s.DiskTotal)), percent(s.DiskUsed, s.DiskTotal)),
+		units.HumanSize(float64(s.NetworkRx)), units.HumanSize(float64(s.NetworkTx)),
+	)
+}
+
+func init() {
+	root.Cmd().AddCommand(statsCmd)
+
+	statsCmd.Flags().BoolVarP(&statsCmdArgs.watch, "watch", "w", false, "continuously print the usage")
+	statsCmd.Flags().DurationVar(&statsCmdArgs.interval, "interval", 2*time.Second, "interval between updates with --watch")
+	statsCmd.Flags().BoolVarP(&statsCmdArgs.json, "json", "j", false, "print json output, one object per line")
+}
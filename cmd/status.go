@@ -1,21 +1,92 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// exit codes for the status command
+const (
+	statusExitStopped  = 2
+	statusExitNotFound = 3
+	statusExitBroken   = 4
 )
 
+var statusCmdArgs struct {
+	json bool
+	yaml bool
+}
+
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
 	Use:   "status [profile]",
 	Short: "show the status of Colima",
-	Long:  `Show the status of Colima`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Show the status of Colima.
+
+The exit code is 0 if running, 2 if stopped, 3 if the profile does not exist
+and 4 if running but in a broken state.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return newApp().Status()
+		if statusCmdArgs.json && statusCmdArgs.yaml {
+			return fmt.Errorf("--json and --yaml cannot be used together")
+		}
+
+		colimaApp := newApp()
+		if !statusCmdArgs.json && !statusCmdArgs.yaml {
+			return statusExitError(colimaApp.Status())
+		}
+
+		status, err := colimaApp.StatusInfo()
+		if err != nil {
+			return statusExitError(err)
+		}
+
+		if statusCmdArgs.json {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(status)
+		} else {
+			err = yaml.NewEncoder(cmd.OutOrStdout()).Encode(status)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !status.Created:
+			return statusExitError(fmt.Errorf("%s %w", config.Profile().DisplayName, app.ErrNotCreated))
+		case !status.Running:
+			return statusExitError(fmt.Errorf("%s %w", config.Profile().DisplayName, app.ErrNotRunning))
+		}
+		return nil
 	},
 }
 
+// statusExitError maps the status error to the exit code.
+func statusExitError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, app.ErrNotCreated):
+		return cli.ExitError{Code: statusExitNotFound, Err: err}
+	case errors.Is(err, app.ErrNotRunning):
+		return cli.ExitError{Code: statusExitStopped, Err: err}
+	}
+	return cli.ExitError{Code: statusExitBroken, Err: err}
+}
+
 func init() {
 	root.Cmd().AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolVarP(&statusCmdArgs.json, "json", "j", false, "print json output")
+	statusCmd.Flags().BoolVar(&statusCmdArgs.yaml, "yaml", false, "print yaml output")
 }
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"github.com/abiosoft/colima/app"
 	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var stopCmdArgs struct {
-	force bool
+	force    bool
+	all      bool
+	parallel bool
 }
 
 // stopCmd represents the stop command
@@ -16,15 +21,54 @@ var stopCmd = &cobra.Command{
 	Long: `Stop stops Colima to free up resources.
 
 The state of the VM is persisted at stop. A start afterwards
-should return it back to its previous state.`,
-	Args: cobra.MaximumNArgs(1),
+should return it back to its previous state.
+
+Running containers are given 'vm.shutdown_timeout' seconds (default 30) in the config
+to stop gracefully. The VM is forcefully stopped if a graceful shutdown does not complete
+within the timeout.
+
+With 'lazy_start', the Docker socket on the host keeps listening after the stop
+and the next connection starts the profile again.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return newApp().Stop(stopCmdArgs.force)
+		if stopCmdArgs.all {
+			if err := checkAllFlags(cmd, args, "force", "parallel"); err != nil {
+				return err
+			}
+			runArgs := []string{"stop"}
+			if stopCmdArgs.force {
+				runArgs = append(runArgs, "--force")
+			}
+			return runAll(stopCmdArgs.parallel, runArgs...)
+		}
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		if err := newApp().Stop(stopCmdArgs.force); err != nil {
+			return err
+		}
+		return startLazy()
 	},
 }
 
+// startLazy starts the monitor of the stopped profile to start it on the next connection, if lazy_start is set.
+func startLazy() error {
+	conf, err := config.Load()
+	if err != nil || !conf.LazyStart {
+		return err
+	}
+	if err := app.RestartMonitor(conf); err != nil {
+		return err
+	}
+	log.Println("the Docker socket keeps listening, the next connection starts", config.Profile().DisplayName)
+	return nil
+}
+
 func init() {
 	root.Cmd().AddCommand(stopCmd)
 
 	stopCmd.Flags().BoolVarP(&stopCmdArgs.force, "force", "f", false, "stop without graceful shutdown")
+	stopCmd.Flags().BoolVar(&stopCmdArgs.all, "all", false, "stop all profiles")
+	stopCmd.Flags().BoolVar(&stopCmdArgs.parallel, "parallel", false, "stop the profiles in parallel, requires --all")
 }
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/pkg/colima"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// templateCmd represents the template command
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"tmpl", "tpl", "t"},
+	Short:   "edit the template for default configurations",
+	Long: `Edit the template for default configurations of new instances.
+
+The template is opened in $EDITOR. New instances are created with the values in the
+template, flags passed to 'colima start' take precedence.
+
+Existing instances are not affected.
+
+Named templates, built-in or saved in the templates directory as <name>.yaml,
+can be managed with the subcommands and used with 'colima start --template'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := config.TemplateFile()
+
+		// create the template with the defaults if it does not exist
+		if _, err := os.Stat(file); err != nil {
+			if err := config.SaveFile(colima.DefaultConfig(), file); err != nil {
+				return fmt.Errorf("error creating template: %w", err)
+			}
+		}
+
+		if err := cli.OpenEditor(file); err != nil {
+			return fmt.Errorf("error opening editor: %w", err)
+		}
+
+		// validate the template
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading template: %w", err)
+		}
+		var c config.Config
+		if err := yaml.Unmarshal(b, &c); err != nil {
+			return fmt.Errorf("invalid template, edit with 'colima template': %w", err)
+		}
+
+		fmt.Println("template saved at", file)
+		return nil
+	},
+}
+
+// templateListCmd represents the template list command
+var templateListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "list templates",
+	Long: `List the built-in and user-defined templates.
+
+User-defined templates are saved in the templates directory as <name>.yaml.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := config.Templates()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE")
+		for _, t := range templates {
+			kind := "user"
+			if t.BuiltIn {
+				kind = "built-in"
+			}
+			fmt.Fprintf(w, "%s\t%s\n", t.Name, kind)
+		}
+		return w.Flush()
+	},
+}
+
+// templateShowCmd represents the template show command
+var templateShowCmd = &cobra.Command{
+	Use:               "show <name>",
+	Short:             "print a template",
+	Long:              `Print the content of a template.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := config.ReadTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+		return nil
+	},
+}
+
+// templateApplyCmd represents the template apply command
+var templateApplyCmd = &cobra.Command{
+	Use:   "apply <name>",
+	Short: "apply a template to the configuration",
+	Long: `Apply a template to the saved configuration of the profile.
+
+Values in the template replace the saved values, changes take effect on the next 'colima start'.`,
+	Example: "  colima template apply kubernetes-dev\n" +
+		"  colima template apply minimal --profile dev",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := lockProfile(); err != nil {
+			return err
+		}
+		current, err := config.LoadInto(colima.DefaultConfig())
+		if err != nil {
+			return err
+		}
+		base := current
+		if base.Empty() {
+			base = colima.DefaultConfig()
+		}
+
+		conf, err := config.LoadNamedTemplate(args[0], base)
+		if err != nil {
+			return err
+		}
+		if errs := colima.Validate(conf); len(errs) > 0 {
+			return errs[0]
+		}
+		if !current.Empty() && (conf.Runtime != current.Runtime || conf.VM.Disk != current.VM.Disk || conf.VM.Arch != current.VM.Arch) {
+			log.Warnln("runtime, disk and arch are only effective on VM create, delete the VM for the change to take effect")
+		}
+
+		if err := config.Save(conf); err != nil {
+			return fmt.Errorf("error saving config: %w", err)
+		}
+		fmt.Println("template", args[0], "applied to", config.Profile().DisplayName)
+		return nil
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateApplyCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+var topCmdArgs struct {
+	sort string
+	json bool
+}
+
+// topSorts are the sort orders of the top command.
+var topSorts = map[string]func(a, b app.ContainerStats) bool{
+	"cpu":    func(a, b app.ContainerStats) bool { return a.CPU > b.CPU },
+	"memory": func(a, b app.ContainerStats) bool { return a.Memory > b.Memory },
+	"name":   func(a, b app.ContainerStats) bool { return a.Name < b.Name },
+}
+
+// topCmd represents the top command
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "show the resource usage of the containers",
+	Long: `Show the CPU and memory usage of the containers of the container runtime.
+
+The containers of all containerd namespaces are included i.e. Kubernetes pods as well.
+With docker, the namespace of the containers of Kubernetes pods is shown.`,
+	Example: "  colima top\n" +
+		"  colima top --sort memory\n" +
+		"  colima top --json",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		less, ok := topSorts[topCmdArgs.sort]
+		if !ok {
+			return fmt.Errorf("invalid sort '%s', valid values are cpu, memory, name", topCmdArgs.sort)
+		}
+
+		stats, err := newApp().Top()
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(stats, func(i, j int) bool { return less(stats[i], stats[j]) })
+
+		if topCmdArgs.json {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if stats == nil {
+				stats = []app.ContainerStats{}
+			}
+			return encoder.Encode(stats)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tID\tCPU\tMEMORY")
+		for _, s := range stats {
+			namespace := s.Namespace
+			if namespace == "" {
+				namespace = "-"
+			}
+			id := s.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.2f%%\t%s (%.2f%%)\n",
+				namespace, s.Name, id, s.CPU, units.BytesSize(float64(s.Memory)), s.MemoryPercent)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	root.Cmd().AddCommand(topCmd)
+
+	topCmd.Flags().StringVarP(&topCmdArgs.sort, "sort", "s", "cpu", "sort by cpu, memory or name")
+	topCmd.Flags().BoolVarP(&topCmdArgs.json, "json", "j", false, "print json output")
+
+	_ = topCmd.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"cpu", "memory", "name"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
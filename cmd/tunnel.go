@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// tunnelCmd represents the tunnel command
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel",
+	Short: "manage tunnels into the VM",
+	Long: `Manage tunnels from the host into addresses reachable from the VM,
+e.g. container, service or pod addresses of the container runtime and Kubernetes.
+
+The tunnels are saved in the configuration and kept open by the monitor while the profile is running,
+they are reopened if the connection is lost.`,
+}
+
+var tunnelAddCmdArgs struct {
+	local string
+}
+
+// tunnelAddCmd represents the tunnel add command
+var tunnelAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "add a tunnel",
+	Long: `Add a tunnel, in the ssh -L form [<bind address>:]<port>:<host>:<host port>.
+
+The local port is bound to 127.0.0.1 if no bind address is specified.`,
+	Example: "  colima tunnel add -L 5432:10.43.0.15:5432\n" +
+		"  colima tunnel add -L 0.0.0.0:8080:172.17.0.2:80",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := config.ParseTunnel(tunnelAddCmdArgs.local); err != nil {
+			return err
+		}
+		return updateTunnels(func(tunnels []string) ([]string, error) {
+			for _, t := range tunnels {
+				if t == tunnelAddCmdArgs.local {
+					return nil, fmt.Errorf("tunnel '%s' already exists", t)
+				}
+			}
+			return append(tunnels, tunnelAddCmdArgs.local), nil
+		})
+	},
+}
+
+// tunnelRemoveCmd represents the tunnel remove command
+var tunnelRemoveCmd = &cobra.Command{
+	Use:     "remove <tunnel>",
+	Aliases: []string{"rm"},
+	Short:   "remove a tunnel",
+	Long:    `Remove a tunnel, as printed by 'colima tunnel list'.`,
+	Example: "  colima tunnel remove 5432:10.43.0.15:5432",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateTunnels(func(tunnels []string) ([]string, error) {
+			var remaining []string
+			for _, t := range tunnels {
+				if t != args[0] {
+					remaining = append(remaining, t)
+				}
+			}
+			if len(remaining) == len(tunnels) {
+				return nil, fmt.Errorf("tunnel '%s' not found", args[0])
+			}
+			return remaining, nil
+		})
+	},
+}
+
+var tunnelListCmdArgs struct {
+	json bool
+}
+
+// tunnelStatus is the status of a tunnel printed by the tunnel list command.
+type tunnelStatus struct {
+	Tunnel string `json:"tunnel"`
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+	Active bool   `json:"active"`
+}
+
+// tunnelListCmd represents the tunnel list command
+var tunnelListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "list the tunnels",
+	Long:    `List the tunnels, a tunnel is active if its local port is accepting connections.`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		statuses := []tunnelStatus{}
+		for _, spec := range conf.Tunnels {
+			t, err := config.ParseTunnel(spec)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, tunnelStatus{Tunnel: spec, Local: t.Local, Remote: t.Remote, Active: app.TunnelActive(t)})
+		}
+
+		if tunnelListCmdArgs.json {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(statuses)
+		}
+
+		if len(statuses) == 0 {
+			logrus.Warn("No tunnel found. Add one with `colima tunnel add -L <port>:<host>:<host port>`.")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "TUNNEL\tLOCAL\tREMOTE\tSTATUS")
+		for _, s := range statuses {
+			status := "inactive"
+			if s.Active {
+				status = "active"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Tunnel, s.Local, s.Remote, status)
+		}
+		return w.Flush()
+	},
+}
+
+// updateTunnels saves the tunnels returned by update and applies them if the profile is running.
+func updateTunnels(update func([]string) ([]string, error)) error {
+	if err := lockProfile(); err != nil {
+		return err
+	}
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if conf.Empty() {
+		return fmt.Errorf("%s has no configuration, start with 'colima start'", config.Profile().DisplayName)
+	}
+
+	if conf.Tunnels, err = update(conf.Tunnels); err != nil {
+		return err
+	}
+	if err := config.Save(conf); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+
+	if !newApp().Active() {
+		logrus.Println("the tunnels are opened on the next start")
+		return nil
+	}
+	return app.RestartMonitor(conf)
+}
+
+func init() {
+	root.Cmd().AddCommand(tunnelCmd)
+	tunnelCmd.AddCommand(tunnelAddCmd)
+	tunnelCmd.AddCommand(tunnelRemoveCmd)
+	tunnelCmd.AddCommand(tunnelListCmd)
+
+	tunnelAddCmd.Flags().StringVarP(&tunnelAddCmdArgs.local, "local", "L", "", "the tunnel, [<bind address>:]<port>:<host>:<host port>")
+	_ = tunnelAddCmd.MarkFlagRequired("local")
+	tunnelListCmd.Flags().BoolVar(&tunnelListCmdArgs.json, "json", false, "print the tunnels as JSON")
+}
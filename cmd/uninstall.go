@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/host"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/environment/vm/lima/network"
+	"github.com/abiosoft/colima/util/autostart"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var uninstallCmdArgs struct {
+	force bool
+}
+
+// uninstallCmd represents the uninstall command
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "remove everything created by Colima",
+	Long: `Remove everything created by Colima on the host.
+
+All profiles are deleted alongside their VMs, configs and caches. The configuration
+templates, docker contexts, Kubernetes config entries, launchd and autostart files
+and the networking files installed as root are removed as well.
+
+The colima binary itself is not removed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifacts := uninstallArtifacts()
+		if artifacts.empty() {
+			log.Println("nothing to uninstall")
+			return nil
+		}
+
+		if !uninstallCmdArgs.force {
+			if ok, err := confirmDestructive("all profiles", artifacts.summary()); !ok {
+				return err
+			}
+		}
+
+		artifacts.remove()
+
+		if executable, err := os.Executable(); err == nil {
+			log.Println("done, the colima binary at", executable, "can now be removed")
+		}
+		return nil
+	},
+}
+
+// artifacts are the things created by colima on the host.
+type artifacts struct {
+	profiles       []string
+	instances      []string
+	dockerContexts []string
+	kubeContexts   []string
+	launchdFiles   []string
+	autostartFiles []string
+	dirs           []string
+	rootfulFiles   []string
+}
+
+func uninstallArtifacts() (a artifacts) {
+	h := host.New()
+
+	a.profiles, _ = config.Profiles()
+
+	// instances without a config are not deleted by 'colima delete'
+	if _, err := exec.LookPath("limactl"); err == nil {
+		instances, _ := lima.Instances()
+		for _, i := range instances {
+			if !contains(a.profiles, i.Name) {
+				a.instances = append(a.instances, config.ProfileFromName(i.Name).ID)
+			}
+		}
+	}
+
+	if _, err := exec.LookPath("docker"); err == nil {
+		out, _ := h.RunOutput("docker", "context", "ls", "-q")
+		a.dockerContexts = colimaNames(out)
+	}
+	if _, err := exec.LookPath("kubectl"); err == nil {
+		out, _ := h.RunOutput("kubectl", "config", "get-contexts", "-o", "name")
+		a.kubeContexts = colimaNames(out)
+	}
+
+	a.launchdFiles, _ = network.LaunchdFiles()
+	a.autostartFiles, _ = autostart.Files()
+	a.dirs, _ = config.HostDirs()
+	for _, f := range network.RootfulFiles() {
+		if _, err := os.Lstat(f); err == nil {
+			a.rootfulFiles = append(a.rootfulFiles, f)
+		}
+	}
+	return a
+}
+
+// colimaNames returns the names in the newline separated output that belong to colima.
+func colimaNames(out string) (names []string) {
+	for _, name := range strings.Fields(out) {
+		if name == config.AppName || strings.HasPrefix(name, config.AppName+"-") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (a artifacts) empty() bool {
+	return len(a.summary()) == 0
+}
+
+func (a artifacts) summary() (summary []string) {
+	add := func(description string, items []string) {
+		if len(items) > 0 {
+			summary = append(summary, description+": "+strings.Join(items, ", "))
+		}
+	}
+	add("profiles and their VMs", a.profiles)
+	add("Lima instances", a.instances)
+	add("docker contexts", a.dockerContexts)
+	add("Kubernetes config entries", a.kubeContexts)
+	add("launchd files", a.launchdFiles)
+	add("autostart files", a.autostartFiles)
+	add("directories", a.dirs)
+	add("files installed as root (requires sudo)", a.rootfulFiles)
+	return summary
+}
+
+// remove removes the artifacts, failures are not fatal.
+func (a artifacts) remove() {
+	h := host.New()
+	warn := func(err error) {
+		if err != nil {
+			log.Warnln(err)
+		}
+	}
+
+	// before the deletion, the profiles started by them are stopped on unload
+	for _, f := range a.autostartFiles {
+		log.Println("removing", f)
+		warn(autostart.Unload(f))
+	}
+	if len(a.profiles) > 0 {
+		warn(runAll(false, "delete", "--force"))
+	}
+	for _, i := range a.instances {
+		log.Println("deleting Lima instance", i)
+		warn(h.RunQuiet("limactl", "delete", "--force", i))
+	}
+	for _, c := range a.dockerContexts {
+		// may have been removed by the profile deletion
+		if h.RunQuiet("docker", "context", "inspect", c) != nil {
+			continue
+		}
+		log.Println("removing docker context", c)
+		warn(h.RunQuiet("docker", "context", "rm", "--force", c))
+	}
+	for _, c := range a.kubeContexts {
+		if h.RunQuiet("kubectl", "config", "get-contexts", c) != nil {
+			continue
+		}
+		log.Println("removing Kubernetes config entries for", c)
+		for _, entry := range []string{"users.", "contexts.", "clusters."} {
+			warn(h.RunQuiet("kubectl", "config", "unset", entry+c))
+		}
+	}
+	for _, f := range a.launchdFiles {
+		log.Println("removing", f)
+		_ = h.RunQuiet("launchctl", "unload", f)
+		warn(os.Remove(f))
+	}
+	for _, d := range a.dirs {
+		log.Println("removing", d)
+		warn(os.RemoveAll(d))
+	}
+	if len(a.rootfulFiles) > 0 {
+		log.Println("removing", strings.Join(a.rootfulFiles, ", "))
+		args := append([]string{"rm", "-rf"}, a.rootfulFiles...)
+		if err := cli.CommandInteractive("sudo", args...).Run(); err != nil {
+			warn(fmt.Errorf("error removing files installed as root: %w", err))
+		}
+	}
+}
+
+func init() {
+	root.Cmd().AddCommand(uninstallCmd)
+
+	uninstallCmd.Flags().BoolVarP(&uninstallCmdArgs.force, "force", "f", false, "do not prompt for yes/no")
+	uninstallCmd.Flags().BoolVarP(&uninstallCmdArgs.force, "yes", "y", false, "alias for --force")
+}
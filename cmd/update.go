@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/util/release"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var updateCmdArgs struct {
+	check bool
+}
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "update Colima to the latest version",
+	Long: `Update Colima to the latest version.
+
+The latest release is downloaded from GitHub and verified against the checksum
+published with it before replacing the current binary. The checksum detects corrupted
+downloads, it is served by GitHub as well and does not authenticate the release.
+Installations managed by Homebrew are updated with 'brew upgrade colima' instead.
+
+Set COLIMA_UPDATE_NOTICE=1 to be notified of new versions, checked at most once a day.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		current := config.AppVersion().Version
+
+		latest, err := release.Latest()
+		if err != nil {
+			return err
+		}
+		if !release.Newer(current, latest) {
+			if !release.Valid(current) {
+				return fmt.Errorf("version '%s' is not a release, the latest release is %s", current, latest)
+			}
+			fmt.Println("colima is up to date, version", current)
+			return nil
+		}
+		if updateCmdArgs.check {
+			fmt.Println("a new version is available:", latest, "(current", current+")")
+			return nil
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("error retrieving colima executable: %w", err)
+		}
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return fmt.Errorf("error retrieving colima executable: %w", err)
+		}
+
+		if brewManaged(executable) {
+			log.Println("colima is installed with Homebrew, updating with brew")
+			return cli.CommandInteractive("brew", "upgrade", "colima").Run()
+		}
+
+		log.Println("updating colima from", current, "to", latest)
+
+		// download alongside the binary for an atomic replacement
+		tmp := filepath.Join(filepath.Dir(executable), ".colima-update")
+		defer func() { _ = os.Remove(tmp) }()
+		if err := release.Download(latest, tmp); err != nil {
+			if os.IsPermission(err) {
+				return fmt.Errorf("cannot write to %s, retry with sudo: %w", filepath.Dir(executable), err)
+			}
+			return err
+		}
+		if err := os.Rename(tmp, executable); err != nil {
+			return fmt.Errorf("error replacing %s: %w", executable, err)
+		}
+
+		log.Println("done")
+		return nil
+	},
+}
+
+// brewManaged returns if the executable is installed with Homebrew.
+func brewManaged(executable string) bool {
+	return strings.Contains(executable, string(filepath.Separator)+"Cellar"+string(filepath.Separator))
+}
+
+func init() {
+	root.Cmd().AddCommand(updateCmd)
+
+	updateCmd.Flags().BoolVar(&updateCmdArgs.check, "check", false, "only check for a new version")
+}
@@ -1,14 +1,50 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
 	"github.com/sirupsen/logrus"
 )
 
 func newApp() app.App {
 	colimaApp, err := app.New()
 	if err != nil {
-		logrus.Fatal("Error: ", err)
+		root.Fatal(err)
 	}
 	return colimaApp
 }
+
+// lockProfile locks the current profile for the lifetime of the process,
+// waiting for other colima processes operating on the profile.
+func lockProfile() error {
+	unlock, err := config.Lock(func() {
+		logrus.Println("waiting for another colima process operating on " + config.Profile().DisplayName)
+	})
+	if err != nil {
+		return err
+	}
+	// the lock is released if the lock file is garbage collected
+	profileUnlock = unlock
+	return nil
+}
+
+// profileUnlock releases the lock of the current profile, it is referenced until the process exits.
+var profileUnlock func()
+
+// confirmDestructive prompts for confirmation of a destructive operation on target,
+// listing what is lost in summary.
+// Commands calling it should provide --force to skip the prompt, it is required in CI mode.
+func confirmDestructive(target string, summary []string) (bool, error) {
+	fmt.Println("the following will be permanently deleted for " + target + ":")
+	for _, s := range summary {
+		fmt.Println("  - " + s)
+	}
+	if cli.Settings.CI {
+		return false, fmt.Errorf("confirmation required, pass --force: %w", cli.ErrNonInteractive)
+	}
+	return cli.Prompt("are you sure you want to continue"), nil
+}
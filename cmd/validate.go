@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/cmd/root"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/pkg/colima"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [profile]",
+	Short: "validate the configuration",
+	Long: `Validate the configuration of the profile, or of the file if --file is passed.
+
+The configuration is checked for unknown keys, invalid values, host capacity
+and conflicting settings. All the problems found are reported.`,
+	Example: "  colima validate\n" +
+		"  colima validate myprofile\n" +
+		"  colima validate -f ./colima.yaml",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := validateCmdArgs.file
+		if file == "" {
+			file = config.File()
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("%s has no configuration, start with 'colima start'", config.Profile().DisplayName)
+			}
+		}
+
+		var errs []error
+		if err := config.CheckFile(file); err != nil {
+			errs = append(errs, err)
+		}
+		conf, err := config.LoadFile(file, colima.DefaultConfig())
+		if err != nil {
+			return err
+		}
+		errs = append(errs, colima.Validate(conf)...)
+
+		if len(errs) == 0 {
+			fmt.Println(file, "is valid")
+			return nil
+		}
+
+		for _, err := range errs {
+			log.Errorln(err)
+		}
+		return cli.NewError(cli.ExitConfig, fmt.Errorf("%s is invalid, %d problem(s) found", file, len(errs)),
+			"fix the configuration with 'colima start --edit'")
+	},
+}
+
+var validateCmdArgs struct {
+	file string
+}
+
+func init() {
+	root.Cmd().AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(&validateCmdArgs.file, "file", "f", "", "the configuration file to validate")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/abiosoft/colima/app"
@@ -9,13 +10,35 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var versionCmdArgs struct {
+	json bool
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version [profile]",
 	Short: "print the version of Colima",
-	Long:  `Print the version of Colima`,
-	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Print the version of Colima.
+
+With --json, the versions of Lima and of the components in the VM are included
+if it is running: the kernel, the container runtimes, nerdctl, buildkit and k3s.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if versionCmdArgs.json {
+			colimaApp, err := app.New()
+			if err != nil {
+				return err
+			}
+			info, err := colimaApp.VersionInfo()
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+
 		version := config.AppVersion()
 		fmt.Println(config.AppName, "version", version.Version)
 		fmt.Println("git commit:", version.Revision)
@@ -23,9 +46,12 @@ var versionCmd = &cobra.Command{
 		if colimaApp, err := app.New(); err == nil {
 			_ = colimaApp.Version()
 		}
+		return nil
 	},
 }
 
 func init() {
 	root.Cmd().AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVarP(&versionCmdArgs.json, "json", "j", false, "print json output")
 }
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/abiosoft/colima/util/yamlutil"
 	"gopkg.in/yaml.v3"
@@ -20,16 +25,36 @@ var profile = ProfileInfo{ID: AppName, DisplayName: AppName, ShortName: AppName}
 // This is an avenue to test Colima without breaking an existing stable setup.
 // Not perfect, but good enough for testing.
 func SetProfile(profileName string) {
+	profile = ProfileFromName(profileName)
+}
+
+// ProfileFromName returns the profile info for profileName
+// without changing the current application profile.
+func ProfileFromName(profileName string) ProfileInfo {
 	switch profileName {
 	case "", AppName, "default":
-		return
+		return ProfileInfo{ID: AppName, DisplayName: AppName, ShortName: AppName}
 	}
 
 	// if custom profile is specified,
 	// use a prefix to prevent possible name clashes
-	profile.ID = "colima-" + profileName
-	profile.DisplayName = "colima [profile=" + profileName + "]"
-	profile.ShortName = profileName
+	return ProfileInfo{
+		ID:          "colima-" + profileName,
+		DisplayName: "colima [profile=" + profileName + "]",
+		ShortName:   profileName,
+	}
+}
+
+type ctxKey struct{}
+
+// CtxKey returns the context key for config.
+func CtxKey() interface{} { return ctxKey{} }
+
+// FromContext returns the config in ctx.
+// An empty config is returned if none is set.
+func FromContext(ctx context.Context) Config {
+	c, _ := ctx.Value(ctxKey{}).(Config)
+	return c
 }
 
 // Profile returns the current application profile.
@@ -57,113 +82,771 @@ var (
 
 // requiredDir is a directory that must exist on the filesystem
 type requiredDir struct {
-	once sync.Once
+	mu sync.Mutex
+	// created are the directories created, the directory changes with the profile.
+	created map[string]bool
 	// dir is a func to enable deferring the value of the directory
 	// until execution time.
-	// if dir() returns an error, a fatal error is triggered.
 	dir func() (string, error)
 }
 
+// ensure returns the directory path, the directory is created on the filesystem if missing.
+func (r *requiredDir) ensure() (string, error) {
+	dir, err := r.dir()
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch required directory: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.created[dir] {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return dir, fmt.Errorf("cannot make required directory: %w", err)
+		}
+		if r.created == nil {
+			r.created = map[string]bool{}
+		}
+		r.created[dir] = true
+	}
+
+	return dir, nil
+}
+
 // Dir returns the directory path.
 // It ensures the directory is created on the filesystem by calling
 // `mkdir` prior to returning the directory path.
+// Errors are logged, the operations on the directory fail. EnsureDirs returns them.
 func (r *requiredDir) Dir() string {
-	dir, err := r.dir()
+	dir, err := r.ensure()
 	if err != nil {
-		log.Fatal(fmt.Errorf("cannot fetch required directory: %w", err))
+		log.Println(err)
 	}
+	return dir
+}
 
-	r.once.Do(func() {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			log.Fatal(fmt.Errorf("cannot make required directory: %w", err))
+// EnsureDirs creates the required directories of the current profile.
+func EnsureDirs() error {
+	for _, r := range []*requiredDir{&configDir, &cacheDir, &appDir, &templatesDir, &sharedCacheDir} {
+		if _, err := r.ensure(); err != nil {
+			return err
 		}
-	})
+	}
+	return nil
+}
 
-	return dir
+// profileDir returns the configuration directory for the profile.
+func profileDir(p ProfileInfo) (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "."+p.ID), nil
 }
 
 var (
 	configDir requiredDir = requiredDir{
-		dir: func() (string, error) {
-			dir, err := os.UserHomeDir()
-			if err != nil {
-				return "", err
-			}
-			return filepath.Join(dir, "."+profile.ID), nil
-		},
+		dir: func() (string, error) { return profileDir(profile) },
 	}
 
 	cacheDir requiredDir = requiredDir{
-		dir: func() (string, error) {
-			dir, err := os.UserCacheDir()
-			if err != nil {
-				return "", err
-			}
-			return filepath.Join(dir, profile.ID), nil
-		},
+		dir: func() (string, error) { return ProfileCacheDir(profile) },
 	}
 )
 
+// ProfileCacheDir returns the cache directory of the profile, it is not created.
+func ProfileCacheDir(p ProfileInfo) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, p.ID), nil
+}
+
+// sharedPrefix is the prefix of the entries of the config directory of the default profile
+// that are shared by all profiles. They are kept when the default profile is deleted.
+const sharedPrefix = "_"
+
+// sharedDir returns the directory shared by all profiles with name in the config directory of the default profile.
+func sharedDir(name string) (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "."+AppName, sharedPrefix+name), nil
+}
+
+var appDir requiredDir = requiredDir{
+	dir: func() (string, error) { return sharedDir("shared") },
+}
+
+// AppDir returns the directory for the files shared by all profiles.
+func AppDir() string { return appDir.Dir() }
+
+var templatesDir requiredDir = requiredDir{
+	dir: func() (string, error) {
+		dir, err := sharedDir("templates")
+		if err != nil {
+			return "", err
+		}
+		// templates saved by earlier versions in the user config directory
+		if conf, err := os.UserConfigDir(); err == nil {
+			legacy := filepath.Join(conf, AppName, "templates")
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				if _, err := os.Stat(legacy); err == nil {
+					if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+						return "", err
+					}
+					if err := os.Rename(legacy, dir); err != nil {
+						return "", err
+					}
+				}
+			}
+		}
+		return dir, nil
+	},
+}
+
+// TemplatesDir returns the directory for configuration templates.
+// It is shared by all profiles.
+func TemplatesDir() string { return templatesDir.Dir() }
+
+// TemplateFile returns the path to the default configuration template.
+func TemplateFile() string { return templateFile(defaultTemplate) }
+
+func templateFile(name string) string { return filepath.Join(templatesDir.Dir(), name+".yaml") }
+
+// LoadTemplate loads the default configuration template on top of base,
+// values missing in the template retain the value in base.
+// An empty config is returned if the template does not exist.
+func LoadTemplate(base Config) (Config, error) {
+	if _, err := os.Stat(TemplateFile()); err != nil {
+		return Config{}, nil
+	}
+	return loadFileInto(TemplateFile(), base)
+}
+
 // Dir returns the configuration directory.
 func Dir() string { return configDir.Dir() }
 
 // CacheDir returns the cache directory.
 func CacheDir() string { return cacheDir.Dir() }
 
+// SharedCacheName is the name of the cache directory shared by all profiles.
+// It cannot be mistaken for the cache directory of a profile.
+const SharedCacheName = AppName + "_shared"
+
+var sharedCacheDir requiredDir = requiredDir{
+	dir: func() (string, error) {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, SharedCacheName), nil
+	},
+}
+
+// SharedCacheDir returns the cache directory shared by all profiles, e.g. for the downloads of release artifacts.
+// Unlike CacheDir, it is not mounted in the VM by colima, but it is within the home directory that is mounted by default.
+func SharedCacheDir() string { return sharedCacheDir.Dir() }
+
 const configFileName = "colima.yaml"
 
 func configFile() string { return filepath.Join(configDir.Dir(), configFileName) }
 
+// File returns the path to the config file of the current profile.
+func File() string { return configFile() }
+
 // Save saves the config.
+// The config file is the source of truth, comments and key order
+// of hand edits in the existing file are preserved.
+// Values inherited via extends are not saved.
 func Save(c Config) error {
-	return yamlutil.WriteYAML(c, configFile())
+	return SaveFile(c, configFile())
+}
+
+// SaveFile saves the config to file.
+// Like Save, comments and key order in the existing file are preserved.
+func SaveFile(c Config, file string) error {
+	c.Version = CurrentVersion
+	if c.Extends == "" {
+		return yamlutil.UpdateYAML(c, file)
+	}
+
+	value, err := withoutExtended(c, filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	return yamlutil.UpdateYAML(value, file)
 }
 
 // Load loads the config.
 // Error is only returned if the config file exists but could not be loaded.
 // No error is returned if the config file does not exist.
-func Load() (Config, error) {
+func Load() (Config, error) { return loadFile(configFile()) }
+
+// LoadInto loads the config on top of base,
+// values missing in the config file retain the value in base.
+// Like Load, an empty config is returned if the config file does not exist.
+func LoadInto(base Config) (Config, error) {
 	if _, err := os.Stat(configFile()); err != nil {
+		return Config{}, nil
+	}
+	c, err := loadFileInto(configFile(), base)
+	if err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// Profiles returns the names of the profiles with a saved config.
+func Profiles() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := filepath.Glob(filepath.Join(home, "."+AppName+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, configFileName)); err != nil {
+			continue
+		}
+		switch name := strings.TrimPrefix(filepath.Base(dir), "."+AppName); {
+		case name == "":
+			names = append(names, "default")
+		case strings.HasPrefix(name, "-"):
+			names = append(names, name[1:])
+		}
+	}
+	return names, nil
+}
+
+// HostDirs returns the existing directories created on the host for all profiles.
+// i.e. the config and cache directory of every profile, and the shared config and cache directories.
+func HostDirs() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	conf, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := []string{
+		filepath.Join(home, "."+AppName),
+		filepath.Join(home, "."+AppName+"-*"),
+		filepath.Join(cache, AppName),
+		filepath.Join(cache, AppName+"-*"),
+		filepath.Join(cache, SharedCacheName),
+		filepath.Join(conf, AppName),
+	}
+	var dirs []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if stat, err := os.Stat(m); err == nil && stat.IsDir() {
+				dirs = append(dirs, m)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// LoadProfile loads the config of the profile without changing the current profile.
+// Like Load, no error is returned if the config file does not exist.
+func LoadProfile(profileName string) (Config, error) {
+	dir, err := profileDir(ProfileFromName(profileName))
+	if err != nil {
+		return Config{}, err
+	}
+	return loadFile(filepath.Join(dir, configFileName))
+}
+
+// LoadFile loads the config at file on top of base,
+// values missing in the file retain the value in base.
+func LoadFile(file string, base Config) (Config, error) {
+	if _, err := os.Stat(file); err != nil {
+		return Config{}, fmt.Errorf("could not load settings: %w", err)
+	}
+	return loadFileInto(file, base)
+}
+
+func loadFile(file string) (Config, error) {
+	if _, err := os.Stat(file); err != nil {
 		// config file does not exist
 		return Config{}, nil
 	}
-	var c Config
-	b, err := os.ReadFile(configFile())
+	return loadFileInto(file, baseConfig())
+}
+
+// baseConfig is the config the config files are loaded on top of,
+// with the defaults of the settings that are enabled unless disabled.
+func baseConfig() Config {
+	return Config{Kubernetes: Kubernetes{MetricsServer: true}}
+}
+
+func loadFileInto(file string, c Config) (Config, error) {
+	b, err := os.ReadFile(file)
 	if err != nil {
-		return c, fmt.Errorf("could not load previous settings: %w", err)
+		return c, fmt.Errorf("could not load settings from %s: %w", file, err)
 	}
 
-	err = yaml.Unmarshal(b, &c)
+	c, err = load(b, filepath.Dir(file), c, map[string]bool{file: true})
 	if err != nil {
-		return c, fmt.Errorf("could not load previous settings: %w", err)
+		return c, fmt.Errorf("could not load settings from %s: %w", file, err)
 	}
 	return c, nil
 }
 
+// load loads the config in b on top of c.
+// dir is the directory for resolving relative extends file paths.
+func load(b []byte, dir string, c Config, visited map[string]bool) (Config, error) {
+	b, err := migrate(b)
+	if err != nil {
+		return c, err
+	}
+
+	var e struct {
+		Extends string `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(b, &e); err != nil {
+		return c, err
+	}
+	if e.Extends != "" {
+		c, err = loadExtended(e.Extends, dir, c, visited)
+		if err != nil {
+			return c, err
+		}
+	}
+
+	err = yaml.Unmarshal(b, &c)
+	return c, err
+}
+
+// CheckFile checks that file is a valid config file.
+// Unknown keys and values of the wrong type are reported as errors.
+func CheckFile(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", file, err)
+	}
+	b, err = migrate(b)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", file, err)
+	}
+
+	var c struct {
+		Config `yaml:",inline"`
+		// only valid in the project-local config file
+		Profile string `yaml:"profile,omitempty"`
+	}
+	var dst interface{} = &c.Config
+	if filepath.Base(file) == ProjectFileName {
+		dst = &c
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(dst); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid config file %s: %w", file, err)
+	}
+	return nil
+}
+
+// RenameProfile renames the config and cache directories of the current profile
+// to those of the profile newName.
+func RenameProfile(newName string) error {
+	to := ProfileFromName(newName)
+
+	fromDir, err := profileDir(profile)
+	if err != nil {
+		return err
+	}
+	toDir, err := profileDir(to)
+	if err != nil {
+		return err
+	}
+	if entries, _ := ProfileEntries(toDir); len(entries) > 0 {
+		return fmt.Errorf("profile '%s' already exists", to.ShortName)
+	}
+	entries, err := ProfileEntries(fromDir)
+	if err != nil {
+		return fmt.Errorf("error renaming config directory: %w", err)
+	}
+	if len(entries) > 0 {
+		if err := os.MkdirAll(toDir, 0755); err != nil {
+			return fmt.Errorf("error renaming config directory: %w", err)
+		}
+	}
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(fromDir, e), filepath.Join(toDir, e)); err != nil {
+			return fmt.Errorf("error renaming config directory: %w", err)
+		}
+	}
+	// kept if it holds the directories shared by all profiles
+	_ = os.Remove(fromDir)
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+	fromCache, toCache := filepath.Join(cache, profile.ID), filepath.Join(cache, to.ID)
+	if _, err := os.Stat(fromCache); err == nil {
+		// the cache is disposable
+		_ = os.RemoveAll(toCache)
+		if err := os.Rename(fromCache, toCache); err != nil {
+			return fmt.Errorf("error renaming cache directory: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Teardown deletes the config.
 func Teardown() error {
-	if _, err := os.Stat(configDir.Dir()); err == nil {
-		return os.RemoveAll(configDir.Dir())
+	dir := configDir.Dir()
+	entries, err := ProfileEntries(dir)
+	if err != nil {
+		return err
 	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e)); err != nil {
+			return err
+		}
+	}
+	// kept if it holds the directories shared by all profiles
+	_ = os.Remove(dir)
 	return nil
 }
 
+// ProfileEntries returns the names of the entries of the config directory of a profile,
+// without the directories shared by all profiles in the config directory of the default profile.
+func ProfileEntries(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), sharedPrefix) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
 // Config is the application config.
 type Config struct {
+	// Version is the version of the config schema, used for migrations.
+	Version int `yaml:"version"`
+
+	// Extends is a profile name or config file path whose config this config is layered on.
+	Extends string `yaml:"extends,omitempty"`
+
 	// Virtual Machine
 	VM VM `yaml:"vm"`
 
+	// Remote is the SSH destination of a remote Linux machine that backs the profile instead of a VM,
+	// in the form user@host[:port].
+	Remote string `yaml:"remote,omitempty"`
+	// RemoteInstall sets if docker is installed on the remote host with the Docker install script if missing.
+	RemoteInstall bool `yaml:"remote_install,omitempty"`
+	// RemoteManage sets if colima manages the docker daemon of the remote host, its config and its lifecycle.
+	RemoteManage bool `yaml:"remote_manage,omitempty"`
+
 	// Runtime is one of docker, containerd.
 	Runtime string `yaml:"runtime"`
 
 	// Kubernetes sets if kubernetes should be enabled.
 	Kubernetes Kubernetes `yaml:"kubernetes"`
+
+	// Registry is the container registry configuration.
+	// It applies to both the container runtime and Kubernetes.
+	Registry Registry `yaml:"registry,omitempty"`
+
+	// LogFormat is the format of the log output, text or json.
+	// The --log-format flag takes precedence.
+	LogFormat string `yaml:"log_format,omitempty"`
+
+	// Notify enables desktop notifications when the profile starts, fails to start
+	// or is low on disk space.
+	Notify bool `yaml:"notify,omitempty"`
+
+	// WaitTimeout is the time to wait for the components to become ready on start.
+	WaitTimeout WaitTimeout `yaml:"wait_timeout,omitempty"`
+
+	// Hooks are host commands run on lifecycle events of the profile.
+	Hooks Hooks `yaml:"hooks,omitempty"`
+
+	// Idle is the policy applied when the profile is not in use.
+	Idle Idle `yaml:"idle,omitempty"`
+
+	// Channel is the release channel of the components provisioned in the VM, one of stable and edge.
+	// Defaults to stable.
+	Channel string `yaml:"channel,omitempty"`
+
+	// LazyStart keeps the Docker socket on the host listening while the profile is stopped,
+	// the profile is started on the first connection.
+	LazyStart bool `yaml:"lazy_start,omitempty"`
+
+	// DockerTransport is the transport of the docker context on the host, one of unix and ssh.
+	// Defaults to unix, the socket forwarded from the VM.
+	DockerTransport string `yaml:"docker_transport,omitempty"`
+
+	// Health is the periodic health check of the profile.
+	Health Health `yaml:"health,omitempty"`
+
+	// Tunnels are the forwards from the host into addresses reachable from the VM,
+	// in the ssh -L form, kept open by the monitor while the profile is running.
+	Tunnels []string `yaml:"tunnels,omitempty"`
+
+	// Provision are the scripts run in the VM when it is created.
+	Provision []Provision `yaml:"provision,omitempty"`
+
+	// Packages are the packages installed in the VM with the package manager.
+	Packages []string `yaml:"packages,omitempty"`
+
+	// DockerEnv and ContainerdEnv are the environment variables of the container runtime daemons in the VM,
+	// e.g. proxies only for the runtime.
+	DockerEnv     map[string]string `yaml:"docker_env,omitempty"`
+	ContainerdEnv map[string]string `yaml:"containerd_env,omitempty"`
+
+	// ContainerdConfig are TOML snippets appended to the containerd config of the VM.
+	ContainerdConfig []string `yaml:"containerd_config,omitempty"`
+
+	// DockerUlimits are the default ulimits of the Docker containers by name e.g. nofile, memlock,
+	// in the form soft[:hard].
+	DockerUlimits map[string]string `yaml:"docker_ulimits,omitempty"`
+	// DockerShmSize is the default size of /dev/shm of the Docker containers e.g. 1g.
+	DockerShmSize string `yaml:"docker_shm_size,omitempty"`
+
+	// NTP is the time synchronisation of the VM.
+	NTP NTP `yaml:"ntp,omitempty"`
+}
+
+// cgroup versions.
+const (
+	CgroupV1 = "v1"
+	CgroupV2 = "v2"
+)
+
+// Release channels of the components.
+const (
+	ChannelStable = "stable"
+	ChannelEdge   = "edge"
+)
+
+// Transports of the docker context.
+const (
+	DockerTransportUnix = "unix"
+	DockerTransportSSH  = "ssh"
+)
+
+// TCG threading modes.
+const (
+	TCGThreadSingle = "single"
+	TCGThreadMulti  = "multi"
+)
+
+// TCG is the tuning of the QEMU emulator (TCG) for an emulated VM.
+type TCG struct {
+	// Thread is the threading of the emulation, one of single and multi. Defaults to the QEMU default.
+	Thread string `yaml:"thread,omitempty"`
+	// TBSize is the size in MiB of the cache of translated code. Defaults to the QEMU default.
+	TBSize int `yaml:"tb_size,omitempty"`
+}
+
+// Empty returns if no tuning is set.
+func (t TCG) Empty() bool { return t.Thread == "" && t.TBSize == 0 }
+
+// NTP is the time synchronisation of the VM with NTP servers.
+type NTP struct {
+	// Servers are the NTP servers, the servers of the VM image are used if unset.
+	Servers []string `yaml:"servers,omitempty"`
+	// Disabled disables the NTP client e.g. in air-gapped networks, the clock is synced with the host.
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// Provision modes.
+const (
+	ProvisionModeSystem = "system"
+	ProvisionModeUser   = "user"
+)
+
+// Provision is a script run in the VM.
+type Provision struct {
+	// Mode is one of system (run as root) and user. Defaults to system.
+	Mode string `yaml:"mode,omitempty"`
+	// Script is the script, run with sh if it has no shebang.
+	Script string `yaml:"script"`
+	// EveryBoot runs the script on every boot of the VM, not only when it is created.
+	EveryBoot bool `yaml:"every_boot,omitempty"`
+}
+
+// Health repairs.
+const (
+	HealthRepairRuntime = "runtime"
+	HealthRepairDisk    = "disk"
+)
+
+// Health is the periodic check of the container runtime, DNS and disk space of the profile.
+type Health struct {
+	// Interval is the interval between the checks in seconds, 0 disables the checks.
+	Interval int `yaml:"interval,omitempty"`
+	// Repairs are the repairs performed for failed checks, any of
+	// runtime (restart the unresponsive runtime) and disk (prune the build cache and dangling images).
+	Repairs []string `yaml:"repairs,omitempty"`
+}
+
+// Enabled returns if the health checks are enabled.
+func (h Health) Enabled() bool { return h.Interval > 0 }
+
+// IntervalDuration returns the interval between the checks.
+func (h Health) IntervalDuration() time.Duration { return time.Duration(h.Interval) * time.Second }
+
+// Repair returns if the repair is enabled.
+func (h Health) Repair(name string) bool {
+	for _, r := range h.Repairs {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Idle actions.
+const (
+	IdleActionStop  = "stop"
+	IdleActionPause = "pause"
+)
+
+// Idle is the policy applied after a period with no running containers or Kubernetes
+// workloads and no connections to the Docker socket.
+type Idle struct {
+	// Timeout is the idle period in minutes before the action is applied, 0 disables the policy.
+	Timeout int `yaml:"timeout,omitempty"`
+	// Action is one of stop, pause. Defaults to stop.
+	Action string `yaml:"action,omitempty"`
+}
+
+// Enabled returns if the idle policy is enabled.
+func (i Idle) Enabled() bool { return i.Timeout > 0 }
+
+// IdleAction returns the action of the idle policy, or the default if unset.
+func (i Idle) IdleAction() string {
+	if i.Action == "" {
+		return IdleActionStop
+	}
+	return i.Action
+}
+
+// Hooks are shell commands run on the host before and after the profile starts and stops.
+// The commands of an event run in order, a failing pre hook aborts the operation.
+type Hooks struct {
+	PreStart  []string `yaml:"pre_start,omitempty"`
+	PostStart []string `yaml:"post_start,omitempty"`
+	PreStop   []string `yaml:"pre_stop,omitempty"`
+	PostStop  []string `yaml:"post_stop,omitempty"`
+}
+
+// WaitTimeout is the duration in seconds to wait for each component to become ready,
+// before the start fails.
+type WaitTimeout struct {
+	Docker     int `yaml:"docker,omitempty"`
+	Containerd int `yaml:"containerd,omitempty"`
+	Kubernetes int `yaml:"kubernetes,omitempty"`
+}
+
+// Default wait timeouts in seconds.
+const (
+	DefaultWaitTimeout           = 60
+	DefaultKubernetesWaitTimeout = 120
+)
+
+// Duration returns the wait timeout of the component, or the default if unset.
+// component is one of docker, containerd, kubernetes.
+func (w WaitTimeout) Duration(component string) time.Duration {
+	timeout, def := 0, DefaultWaitTimeout
+	switch component {
+	case "docker":
+		timeout = w.Docker
+	case "containerd":
+		timeout = w.Containerd
+	case "kubernetes":
+		timeout, def = w.Kubernetes, DefaultKubernetesWaitTimeout
+	}
+	if timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return time.Duration(def) * time.Second
+}
+
+// Registry is container registry configuration.
+type Registry struct {
+	// Mirrors are mirrors for the docker.io registry.
+	Mirrors []string `yaml:"mirrors,omitempty"`
+	// Insecure are registries accessed without TLS verification.
+	Insecure []string `yaml:"insecure,omitempty"`
+	// Auths are registry credentials, keyed by the registry host.
+	Auths map[string]RegistryAuth `yaml:"auths,omitempty"`
+}
+
+// RegistryAuth is the credential for a registry.
+type RegistryAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
 }
 
 // Kubernetes is kubernetes configuration
 type Kubernetes struct {
 	Enabled bool   `yaml:"enabled"`
 	Version string `yaml:"version"`
+
+	// MetricsServer enables metrics-server for 'kubectl top', enabled unless disabled.
+	MetricsServer bool `yaml:"metrics_server"`
+
+	// AirgapPath is a local directory with the k3s release artifacts for offline installs.
+	// It should contain the k3s binary, airgap images, install.sh and sha256sum file as published on k3s releases.
+	AirgapPath string `yaml:"airgap_path,omitempty"`
+
+	// Port is the Kubernetes API server port, forwarded to the same port on the host.
+	// Defaults to 6443.
+	Port int `yaml:"port,omitempty"`
+	// TLSSAN are additional hostnames or IP addresses for the API server certificate.
+	TLSSAN []string `yaml:"tls_san,omitempty"`
+
+	// Manifests are manifest files or URLs applied when the cluster starts.
+	Manifests []string `yaml:"manifests,omitempty"`
+	// HelmCharts are Helm charts installed when the cluster starts.
+	HelmCharts []HelmChart `yaml:"helm_charts,omitempty"`
+
+	// Join is the profile whose cluster to join as an agent node.
+	// Requires a reachable IP address for both profiles (macOS only).
+	Join string `yaml:"join,omitempty"`
+}
+
+// HelmChart is a Helm chart installed in the Kubernetes cluster.
+type HelmChart struct {
+	Name      string                 `yaml:"name"`
+	Chart     string                 `yaml:"chart"`
+	Repo      string                 `yaml:"repo,omitempty"`
+	Version   string                 `yaml:"version,omitempty"`
+	Namespace string                 `yaml:"namespace,omitempty"`
+	Values    map[string]interface{} `yaml:"values,omitempty"`
 }
 
 // VM is virtual machine configuration.
@@ -173,8 +856,34 @@ type VM struct {
 	Memory int    `yaml:"memory"`
 	Arch   string `yaml:"arch"`
 
+	// CPUAffinity are the host cores the vCPUs are pinned to e.g. 0-3,6.
+	// On macOS, only 'efficiency' is supported to run the VM on the efficiency cores.
+	CPUAffinity string `yaml:"cpu_affinity,omitempty"`
+
 	ForwardAgent bool `yaml:"forward_agent"`
 
+	// SSHPort is the SSH port on the host, allocated from PortRange if unset.
+	SSHPort int `yaml:"ssh_port,omitempty"`
+	// PortRange is the range of ports to allocate from, defaults to DefaultPortRange.
+	PortRange string `yaml:"port_range,omitempty"`
+	// SSHAuthorizedKeys are additional public keys, or paths to public key files on the host,
+	// authorized to SSH into the VM.
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+	// CloudInit is the path to a cloud-init user-data file applied to the VM.
+	CloudInit string `yaml:"cloud_init,omitempty"`
+	// Timezone is the timezone of the VM, defaults to the timezone of the host.
+	Timezone string `yaml:"timezone,omitempty"`
+	// Cgroup is the cgroup version of the VM, one of v1 and v2. Defaults to the version of the VM image.
+	Cgroup string `yaml:"cgroup,omitempty"`
+	// TCG is the tuning of the emulation, applied if the architecture differs from the host.
+	TCG TCG `yaml:"tcg,omitempty"`
+	// QEMUBinary is the path to the qemu-system binary of the architecture, looked up in the PATH if unset.
+	QEMUBinary string `yaml:"qemu_binary,omitempty"`
+
+	// ShutdownTimeout is the duration in seconds given to containers to stop gracefully
+	// and to the VM to shut down, before it is forcefully stopped.
+	ShutdownTimeout int `yaml:"shutdown_timeout,omitempty"`
+
 	// volume mounts
 	Mounts []string `yaml:"mounts"`
 
@@ -183,5 +892,60 @@ type VM struct {
 	Env map[string]string `yaml:"-"` // environment variables
 }
 
+// PortRangeOrDefault returns the port range, or the default if unset.
+func (v VM) PortRangeOrDefault() string {
+	if v.PortRange != "" {
+		return v.PortRange
+	}
+	return DefaultPortRange
+}
+
+// AuthorizedKeys returns the public keys of SSHAuthorizedKeys, with the files read.
+func (v VM) AuthorizedKeys() ([]string, error) {
+	var keys []string
+	for _, k := range v.SSHAuthorizedKeys {
+		k = strings.TrimSpace(k)
+		// <type> <base64 key> [comment]
+		if fields := strings.Fields(k); len(fields) >= 2 && !strings.ContainsAny(fields[0], `/\`) {
+			keys = append(keys, k)
+			continue
+		}
+
+		file := k
+		if strings.HasPrefix(file, "~") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			file = strings.Replace(file, "~", home, 1)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ssh authorized key '%s': %w", k, err)
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// DefaultShutdownTimeout is the default shutdown timeout in seconds.
+const DefaultShutdownTimeout = 30
+
+// ShutdownDuration returns the shutdown timeout, or the default if unset.
+func (v VM) ShutdownDuration() time.Duration {
+	if v.ShutdownTimeout > 0 {
+		return time.Duration(v.ShutdownTimeout) * time.Second
+	}
+	return DefaultShutdownTimeout * time.Second
+}
+
+// DaemonManaged returns if colima manages the config and the lifecycle of the container runtime daemon,
+// always in a VM and with remote_manage on a remote host, that may be shared.
+func (c Config) DaemonManaged() bool { return c.Remote == "" || c.RemoteManage }
+
 // Empty checks if the configuration is empty.
 func (c Config) Empty() bool { return c.Runtime == "" } // this may be better but not really needed.
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_load_metricsServer(t *testing.T) {
+	disabled := baseConfig()
+	disabled.Kubernetes.MetricsServer = false
+
+	tests := []struct {
+		yaml string
+		base Config
+		want bool
+	}{
+		{yaml: "version: 1\nruntime: docker\n", base: baseConfig(), want: true},
+		// configs created prior to the toggle
+		{yaml: "runtime: docker\nkubernetes:\n  enabled: true\n", base: disabled, want: true},
+		{yaml: "version: 1\nruntime: docker\nkubernetes:\n  enabled: true\n", base: baseConfig(), want: true},
+		{yaml: "version: 1\nruntime: docker\nkubernetes:\n  metrics_server: false\n", base: baseConfig()},
+		// the value of the base is retained
+		{yaml: "version: 1\nruntime: docker\nkubernetes:\n  enabled: true\n", base: disabled},
+		{yaml: "version: 1\nruntime: docker\nkubernetes:\n  metrics_server: true\n", base: disabled, want: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			c, err := load([]byte(tt.yaml), t.TempDir(), tt.base, map[string]bool{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Kubernetes.MetricsServer != tt.want {
+				t.Errorf("load() metrics server = %v, want %v", c.Kubernetes.MetricsServer, tt.want)
+			}
+		})
+	}
+}
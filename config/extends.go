@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extendedFile returns the config file for the value of extends.
+// extends is either a file path, relative to dir if not absolute, or a profile name.
+func extendedFile(extends, dir string) (string, error) {
+	if strings.HasSuffix(extends, ".yaml") || strings.HasSuffix(extends, ".yml") || strings.ContainsRune(extends, os.PathSeparator) {
+		if strings.HasPrefix(extends, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			extends = filepath.Join(home, extends[2:])
+		}
+		if !filepath.IsAbs(extends) {
+			extends = filepath.Join(dir, extends)
+		}
+		return extends, nil
+	}
+
+	profileDir, err := profileDir(ProfileFromName(extends))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, configFileName), nil
+}
+
+// loadExtended loads the config extended by extends on top of c.
+// visited are the files in the chain of extends, to detect cycles.
+func loadExtended(extends, dir string, c Config, visited map[string]bool) (Config, error) {
+	file, err := extendedFile(extends, dir)
+	if err != nil {
+		return c, fmt.Errorf("error resolving extends '%s': %w", extends, err)
+	}
+	if visited[file] {
+		return c, fmt.Errorf("cyclic extends '%s'", extends)
+	}
+	visited[file] = true
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return c, fmt.Errorf("error loading extends '%s': %w", extends, err)
+	}
+	return load(b, filepath.Dir(file), c, visited)
+}
+
+// withoutExtended returns c encoded as a map without the values
+// that are the same in the config it extends.
+func withoutExtended(c Config, dir string) (map[string]interface{}, error) {
+	base, err := loadExtended(c.Extends, dir, Config{}, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := toMap(c)
+	if err != nil {
+		return nil, err
+	}
+	baseMap, err := toMap(base)
+	if err != nil {
+		return nil, err
+	}
+
+	d := diff(m, baseMap)
+	d["extends"] = c.Extends
+	d["version"] = c.Version
+	return d, nil
+}
+
+func toMap(c Config) (map[string]interface{}, error) {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding config: %w", err)
+	}
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("error encoding config: %w", err)
+	}
+	return m, nil
+}
+
+// diff returns the values in m that are not in base.
+func diff(m, base map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range m {
+		bv, ok := base[k]
+		if vm, isMap := v.(map[string]interface{}); isMap {
+			if bm, isMap := bv.(map[string]interface{}); isMap {
+				if d := diff(vm, bm); len(d) > 0 {
+					out[k] = d
+				}
+				continue
+			}
+		}
+		if ok && reflect.DeepEqual(v, bv) {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys returns the configurable keys, as dot separated yaml paths.
+// e.g. vm.cpu, kubernetes.enabled
+func Keys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			name := yamlName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			if t.Field(i).Type.Kind() == reflect.Struct {
+				walk(t.Field(i).Type, prefix+name+".")
+				continue
+			}
+			keys = append(keys, prefix+name)
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return keys
+}
+
+// GetKey returns the value of key in c encoded as YAML.
+// key is a dot separated yaml path e.g. kubernetes.enabled.
+// Keys of the VM can be specified without the 'vm.' prefix e.g. memory.
+func GetKey(c Config, key string) (string, error) {
+	v, err := lookupKey(reflect.ValueOf(&c).Elem(), key)
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.Marshal(v.Interface())
+	if err != nil {
+		return "", fmt.Errorf("error encoding value of %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// SetKey sets key in c to value and returns the updated config.
+// value is decoded as YAML into the type of the key.
+// e.g. 'true' for a bool, '[a, b]' for a list.
+func SetKey(c Config, key, value string) (Config, error) {
+	v, err := lookupKey(reflect.ValueOf(&c).Elem(), key)
+	if err != nil {
+		return c, err
+	}
+
+	ptr := reflect.New(v.Type())
+	if err := yaml.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+		return c, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	v.Set(ptr.Elem())
+	return c, nil
+}
+
+func lookupKey(v reflect.Value, key string) (reflect.Value, error) {
+	path := strings.Split(key, ".")
+	if len(path) == 1 {
+		// shorthand for vm keys
+		if _, found := fieldByYAMLName(v, path[0]); !found {
+			path = append([]string{"vm"}, path...)
+		}
+	}
+
+	for _, name := range path {
+		if v.Kind() != reflect.Struct {
+			return v, fmt.Errorf("invalid config key '%s'", key)
+		}
+		field, found := fieldByYAMLName(v, name)
+		if !found {
+			return v, fmt.Errorf("invalid config key '%s'", key)
+		}
+		v = field
+	}
+	return v, nil
+}
+
+func fieldByYAMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if n := yamlName(v.Type().Field(i)); n != "" && n == name {
+			return v.Field(i), true
+		}
+	}
+	return v, false
+}
+
+// yamlName returns the yaml key for the struct field,
+// or an empty string if it is not persisted.
+func yamlName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
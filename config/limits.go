@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ulimitNames are the valid names of ulimits for containers.
+var ulimitNames = map[string]bool{
+	"core": true, "cpu": true, "data": true, "fsize": true, "locks": true, "memlock": true, "msgqueue": true,
+	"nice": true, "nofile": true, "nproc": true, "rss": true, "rtprio": true, "rttime": true, "sigpending": true, "stack": true,
+}
+
+// Ulimit is a soft and hard resource limit, -1 is unlimited.
+type Ulimit struct {
+	Name string `json:"Name"`
+	Soft int64  `json:"Soft"`
+	Hard int64  `json:"Hard"`
+}
+
+// ParseUlimit parses the ulimit name with the value in the form soft[:hard].
+// The hard limit defaults to the soft limit, 'unlimited' or -1 is unlimited.
+func ParseUlimit(name, value string) (Ulimit, error) {
+	u := Ulimit{Name: name}
+	if !ulimitNames[name] {
+		return u, fmt.Errorf("invalid ulimit '%s'", name)
+	}
+
+	parse := func(s string) (int64, error) {
+		s = strings.TrimSpace(s)
+		if s == "unlimited" {
+			return -1, nil
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < -1 {
+			return 0, fmt.Errorf("invalid ulimit %s '%s', expected soft[:hard]", name, value)
+		}
+		return n, nil
+	}
+
+	parts := strings.SplitN(value, ":", 2)
+	var err error
+	if u.Soft, err = parse(parts[0]); err != nil {
+		return u, err
+	}
+	u.Hard = u.Soft
+	if len(parts) == 2 {
+		if u.Hard, err = parse(parts[1]); err != nil {
+			return u, err
+		}
+	}
+	if u.Hard != -1 && (u.Soft == -1 || u.Soft > u.Hard) {
+		return u, fmt.Errorf("invalid ulimit %s '%s', the soft limit exceeds the hard limit", name, value)
+	}
+	return u, nil
+}
+
+// CPUAffinityEfficiency is the CPU affinity for the efficiency cores of Apple Silicon.
+const CPUAffinityEfficiency = "efficiency"
+
+// ParseCPUList parses a list of host cores in the form of taskset e.g. 0-3,6.
+// The cores must exist on the host.
+func ParseCPUList(s string) ([]int, error) {
+	var cores []int
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		min, err := strconv.Atoi(bounds[0])
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("invalid cpu list '%s'", s)
+		}
+		max := min
+		if len(bounds) == 2 {
+			if max, err = strconv.Atoi(bounds[1]); err != nil || max < min {
+				return nil, fmt.Errorf("invalid cpu list '%s'", s)
+			}
+		}
+		if n := runtime.NumCPU(); max >= n {
+			return nil, fmt.Errorf("invalid cpu list '%s', the host has %d cores", s, n)
+		}
+		for c := min; c <= max; c++ {
+			cores = append(cores, c)
+		}
+	}
+	return cores, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseUlimit(t *testing.T) {
+	tests := []struct {
+		name, value string
+		want        Ulimit
+		wantErr     bool
+	}{
+		{name: "nofile", value: "1024", want: Ulimit{Name: "nofile", Soft: 1024, Hard: 1024}},
+		{name: "nofile", value: "1024:65536", want: Ulimit{Name: "nofile", Soft: 1024, Hard: 65536}},
+		{name: "memlock", value: "unlimited", want: Ulimit{Name: "memlock", Soft: -1, Hard: -1}},
+		{name: "memlock", value: "-1", want: Ulimit{Name: "memlock", Soft: -1, Hard: -1}},
+		{name: "core", value: "0:unlimited", want: Ulimit{Name: "core", Soft: 0, Hard: -1}},
+		{name: "nofile", value: "65536:1024", wantErr: true},
+		{name: "nofile", value: "unlimited:1024", wantErr: true},
+		{name: "nofile", value: "-2", wantErr: true},
+		{name: "nofile", value: "many", wantErr: true},
+		{name: "nofile", value: "", wantErr: true},
+		{name: "files", value: "1024", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := ParseUlimit(tt.name, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUlimit(%s, %s) error = %v, wantErr %v", tt.name, tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseUlimit(%s, %s) = %+v, want %+v", tt.name, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	n := runtime.NumCPU()
+	tests := []struct {
+		s       string
+		want    []int
+		wantErr bool
+	}{
+		{s: "0", want: []int{0}},
+		{s: " 0,0", want: []int{0, 0}},
+		{s: fmt.Sprintf("0-%d", n-1), want: cores(n)},
+		{s: fmt.Sprint(n), wantErr: true},
+		// not expanded
+		{s: "0-999999999", wantErr: true},
+		{s: "1-0", wantErr: true},
+		{s: "-1", wantErr: true},
+		{s: "0,a", wantErr: true},
+		{s: "", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := ParseCPUList(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCPUList(%s) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCPUList(%s) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func cores(n int) (c []int) {
+	for i := 0; i < n; i++ {
+		c = append(c, i)
+	}
+	return c
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the config schema.
+// It must be incremented when a migration is added.
+const CurrentVersion = 1
+
+// ErrUnsupportedVersion is returned for configs created by a newer version of colima.
+var ErrUnsupportedVersion = errors.New("unsupported config version")
+
+// migration migrates the raw config from a version to the next.
+type migration func(raw map[string]interface{})
+
+// migrations are the config migrations, migrations[i] migrates from version i to i+1.
+var migrations = []migration{
+	// v0 -> v1: metrics_server was introduced and enabled by default,
+	// configs created before then have it unset.
+	func(raw map[string]interface{}) {
+		k, _ := raw["kubernetes"].(map[string]interface{})
+		if k == nil {
+			return
+		}
+		if _, ok := k["metrics_server"]; !ok {
+			k["metrics_server"] = true
+		}
+	},
+}
+
+// migrate migrates the config in b to the current version.
+func migrate(b []byte) ([]byte, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return b, nil
+	}
+
+	version := 0
+	if v, ok := raw["version"]; ok {
+		if version, ok = v.(int); !ok {
+			return nil, fmt.Errorf("invalid config version '%v'", v)
+		}
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("%w: version %d is newer than the supported version %d, upgrade colima to use it", ErrUnsupportedVersion, version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return b, nil
+	}
+
+	for _, m := range migrations[version:] {
+		m(raw)
+	}
+	raw["version"] = CurrentVersion
+
+	return yaml.Marshal(raw)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultPortRange is the default range of ports allocated to profiles.
+const DefaultPortRange = "50100-50999"
+
+// ParsePortRange parses a port range in the form <min>-<max>.
+func ParsePortRange(s string) (min, max int, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid port range '%s', expected <min>-<max>", s)
+	}
+	if min, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return 0, 0, fmt.Errorf("invalid port range '%s': %w", s, err)
+	}
+	if max, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+		return 0, 0, fmt.Errorf("invalid port range '%s': %w", s, err)
+	}
+	if min < 1 || max > 65535 || min > max {
+		return 0, 0, fmt.Errorf("invalid port range '%s'", s)
+	}
+	return min, max, nil
+}
+
+// AllocatePort allocates a port for key in portRange.
+// The allocation is deterministic, the preferred port is derived from key and
+// the next port is used if it is in use or in used.
+func AllocatePort(key, portRange string, used map[int]bool) (int, error) {
+	min, max, err := ParsePortRange(portRange)
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	size := max - min + 1
+	offset := int(h.Sum32() % uint32(size))
+
+	for i := 0; i < size; i++ {
+		port := min + (offset+i)%size
+		if used[port] || !PortAvailable(port) {
+			continue
+		}
+		return port, nil
+	}
+	return 0, fmt.Errorf("no free port in range %s", portRange)
+}
+
+// UsedPorts returns the ports persisted in the config of all profiles except the current.
+func UsedPorts() map[int]bool {
+	used := map[int]bool{}
+	profiles, _ := Profiles()
+	for _, p := range profiles {
+		if ProfileFromName(p).ID == profile.ID {
+			continue
+		}
+		c, err := LoadProfile(p)
+		if err != nil {
+			continue
+		}
+		used[c.VM.SSHPort] = true
+		used[c.Kubernetes.Port] = true
+	}
+	delete(used, 0)
+	return used
+}
+
+// PortAvailable returns if port is free for use on the host.
+func PortAvailable(port int) bool {
+	l, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
+
+// Tunnel is a forward of a local address on the host to an address reachable from the VM.
+type Tunnel struct {
+	// Local is the local address, <bind address>:<port>.
+	Local string
+	// Remote is the address, <host>:<port>, resolved in the VM.
+	Remote string
+}
+
+// ParseTunnel parses a tunnel in the ssh -L form [<bind address>:]<port>:<host>:<host port>.
+func ParseTunnel(s string) (Tunnel, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) == 3 {
+		// ssh binds to localhost by default
+		parts = append([]string{"127.0.0.1"}, parts...)
+	}
+	if len(parts) != 4 || parts[0] == "" || parts[2] == "" {
+		return Tunnel{}, fmt.Errorf("invalid tunnel '%s', expected [<bind address>:]<port>:<host>:<host port>", s)
+	}
+	for _, p := range []string{parts[1], parts[3]} {
+		if port, err := strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+			return Tunnel{}, fmt.Errorf("invalid tunnel '%s', invalid port '%s'", s, p)
+		}
+	}
+	return Tunnel{Local: parts[0] + ":" + parts[1], Remote: parts[2] + ":" + parts[3]}, nil
+}
+
+// RemoteHost is the SSH destination of a remote host.
+type RemoteHost struct {
+	User string
+	Host string
+	// Port is the SSH port, 22 if not set.
+	Port int
+}
+
+// ParseRemote parses a remote host in the form user@host[:port].
+func ParseRemote(s string) (RemoteHost, error) {
+	r := RemoteHost{Port: 22}
+	at := strings.Index(s, "@")
+	if at < 1 {
+		return r, fmt.Errorf("invalid remote '%s', expected user@host[:port]", s)
+	}
+	r.User, r.Host = s[:at], s[at+1:]
+	if i := strings.LastIndex(r.Host, ":"); i >= 0 {
+		port, err := strconv.Atoi(r.Host[i+1:])
+		if err != nil || port < 1 || port > 65535 {
+			return r, fmt.Errorf("invalid remote '%s', invalid port '%s'", s, r.Host[i+1:])
+		}
+		r.Host, r.Port = r.Host[:i], port
+	}
+	if r.Host == "" {
+		return r, fmt.Errorf("invalid remote '%s', expected user@host[:port]", s)
+	}
+	return r, nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseTunnel(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Tunnel
+		wantErr bool
+	}{
+		{s: "5432:db:5432", want: Tunnel{Local: "127.0.0.1:5432", Remote: "db:5432"}},
+		{s: "0.0.0.0:8080:localhost:80", want: Tunnel{Local: "0.0.0.0:8080", Remote: "localhost:80"}},
+		{s: "5432:db", wantErr: true},
+		{s: "5432::5432", wantErr: true},
+		{s: ":5432:db:5432", wantErr: true},
+		{s: "0:db:5432", wantErr: true},
+		{s: "5432:db:65536", wantErr: true},
+		{s: "port:db:5432", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := ParseTunnel(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTunnel(%s) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseTunnel(%s) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    RemoteHost
+		wantErr bool
+	}{
+		{s: "user@buildbox", want: RemoteHost{User: "user", Host: "buildbox", Port: 22}},
+		{s: "user@10.0.0.2:2222", want: RemoteHost{User: "user", Host: "10.0.0.2", Port: 2222}},
+		{s: "buildbox", wantErr: true},
+		{s: "@buildbox", wantErr: true},
+		{s: "user@", wantErr: true},
+		{s: "user@:22", wantErr: true},
+		{s: "user@buildbox:", wantErr: true},
+		{s: "user@buildbox:0", wantErr: true},
+		{s: "user@buildbox:ssh", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := ParseRemote(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemote(%s) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseRemote(%s) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
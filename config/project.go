@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the name of the project-local config file.
+const ProjectFileName = ".colima.yaml"
+
+// FindProjectFile searches dir and its parent directories for the project-local config file.
+// An empty string is returned if none is found.
+func FindProjectFile(dir string) string {
+	for {
+		file := filepath.Join(dir, ProjectFileName)
+		if stat, err := os.Stat(file); err == nil && !stat.IsDir() {
+			return file
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ProjectProfile returns the profile name defined in the project-local config file.
+// The name of the directory containing the file is used if none is defined.
+func ProjectProfile(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %w", file, err)
+	}
+
+	var p struct {
+		Profile string `yaml:"profile"`
+	}
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return "", fmt.Errorf("could not read %s: %w", file, err)
+	}
+	if p.Profile == "" {
+		return filepath.Base(filepath.Dir(file)), nil
+	}
+	return p.Profile, nil
+}
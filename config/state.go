@@ -0,0 +1,79 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// provisionState is the state of the provisioning steps of the profile, the hashes of the inputs
+// of the steps by name when they last succeeded, with the key of the guest state they were applied to.
+type provisionState struct {
+	// Version is the colima version that recorded the state, steps are not skipped after an upgrade.
+	Version string            `json:"version"`
+	Steps   map[string]string `json:"steps"`
+}
+
+var provisionStateMu sync.Mutex
+
+func provisionStateFile() string { return filepath.Join(Dir(), "provisioned.json") }
+
+// loadProvisionState loads the state of the provisioning steps recorded by the current version.
+func loadProvisionState() provisionState {
+	var s provisionState
+	if b, err := os.ReadFile(provisionStateFile()); err == nil {
+		_ = json.Unmarshal(b, &s)
+	}
+	if s.Version != AppVersion().Version || s.Steps == nil {
+		s = provisionState{Version: AppVersion().Version, Steps: map[string]string{}}
+	}
+	return s
+}
+
+func inputsHash(inputs interface{}) string {
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		// not hashable, never unchanged
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// ProvisionUnchanged returns if the inputs of the provisioning step are unchanged since it last succeeded
+// for the state of the guest identified by key, e.g. the boot id of the VM. It is never unchanged for an empty key.
+func ProvisionUnchanged(step, key string, inputs interface{}) bool {
+	provisionStateMu.Lock()
+	defer provisionStateMu.Unlock()
+
+	hash := inputsHash(inputs)
+	return hash != "" && key != "" && loadProvisionState().Steps[step] == key+":"+hash
+}
+
+// RecordProvision records the inputs of the provisioning step that succeeded for the state of the guest
+// identified by key.
+func RecordProvision(step, key string, inputs interface{}) error {
+	provisionStateMu.Lock()
+	defer provisionStateMu.Unlock()
+
+	s := loadProvisionState()
+	s.Steps[step] = key + ":" + inputsHash(inputs)
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("error encoding provisioning state: %w", err)
+	}
+	return os.WriteFile(provisionStateFile(), b, 0644)
+}
+
+// ResetProvision clears the state of the provisioning steps e.g. when the VM is created.
+func ResetProvision() error {
+	provisionStateMu.Lock()
+	defer provisionStateMu.Unlock()
+
+	if err := os.Remove(provisionStateFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error resetting provisioning state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_inputsHash(t *testing.T) {
+	tests := []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{a: []string{"htop"}, b: []string{"htop"}, equal: true},
+		{a: []string{"htop"}, b: []string{"htop", "git"}},
+		{a: map[string]string{"a": "1", "b": "2"}, b: map[string]string{"b": "2", "a": "1"}, equal: true},
+		{a: nil, b: []string{}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := inputsHash(tt.a) == inputsHash(tt.b); got != tt.equal {
+				t.Errorf("inputsHash(%v) == inputsHash(%v) = %v, want %v", tt.a, tt.b, got, tt.equal)
+			}
+		})
+	}
+
+	// not hashable
+	if got := inputsHash(func() {}); got != "" {
+		t.Errorf("inputsHash(func) = %s, want empty", got)
+	}
+}
+
+func TestProvisionUnchanged(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := RecordProvision("vm.packages", "boot-1", []string{"htop"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordProvision("vm.ntp", "boot-1", []string{"pool.ntp.org"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		step   string
+		key    string
+		inputs interface{}
+		want   bool
+	}{
+		{step: "vm.packages", key: "boot-1", inputs: []string{"htop"}, want: true},
+		{step: "vm.ntp", key: "boot-1", inputs: []string{"pool.ntp.org"}, want: true},
+		{step: "vm.packages", key: "boot-1", inputs: []string{"htop", "git"}},
+		{step: "vm.certs", key: "boot-1", inputs: []string{"htop"}},
+		// another state of the guest, e.g. rebooted
+		{step: "vm.packages", key: "boot-2", inputs: []string{"htop"}},
+		// unknown state of the guest
+		{step: "vm.packages", key: "", inputs: []string{"htop"}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := ProvisionUnchanged(tt.step, tt.key, tt.inputs); got != tt.want {
+				t.Errorf("ProvisionUnchanged(%s, %s) = %v, want %v", tt.step, tt.key, got, tt.want)
+			}
+		})
+	}
+
+	// the steps are keyed independently, e.g. by the boot id and by the instance id
+	if err := RecordProvision("vm.packages", "boot-2", []string{"htop"}); err != nil {
+		t.Fatal(err)
+	}
+	if !ProvisionUnchanged("vm.ntp", "boot-1", []string{"pool.ntp.org"}) {
+		t.Error("ProvisionUnchanged() = false for a step recorded with another key")
+	}
+	if ProvisionUnchanged("vm.packages", "boot-1", []string{"htop"}) {
+		t.Error("ProvisionUnchanged() = true for a step recorded again with another key")
+	}
+
+	if err := ResetProvision(); err != nil {
+		t.Fatal(err)
+	}
+	if ProvisionUnchanged("vm.packages", "boot-2", []string{"htop"}) {
+		t.Error("ProvisionUnchanged() = true after ResetProvision()")
+	}
+}
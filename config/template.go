@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/abiosoft/colima/embedded"
+)
+
+const defaultTemplate = "default"
+
+// Template is a named configuration template.
+type Template struct {
+	Name string
+	// BuiltIn is true for templates shipped with colima.
+	// A user-defined template with the same name takes precedence.
+	BuiltIn bool
+}
+
+// Templates returns the built-in and user-defined templates, sorted by name.
+func Templates() ([]Template, error) {
+	found := map[string]bool{}
+	var templates []Template
+
+	files, err := filepath.Glob(filepath.Join(TemplatesDir(), "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing templates: %w", err)
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		found[name] = true
+		templates = append(templates, Template{Name: name})
+	}
+
+	entries, err := embedded.FS().ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("error listing built-in templates: %w", err)
+	}
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".yaml")
+		if !found[name] {
+			templates = append(templates, Template{Name: name, BuiltIn: true})
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// ReadTemplate returns the content of the named template.
+// A user-defined template takes precedence over a built-in template with the same name.
+func ReadTemplate(name string) ([]byte, error) {
+	if b, err := os.ReadFile(templateFile(name)); err == nil {
+		return b, nil
+	}
+	if b, err := embedded.Read("templates/" + name + ".yaml"); err == nil {
+		return b, nil
+	}
+	return nil, fmt.Errorf("template '%s' not found, run 'colima template list' for available templates", name)
+}
+
+// LoadNamedTemplate loads the named template on top of base,
+// values missing in the template retain the value in base.
+func LoadNamedTemplate(name string, base Config) (Config, error) {
+	b, err := ReadTemplate(name)
+	if err != nil {
+		return Config{}, err
+	}
+	c, err := load(b, TemplatesDir(), base, map[string]bool{})
+	if err != nil {
+		return Config{}, fmt.Errorf("could not load template '%s': %w", name, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,236 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SocketFile returns the default path to the socket of the daemon.
+func SocketFile() string { return filepath.Join(config.AppDir(), "daemon.sock") }
+
+// Error is the response of a failed request.
+type Error struct {
+	Message  string `json:"error"`
+	Hint     string `json:"hint,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+
+	status int
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func newError(status int, format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...), status: status}
+}
+
+// Serve serves the control API on the unix socket until the listener fails.
+// The operations on profiles are run with separate colima processes, concurrent
+// operations on a profile are serialized by the profile lock.
+func Serve(socket string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error retrieving colima executable: %w", err)
+	}
+
+	// stale socket from a previous run
+	_ = os.Remove(socket)
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", socket, err)
+	}
+	defer l.Close()
+	// only accessible to the user
+	if err := os.Chmod(socket, 0600); err != nil {
+		return fmt.Errorf("error setting socket permissions: %w", err)
+	}
+
+	s := &server{executable: executable}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/version", s.handle(http.MethodGet, s.version))
+	mux.HandleFunc("/v1/profiles", s.handle(http.MethodGet, s.list))
+	mux.HandleFunc("/v1/profiles/", s.profile)
+
+	log.Println("serving API on", socket)
+	return http.Serve(l, mux)
+}
+
+type server struct {
+	executable string
+}
+
+type handlerFunc func(r *http.Request) (interface{}, error)
+
+// handle writes the response of h as JSON, for requests with the method.
+func (s *server) handle(method string, h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeJSON(w, http.StatusMethodNotAllowed, newError(http.StatusMethodNotAllowed, "method %s not allowed", r.Method))
+			return
+		}
+		resp, err := h(r)
+		if err != nil {
+			var e *Error
+			if !errors.As(err, &e) {
+				e = &Error{Message: err.Error()}
+			}
+			if e.status == 0 {
+				e.status = http.StatusInternalServerError
+			}
+			log.Warnln(r.Method, r.URL.Path, e.Message)
+			writeJSON(w, e.status, e)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *server) version(*http.Request) (interface{}, error) {
+	v := config.AppVersion()
+	return map[string]string{"version": v.Version, "revision": v.Revision}, nil
+}
+
+func (s *server) list(*http.Request) (interface{}, error) {
+	instances, err := lima.Instances()
+	if err != nil {
+		return nil, err
+	}
+	if instances == nil {
+		instances = []lima.InstanceInfo{}
+	}
+	return instances, nil
+}
+
+// profile routes the requests for a profile.
+//
+//	GET  /v1/profiles/{name}/status
+//	POST /v1/profiles/{name}/start
+//	POST /v1/profiles/{name}/stop[?force=true]
+//	GET  /v1/profiles/{name}/config
+//	PUT  /v1/profiles/{name}/config/{key}, the value is the request body
+func (s *server) profile(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/profiles/"), "/")
+	if len(parts) < 2 || parts[0] == "" {
+		writeJSON(w, http.StatusNotFound, newError(http.StatusNotFound, "not found"))
+		return
+	}
+	name, action := parts[0], parts[1]
+
+	switch {
+	case action == "status" && len(parts) == 2:
+		s.handle(http.MethodGet, func(*http.Request) (interface{}, error) { return s.status(name) })(w, r)
+	case action == "start" && len(parts) == 2:
+		s.handle(http.MethodPost, func(*http.Request) (interface{}, error) { return s.run(name, "start") })(w, r)
+	case action == "stop" && len(parts) == 2:
+		s.handle(http.MethodPost, func(r *http.Request) (interface{}, error) {
+			if r.URL.Query().Get("force") == "true" {
+				return s.run(name, "stop", "--force")
+			}
+			return s.run(name, "stop")
+		})(w, r)
+	case action == "config" && len(parts) == 2:
+		s.handle(http.MethodGet, func(*http.Request) (interface{}, error) { return getConfig(name) })(w, r)
+	case action == "config" && len(parts) == 3:
+		s.handle(http.MethodPut, func(r *http.Request) (interface{}, error) {
+			value, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, newError(http.StatusBadRequest, "error reading value: %v", err)
+			}
+			return s.run(name, "config", "set", parts[2], strings.TrimSpace(string(value)))
+		})(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, newError(http.StatusNotFound, "not found"))
+	}
+}
+
+// status returns the status of the profile, as printed by 'colima status --json'.
+func (s *server) status(name string) (interface{}, error) {
+	out, err := s.colima(name, "status", "--json")
+	// the status is printed for stopped profiles, with a non-zero exit code
+	if len(out) > 0 && json.Valid(out) {
+		return json.RawMessage(out), nil
+	}
+	return nil, err
+}
+
+// run runs the colima command for the profile.
+func (s *server) run(name string, args ...string) (interface{}, error) {
+	if _, err := s.colima(name, args...); err != nil {
+		return nil, err
+	}
+	return map[string]string{"profile": name}, nil
+}
+
+// colima runs colima with args for the profile and returns the output.
+func (s *server) colima(name string, args ...string) ([]byte, error) {
+	args = append(args, "--profile", name, "--log-format", "json")
+	cmd := cli.Command(s.executable, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), commandError(err, stderr.Bytes())
+	}
+	return stdout.Bytes(), nil
+}
+
+// commandError returns the error logged by the colima process.
+func commandError(err error, stderr []byte) *Error {
+	e := &Error{Message: err.Error()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		e.ExitCode = exitErr.ExitCode()
+	}
+	for _, line := range bytes.Split(stderr, []byte("\n")) {
+		var entry struct {
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+			Hint  string `json:"hint"`
+		}
+		if json.Unmarshal(line, &entry) != nil {
+			continue
+		}
+		// the last error is the cause of the failure
+		if entry.Level == "error" || entry.Level == "fatal" {
+			e.Message, e.Hint = entry.Msg, entry.Hint
+		}
+	}
+	return e
+}
+
+// getConfig returns the saved config of the profile with the keys of the config file.
+func getConfig(name string) (interface{}, error) {
+	conf, err := config.LoadProfile(name)
+	if err != nil || conf.Empty() {
+		return nil, newError(http.StatusNotFound, "profile '%s' has no configuration", name)
+	}
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	var v map[string]interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,9 @@
+#!/sbin/openrc-run
+
+description="Colima watchdog for the container runtime and Kubernetes services"
+
+command="/usr/local/bin/colima-watchdog"
+command_background=true
+pidfile="/run/${RC_SVCNAME}.pid"
+output_log="/var/log/${RC_SVCNAME}.log"
+error_log="/var/log/${RC_SVCNAME}.log"
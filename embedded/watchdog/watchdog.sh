@@ -0,0 +1,57 @@
+#!/bin/sh
+
+# restarts the container runtime and Kubernetes services found crashed, and resyncs
+# the clock when it drifts from the hardware clock e.g. after the host sleeps.
+# the events are logged as "<unix time> <event> [service]", a service is given up on
+# after 5 restart attempts within 10 minutes.
+
+interval="${WATCHDOG_INTERVAL:-10}"
+max_drift="${WATCHDOG_MAX_DRIFT:-5}"
+services="docker containerd buildkitd k3s k3s-agent"
+rtc=/sys/class/rtc/rtc0/since_epoch
+
+log() { echo "$(date +%s) $*"; }
+
+recent_restarts() {
+    since=$(($(date +%s) - 600))
+    awk -v since="$since" -v service="$1" '$1 >= since && ($2 == "restarted" || $2 == "failed") && $3 == service' /var/log/colima-watchdog.log 2>/dev/null | wc -l
+}
+
+# the guest clock stops while the VM is suspended with the host,
+# the hardware clock is provided by the host and keeps up.
+sync_clock() {
+    [ -r "$rtc" ] || return 0
+    drift=$(($(cat "$rtc") - $(date +%s)))
+    [ "${drift#-}" -gt "$max_drift" ] || return 0
+    if hwclock --hctosys --utc; then
+        log clock-synced "${drift}s"
+    else
+        log clock-sync-failed "${drift}s"
+    fi
+}
+
+log started
+
+while true; do
+    sync_clock
+
+    for service in $services; do
+        [ -e "/etc/init.d/$service" ] || continue
+
+        # exit code 32 is crashed, started then died without being stopped
+        rc-service --quiet "$service" status >/dev/null 2>&1
+        [ $? -eq 32 ] || continue
+
+        if [ "$(recent_restarts "$service")" -ge 5 ]; then
+            continue
+        fi
+
+        log crashed "$service"
+        if rc-service "$service" restart; then
+            log restarted "$service"
+        else
+            log failed "$service"
+        fi
+    done
+    sleep "$interval"
+done
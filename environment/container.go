@@ -1,8 +1,16 @@
 package environment
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/util"
 )
 
 // Container is container environment.
@@ -11,11 +19,14 @@ type Container interface {
 	Name() string
 	// Provision provisions/installs the container runtime.
 	// Should be idempotent.
-	Provision() error
-	// Start starts the container runtime.
-	Start() error
+	// The config (when available) is accessible via ctx with config.CtxKey.
+	Provision(ctx context.Context) error
+	// Start starts the container runtime and waits for it to become ready.
+	// The readiness timeout of the config is accessible via ctx.
+	Start(ctx context.Context) error
 	// Stop stops the container runtime.
-	Stop() error
+	// Running containers are given the config's shutdown timeout (accessible via ctx) to stop gracefully.
+	Stop(ctx context.Context) error
 	// Teardown tears down/uninstall the container runtime.
 	Teardown() error
 	// Version returns the container runtime version.
@@ -26,6 +37,14 @@ type Container interface {
 	Dependencies
 }
 
+// Prefetcher is implemented by the container runtimes that download artifacts when provisioned.
+// The artifacts are prefetched into the cache of the host while the VM boots.
+type Prefetcher interface {
+	// Prefetch downloads the artifacts into the cache of the host, the VM may not be running.
+	// The config is accessible via ctx with config.CtxKey.
+	Prefetch(ctx context.Context) error
+}
+
 // NewContainer creates a new container environment.
 func NewContainer(runtime string, host HostActions, guest GuestActions) (Container, error) {
 	if _, ok := containerRuntimes[runtime]; !ok {
@@ -60,3 +79,85 @@ func ContainerRuntimes() (names []string) {
 	}
 	return
 }
+
+// logTailLines is the number of log lines included in readiness errors.
+const logTailLines = 20
+
+// WithLogTail appends the last lines of the log files in the guest to err if err is a readiness timeout.
+func WithLogTail(guest GuestActions, err error, files ...string) error {
+	if !errors.Is(err, cli.ErrTimeout) {
+		return err
+	}
+	args := append([]string{"sudo", "tail", "-n", strconv.Itoa(logTailLines)}, files...)
+	out, tailErr := guest.RunOutput(args...)
+	if tailErr != nil || out == "" {
+		return err
+	}
+	return fmt.Errorf("%w\n\nlast lines of %s:\n%s", err, strings.Join(files, ", "), out)
+}
+
+// the markers of the section of the service config in the VM managed by colima.
+const (
+	serviceEnvBegin = "# BEGIN colima env"
+	serviceEnvEnd   = "# END colima env"
+)
+
+// DaemonEnv returns the environment of the container runtime daemons with the variables of env,
+// LANG defaults to the UTF-8 locale of the host.
+func DaemonEnv(env map[string]string) map[string]string {
+	e := map[string]string{"LANG": util.HostLocale()}
+	for k, v := range env {
+		e[k] = v
+	}
+	return e
+}
+
+// SetServiceEnv sets the environment variables of the service in the VM, replacing the variables
+// previously set. changed is true if the variables differ, the service must then be restarted to apply them.
+// The variables are set in /etc/conf.d for OpenRC, and in a drop-in of the unit for systemd.
+func SetServiceEnv(guest GuestActions, service string, env map[string]string) (changed bool, err error) {
+	if usesSystemd(guest) {
+		return setSystemdEnv(guest, service, env)
+	}
+
+	file := "/etc/conf.d/" + service
+	current, _ := guest.RunOutput("cat", file)
+
+	// the config without the section
+	var lines, previous []string
+	inSection := false
+	for _, line := range strings.Split(current, "\n") {
+		switch {
+		case line == serviceEnvBegin:
+			inSection = true
+		case line == serviceEnvEnd:
+			inSection = false
+		case inSection:
+			previous = append(previous, line)
+		default:
+			lines = append(lines, line)
+		}
+	}
+
+	var section []string
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		section = append(section, "export "+k+"="+util.ShellQuote(env[k]))
+	}
+	if strings.Join(section, "\n") == strings.Join(previous, "\n") {
+		return false, nil
+	}
+
+	body := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if len(section) > 0 {
+		body += "\n" + serviceEnvBegin + "\n" + strings.Join(section, "\n") + "\n" + serviceEnvEnd
+	}
+	if err := WriteFile(guest, file, 0644, strings.TrimLeft(body, "\n")+"\n"); err != nil {
+		return false, fmt.Errorf("error setting %s environment: %w", service, err)
+	}
+	return true, nil
+}
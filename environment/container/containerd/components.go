@@ -0,0 +1,91 @@
+package containerd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util/downloader"
+)
+
+// channelKey is the settings key for the channel of the installed components.
+const channelKey = "containerd_channel"
+
+// versions of the components for the edge channel, the stable channel uses the versions of the VM image.
+const (
+	edgeNerdctlVersion  = "0.17.1"
+	edgeBuildkitVersion = "0.10.0"
+)
+
+// componentChecksums are the pinned sha256 checksums of the archives of the edge components by file name,
+// for each architecture. Updated with the versions with scripts/checksums.sh.
+// An archive without a pinned checksum is not verified, a warning is logged by the download.
+var componentChecksums = map[string]string{}
+
+// component is a release archive of binaries that replace the binaries of the VM image.
+type component struct {
+	url      string
+	binaries []string
+}
+
+func edgeComponents(arch environment.Arch) []component {
+	goarch := arch.GoArch()
+	return []component{
+		{
+			url:      fmt.Sprintf("https://github.com/containerd/nerdctl/releases/download/v%[1]s/nerdctl-%[1]s-linux-%s.tar.gz", edgeNerdctlVersion, goarch),
+			binaries: []string{"nerdctl"},
+		},
+		{
+			url:      fmt.Sprintf("https://github.com/moby/buildkit/releases/download/v%[1]s/buildkit-v%[1]s.linux-%s.tar.gz", edgeBuildkitVersion, goarch),
+			binaries: []string{"bin/buildkitd", "bin/buildctl"},
+		},
+	}
+}
+
+// applyChannel installs the components of the channel, it returns if they changed.
+// The components of the VM image are not restored when switching back to stable.
+func (c containerdRuntime) applyChannel(channel string) (changed bool, err error) {
+	current := c.guest.Get(channelKey)
+	if channel != config.ChannelEdge {
+		if current == config.ChannelEdge {
+			c.Logger().Warnln("the edge components remain installed until the VM is recreated")
+		}
+		return false, nil
+	}
+
+	inputs := []string{edgeNerdctlVersion, edgeBuildkitVersion}
+	err = environment.ProvisionedBoot(c.guest, "containerd.channel", inputs, func() error {
+		c.Logger().Println("installing nerdctl", edgeNerdctlVersion, "and buildkit", edgeBuildkitVersion)
+		for _, comp := range edgeComponents(c.guest.Arch()) {
+			if err := c.installComponent(comp); err != nil {
+				return err
+			}
+		}
+		changed = true
+		return c.guest.Set(channelKey, channel)
+	})()
+	return changed, err
+}
+
+// installComponent replaces the binaries of the VM image with the binaries in the archive of comp.
+func (c containerdRuntime) installComponent(comp component) error {
+	archive := "/tmp/colima-component.tar.gz"
+	dir := "/tmp/colima-component"
+	if err := downloader.DownloadArtifact(c.host, c.guest, comp.url, componentChecksums[path.Base(comp.url)], archive); err != nil {
+		return err
+	}
+	defer func() { _ = c.guest.RunQuiet("rm", "-rf", archive, dir) }()
+
+	if err := c.guest.RunQuiet("sh", "-c", fmt.Sprintf("mkdir -p %s && tar -C %s -xzf %s", dir, dir, archive)); err != nil {
+		return fmt.Errorf("error extracting '%s': %w", comp.url, err)
+	}
+	for _, binary := range comp.binaries {
+		// in place of the binary of the VM image
+		script := fmt.Sprintf(`install -m 755 %s/%s "$(dirname "$(command -v %s)")"`, dir, binary, path.Base(binary))
+		if err := c.guest.RunQuiet("sudo", "sh", "-c", script); err != nil {
+			return fmt.Errorf("error installing %s: %w", binary, err)
+		}
+	}
+	return nil
+}
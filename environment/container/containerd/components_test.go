@@ -0,0 +1,33 @@
+package containerd
+
+import (
+	"path"
+	"regexp"
+	"testing"
+
+	"github.com/abiosoft/colima/environment"
+)
+
+func Test_componentChecksums(t *testing.T) {
+	if len(componentChecksums) == 0 {
+		// the downloads are not verified until pinned with scripts/checksums.sh
+		t.Logf("no pinned checksums for nerdctl %s and buildkit %s", edgeNerdctlVersion, edgeBuildkitVersion)
+		return
+	}
+	sha256Pattern := regexp.MustCompile(`^[0-9a-f]{64}$`)
+	names := map[string]bool{}
+	for _, arch := range []environment.Arch{environment.X8664, environment.AARCH64} {
+		for _, comp := range edgeComponents(arch) {
+			name := path.Base(comp.url)
+			names[name] = true
+			if !sha256Pattern.MatchString(componentChecksums[name]) {
+				t.Errorf("invalid or missing pinned checksum for '%s': '%s'", name, componentChecksums[name])
+			}
+		}
+	}
+	for name := range componentChecksums {
+		if !names[name] {
+			t.Errorf("pinned checksum for '%s' that is not an edge component", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package containerd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/environment"
+)
+
+const (
+	configFile = "/etc/containerd/config.toml"
+	// configFileBackup is the original config of the VM, the snippets of the config are appended to.
+	configFileBackup = configFile + ".colima"
+)
+
+// setupConfig appends the TOML snippets to the original containerd config in the VM.
+// The config is validated by containerd before it is applied.
+// changed is true if the config differs, the service must then be restarted to apply it.
+func (c containerdRuntime) setupConfig(snippets []string) (changed bool, err error) {
+	// keep the original config, if not previously done
+	if c.guest.RunQuiet("stat", configFileBackup) != nil {
+		script := fmt.Sprintf("mkdir -p %s && touch %s && cp %s %s",
+			filepath.Dir(configFile), configFile, configFile, configFileBackup)
+		if err := c.guest.RunQuiet("sudo", "sh", "-c", script); err != nil {
+			return false, fmt.Errorf("error backing up containerd config: %w", err)
+		}
+	}
+
+	original, err := c.guest.RunOutput("sudo", "cat", configFileBackup)
+	if err != nil {
+		return false, fmt.Errorf("error reading containerd config: %w", err)
+	}
+	body := original
+	if len(snippets) > 0 {
+		body += "\n\n# containerd_config of the colima config\n" + strings.Join(snippets, "\n")
+	}
+	body = strings.TrimSpace(body)
+
+	current, _ := c.guest.RunOutput("sudo", "cat", configFile)
+	if current == body {
+		return false, nil
+	}
+
+	// validated before it replaces the config
+	tmpFile := "/tmp/colima-containerd.toml"
+	if err := environment.WriteFile(c.guest, tmpFile, 0600, body+"\n"); err != nil {
+		return false, err
+	}
+	defer func() { _ = c.guest.RunQuiet("sudo", "rm", "-f", tmpFile) }()
+	validate := fmt.Sprintf("containerd --config %s config dump > /dev/null", tmpFile)
+	if err := c.guest.Run("sudo", "sh", "-c", validate); err != nil {
+		return false, fmt.Errorf("invalid containerd_config: %w", err)
+	}
+	if err := c.guest.RunQuiet("sudo", "install", "-m", "644", tmpFile, configFile); err != nil {
+		return false, fmt.Errorf("error applying containerd config: %w", err)
+	}
+	return true, nil
+}
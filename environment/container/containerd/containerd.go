@@ -1,9 +1,12 @@
 package containerd
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment"
 )
 
@@ -38,12 +41,48 @@ func (c containerdRuntime) Name() string {
 	return Name
 }
 
-func (c containerdRuntime) Provision() error {
-	// already provisioned as part of Lima
-	return nil
+func (c containerdRuntime) Provision(ctx context.Context) error {
+	// already provisioned as part of Lima, only the config and the environment of the daemons are set.
+	// buildkitd pulls the images of the builds and requires the same environment e.g. proxies.
+	conf := config.FromContext(ctx)
+	// the new buildkitd is started during start
+	if changed, err := c.applyChannel(conf.Channel); err != nil {
+		return err
+	} else if changed && c.guest.RunQuiet("service", "buildkitd", "status") == nil {
+		if err := c.guest.RunQuiet("sudo", "service", "buildkitd", "stop"); err != nil {
+			return err
+		}
+	}
+	daemonEnv := environment.DaemonEnv(conf.ContainerdEnv)
+	inputs := []interface{}{conf.ContainerdConfig, daemonEnv, conf.Registry.Auths}
+	return environment.ProvisionedBoot(c.guest, "containerd.config", inputs, func() error {
+		if err := environment.SetRegistryAuths(c.guest, conf.Registry.Auths); err != nil {
+			return err
+		}
+		configChanged, err := c.setupConfig(conf.ContainerdConfig)
+		if err != nil {
+			return err
+		}
+		for _, service := range []string{"containerd", "buildkitd"} {
+			changed, err := environment.SetServiceEnv(c.guest, service, daemonEnv)
+			if err != nil {
+				return err
+			}
+			if service == "containerd" {
+				changed = changed || configChanged
+			}
+			// stop now, start will be done during start
+			if changed && c.guest.RunQuiet("service", service, "status") == nil {
+				if err := c.guest.RunQuiet("sudo", "service", service, "stop"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})()
 }
 
-func (c containerdRuntime) Start() error {
+func (c containerdRuntime) Start(ctx context.Context) error {
 	a := c.Init()
 
 	a.Stage("starting")
@@ -54,21 +93,32 @@ func (c containerdRuntime) Start() error {
 		return c.guest.Run("sudo", "service", "buildkitd", "start")
 	})
 
-	// service startup takes few seconds, retry at most 10 times before giving up.
-	a.Retry("waiting for startup to complete", time.Second*5, 10, func() error {
+	// service startup takes few seconds.
+	timeout := config.FromContext(ctx).WaitTimeout.Duration(Name)
+	a.Wait("waiting for startup to complete", time.Second*5, timeout, func() error {
 		return c.guest.RunQuiet("sudo", "nerdctl", "info")
 	})
 
-	return a.Exec()
+	return environment.WithLogTail(c.guest, a.Exec(), "/var/log/containerd.log", "/var/log/buildkitd.log")
 }
 
 func (c containerdRuntime) Running() bool {
 	return c.guest.RunQuiet("service", "containerd", "status") == nil
 }
 
-func (c containerdRuntime) Stop() error {
+func (c containerdRuntime) Stop(ctx context.Context) error {
 	a := c.Init()
 	a.Stage("stopping")
+
+	timeout := config.FromContext(ctx).VM.ShutdownDuration()
+	a.Add(func() error {
+		// give running containers the grace period to stop
+		stop := fmt.Sprintf(`ids="$(nerdctl ps -q)"; [ -z "$ids" ] || nerdctl stop -t %d $ids`, int(timeout.Seconds()))
+		if err := c.guest.RunQuiet("sudo", "sh", "-c", stop); err != nil {
+			c.Logger().Warnln(fmt.Errorf("error stopping containers: %w", err))
+		}
+		return nil
+	})
 	a.Add(func() error {
 		return c.guest.Run("sudo", "service", "containerd", "stop")
 	})
@@ -3,6 +3,7 @@ package docker
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/abiosoft/colima/config"
 )
@@ -10,24 +11,60 @@ import (
 // HostSocketFile returns the path to the docker socket on host.
 func HostSocketFile() string { return filepath.Join(config.Dir(), "docker.sock") }
 
+// VMSocketFile returns the path to the docker socket forwarded from the VM
+// when the host socket is proxied by the idle monitor.
+func VMSocketFile() string { return filepath.Join(config.Dir(), "docker.vm.sock") }
+
+// Host returns the docker host of the context for conf, the socket forwarded from the VM or the VM over ssh.
+// The ssh host is the profile ID, resolved by the config installed with 'colima ssh-config --install',
+// or the remote host.
+func Host(conf config.Config) string {
+	if conf.DockerTransport == config.DockerTransportSSH {
+		if conf.Remote != "" {
+			return "ssh://" + conf.Remote
+		}
+		return "ssh://" + config.Profile().ID
+	}
+	return "unix://" + HostSocketFile()
+}
+
 func (d dockerRuntime) isContextCreated() bool {
 	command := fmt.Sprintf(`docker context ls -q | grep "^%s$"`, config.Profile().ID)
 	return d.host.RunQuiet("sh", "-c", command) == nil
 }
 
-func (d dockerRuntime) setupContext() error {
-	if d.isContextCreated() {
-		return nil
+func (d dockerRuntime) setupContext(conf config.Config) error {
+	host := Host(conf)
+	if conf.DockerTransport == config.DockerTransportSSH && conf.Remote == "" && !d.isSSHHostResolved() {
+		d.Logger().Warnln("the ssh host " + config.Profile().ID + " is not configured, run 'colima ssh-config --install' for the docker context")
 	}
 
 	profile := config.Profile()
+	if d.isContextCreated() {
+		// the transport may have changed
+		return d.host.Run("docker", "context", "update", profile.ID, "--docker", "host="+host)
+	}
 
 	return d.host.Run("docker", "context", "create", profile.ID,
 		"--description", profile.DisplayName,
-		"--docker", "host=unix://"+HostSocketFile(),
+		"--docker", "host="+host,
 	)
 }
 
+// isSSHHostResolved returns if ssh resolves the host of the profile to the VM, ssh resolves unknown hosts to themselves.
+func (d dockerRuntime) isSSHHostResolved() bool {
+	out, err := d.host.RunOutput("ssh", "-G", config.Profile().ID)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if option := strings.Fields(line); len(option) == 2 && option[0] == "hostname" {
+			return option[1] != config.Profile().ID
+		}
+	}
+	return false
+}
+
 func (d dockerRuntime) useContext() error {
 	return d.host.Run("docker", "context", "use", config.Profile().ID)
 }
@@ -1,9 +1,12 @@
 package docker
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment"
 )
 
@@ -40,7 +43,7 @@ func (d dockerRuntime) isUserPermissionFixed() bool {
 	return err == nil
 }
 
-func (d dockerRuntime) Provision() error {
+func (d dockerRuntime) Provision(ctx context.Context) error {
 	a := d.Init()
 	a.Stage("provisioning")
 
@@ -56,46 +59,88 @@ func (d dockerRuntime) Provision() error {
 		a.Add(d.createDaemonFile)
 	}
 
-	// daemon.json
-	a.Add(d.setupDaemonFile)
-
-	// docker context
-	a.Add(d.setupContext)
-	a.Add(d.useContext)
+	conf := config.FromContext(ctx)
+	daemonBody, _ := d.host.Read(daemonFile())
+	daemonEnv := environment.DaemonEnv(conf.DockerEnv)
+	daemonInputs := []interface{}{daemonBody, conf.Registry, daemonEnv, conf.DockerUlimits, conf.DockerShmSize}
+	daemon := environment.ProvisionedBoot(d.guest, "docker.daemon", daemonInputs, func() error {
+		// daemon environment, applied by the restart of the daemon.json setup
+		if _, err := environment.SetServiceEnv(d.guest, "docker", daemonEnv); err != nil {
+			return err
+		}
+		if err := environment.SetRegistryAuths(d.guest, conf.Registry.Auths); err != nil {
+			return err
+		}
+		return d.setupDaemonFile(conf)
+	})
+	if !conf.DaemonManaged() {
+		// the daemon of a remote host, that may be shared, is used as is
+		daemon = func() error {
+			registry := len(conf.Registry.Mirrors) + len(conf.Registry.Insecure) + len(conf.Registry.Auths)
+			if len(conf.DockerEnv) > 0 || registry > 0 || len(conf.DockerUlimits) > 0 || conf.DockerShmSize != "" {
+				d.Logger().Warnln("the docker settings are not applied to the remote host, start with --remote-manage for colima to manage its daemon")
+			}
+			return nil
+		}
+	}
+	a.Parallel(
+		// the daemon in the VM
+		daemon,
+		// docker context on the host
+		func() error { return d.setupContext(conf) },
+	)
+	if cli.Settings.CI {
+		// the active context of the host is left as is, DOCKER_HOST is used instead.
+		d.Logger().Println("CI mode: docker context not switched, set DOCKER_HOST=" + Host(conf))
+	} else {
+		a.Add(d.useContext)
+	}
 
 	return a.Exec()
 }
 
-func (d dockerRuntime) Start() error {
+func (d dockerRuntime) Start(ctx context.Context) error {
 	a := d.Init()
 
 	a.Stage("starting")
 
 	a.Add(func() error {
-		return d.guest.Run("sudo", "service", "docker", "start")
+		return d.guest.Run(environment.ServiceCmd(d.guest, "docker", "start")...)
 	})
 
-	// service startup takes few seconds, retry at most 5 times before giving up.
-	a.Retry("waiting for startup to complete", time.Second*5, 10, func() error {
+	// service startup takes few seconds.
+	timeout := config.FromContext(ctx).WaitTimeout.Duration(Name)
+	a.Wait("waiting for startup to complete", time.Second*5, timeout, func() error {
 		return d.guest.RunQuiet("sudo", "docker", "info")
 	})
 
-	return a.Exec()
+	return environment.WithLogTail(d.guest, a.Exec(), "/var/log/docker.log")
 }
 
 func (d dockerRuntime) Running() bool {
-	return d.guest.RunQuiet("service", "docker", "status") == nil
+	return d.guest.RunQuiet(environment.ServiceCmd(d.guest, "docker", "status")...) == nil
 }
 
-func (d dockerRuntime) Stop() error {
+func (d dockerRuntime) Stop(ctx context.Context) error {
 	a := d.Init()
 	a.Stage("stopping")
 
+	conf := config.FromContext(ctx)
+	timeout := conf.VM.ShutdownDuration()
 	a.Add(func() error {
 		if !d.Running() {
 			return nil
 		}
-		return d.guest.Run("sudo", "service", "docker", "stop")
+		if !conf.DaemonManaged() {
+			// the daemon and the containers of a remote host, that may be shared, are left running
+			return nil
+		}
+		// give running containers the grace period to stop
+		stop := fmt.Sprintf(`ids="$(docker ps -q)"; [ -z "$ids" ] || docker stop -t %d $ids`, int(timeout.Seconds()))
+		if err := d.guest.RunQuiet("sudo", "sh", "-c", stop); err != nil {
+			d.Logger().Warnln(fmt.Errorf("error stopping containers: %w", err))
+		}
+		return d.guest.Run(environment.ServiceCmd(d.guest, "docker", "stop")...)
 	})
 
 	return a.Exec()
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
 )
 
 func (d dockerRuntime) fixUserPermission() error {
@@ -56,7 +57,83 @@ func (d dockerRuntime) createDaemonFile() error {
 	return d.host.Write(fileName, string(b))
 }
 
-func (d dockerRuntime) setupDaemonFile() error {
+// mergeRegistry merges the registry config into the daemon.json content.
+func mergeRegistry(daemonFileContent string, conf config.Registry) (string, error) {
+	if len(conf.Mirrors) == 0 && len(conf.Insecure) == 0 {
+		return daemonFileContent, nil
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(daemonFileContent), &obj); err != nil {
+		return "", fmt.Errorf("error decoding daemon.json: %w", err)
+	}
+
+	merge := func(key string, values []string) {
+		existing, _ := obj[key].([]interface{})
+		seen := map[string]bool{}
+		for _, v := range existing {
+			if s, ok := v.(string); ok {
+				seen[s] = true
+			}
+		}
+		for _, v := range values {
+			if !seen[v] {
+				existing = append(existing, v)
+				seen[v] = true
+			}
+		}
+		if len(existing) > 0 {
+			obj[key] = existing
+		}
+	}
+	merge("registry-mirrors", conf.Mirrors)
+	merge("insecure-registries", conf.Insecure)
+
+	b, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding daemon.json: %w", err)
+	}
+	return string(b), nil
+}
+
+// mergeLimits merges the default ulimits and shm size of the containers into the daemon.json content.
+// The limits of the config take precedence over the limits in daemon.json.
+func mergeLimits(daemonFileContent string, conf config.Config) (string, error) {
+	if len(conf.DockerUlimits) == 0 && conf.DockerShmSize == "" {
+		return daemonFileContent, nil
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(daemonFileContent), &obj); err != nil {
+		return "", fmt.Errorf("error decoding daemon.json: %w", err)
+	}
+
+	if len(conf.DockerUlimits) > 0 {
+		ulimits, _ := obj["default-ulimits"].(map[string]interface{})
+		if ulimits == nil {
+			ulimits = map[string]interface{}{}
+		}
+		for name, value := range conf.DockerUlimits {
+			u, err := config.ParseUlimit(name, value)
+			if err != nil {
+				return "", err
+			}
+			ulimits[name] = u
+		}
+		obj["default-ulimits"] = ulimits
+	}
+	if conf.DockerShmSize != "" {
+		obj["default-shm-size"] = conf.DockerShmSize
+	}
+
+	b, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding daemon.json: %w", err)
+	}
+	return string(b), nil
+}
+
+func (d dockerRuntime) setupDaemonFile(conf config.Config) error {
 	log := d.Logger()
 
 	daemonFile := daemonFile()
@@ -69,25 +146,27 @@ func (d dockerRuntime) setupDaemonFile() error {
 		}
 	}
 
-	daemonFileInVM := filepath.Join(config.CacheDir(), "daemon.json")
-
-	// copy to vm, cache directory is shared by host and vm and guaranteed to be mounted.
-	if err := d.host.RunQuiet("cp", daemonFile, daemonFileInVM); err != nil {
-		return fmt.Errorf("error copying daemon.json to VM: %w", err)
+	// registry config and limits are merged into the copy for the VM, leaving the user's daemon.json untouched.
+	body, err := d.host.Read(daemonFile)
+	if err != nil {
+		return fmt.Errorf("error reading daemon.json: %w", err)
 	}
-
-	// copy to location in VM
-	if err := d.guest.RunQuiet("sudo", "mkdir", "-p", "/etc/docker"); err != nil {
-		return fmt.Errorf("error setting up default config: %w", err)
+	body, err = mergeRegistry(body, conf.Registry)
+	if err != nil {
+		return err
+	}
+	body, err = mergeLimits(body, conf)
+	if err != nil {
+		return err
 	}
 
-	if err := d.guest.RunQuiet("sudo", "cp", daemonFileInVM, "/etc/docker/daemon.json"); err != nil {
+	if err := environment.WriteFile(d.guest, "/etc/docker/daemon.json", 0644, body); err != nil {
 		return fmt.Errorf("error copying daemon.json: %w", err)
 	}
 
 	// config changed, restart is a must. stop now, start will be done during start
 	if d.Running() {
-		return d.guest.RunQuiet("sudo", "service", "docker", "stop")
+		return d.guest.RunQuiet(environment.ServiceCmd(d.guest, "docker", "stop")...)
 	}
 
 	return nil
@@ -0,0 +1,138 @@
+package kubernetes
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util/downloader"
+	"github.com/sirupsen/logrus"
+)
+
+// artifactSource fetches k3s release artifacts.
+// The artifacts are downloaded, unless a local airgap directory is set.
+type artifactSource struct {
+	// airgap directory
+	dir     string
+	version string
+	arch    string
+}
+
+func newArtifactSource(airgapPath, k3sVersion string, arch environment.Arch) artifactSource {
+	return artifactSource{dir: airgapPath, version: k3sVersion, arch: arch.GoArch()}
+}
+
+func (s artifactSource) checksumFile() string {
+	return filepath.Join(s.dir, "sha256sum-"+s.arch+".txt")
+}
+
+// checksums parses the sha256sum file of the airgap directory.
+func (s artifactSource) checksums() (map[string]string, error) {
+	return parseChecksums(s.checksumFile())
+}
+
+// parseChecksums parses the sha256sum file published with k3s releases.
+func parseChecksums(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening checksum file: %w", err)
+	}
+	defer f.Close()
+
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sums[fields[1]] = fields[0]
+	}
+	return sums, scanner.Err()
+}
+
+// expected returns the checksum of the artifact file of the airgap directory.
+// install.sh is not part of the published checksums, it is verified by the pinned checksum if any.
+func (s artifactSource) expected(name string) (string, error) {
+	if name == "install.sh" {
+		return pinnedChecksum(s.version, name), nil
+	}
+
+	sums, err := s.checksums()
+	if err != nil {
+		return "", err
+	}
+	expected, ok := sums[name]
+	if !ok {
+		return "", fmt.Errorf("checksum for '%s' not found in %s", name, s.checksumFile())
+	}
+	return expected, nil
+}
+
+// verify validates the checksum of the artifact file.
+func (s artifactSource) verify(name string) error {
+	expected, err := s.expected(name)
+	if err != nil {
+		return err
+	}
+	if expected == "" {
+		logrus.Warnf("'%s' is not verified, there is no pinned checksum for k3s %s", name, s.version)
+		return nil
+	}
+
+	actual, err := downloader.SHA256File(filepath.Join(s.dir, name))
+	if err != nil {
+		return fmt.Errorf("error computing checksum for '%s': %w", name, err)
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for '%s': expected %s, got %s", name, expected, actual)
+	}
+	return nil
+}
+
+// releaseChecksum returns the checksum of the artifact at url published with the k3s release.
+// The published checksum file is verified by its pinned checksum, as is install.sh that is not part of it.
+func (s artifactSource) releaseChecksum(host environment.HostActions, url string) (string, error) {
+	name := path.Base(url)
+	if name == "install.sh" {
+		return pinnedChecksum(s.version, name), nil
+	}
+
+	// the checksum file is itself a cached release artifact
+	sumsName := "sha256sum-" + s.arch + ".txt"
+	file, err := downloader.FetchArtifact(host, strings.TrimSuffix(url, name)+sumsName, pinnedChecksum(s.version, sumsName))
+	if err != nil {
+		return "", err
+	}
+	sums, err := parseChecksums(file)
+	if err != nil {
+		return "", err
+	}
+	checksum, ok := sums[name]
+	if !ok {
+		return "", fmt.Errorf("checksum for '%s' not found in the k3s release", name)
+	}
+	return checksum, nil
+}
+
+// Fetch retrieves the artifact at url and saves it to fileName on the guest.
+func (s artifactSource) Fetch(host environment.HostActions, guest environment.GuestActions, url, fileName string) error {
+	if s.dir == "" {
+		checksum, err := s.releaseChecksum(host, url)
+		if err != nil {
+			return err
+		}
+		return downloader.DownloadArtifact(host, guest, url, checksum, fileName)
+	}
+
+	name := path.Base(url)
+	if err := s.verify(name); err != nil {
+		return fmt.Errorf("airgap artifact validation failed: %w", err)
+	}
+
+	return downloader.CopyFile(host, guest, filepath.Join(s.dir, name), fileName)
+}
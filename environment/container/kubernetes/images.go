@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/container/containerd"
+	"github.com/abiosoft/colima/environment/container/docker"
+)
+
+// ImageLoader is implemented by Kubernetes runtimes that can make locally
+// built images available to the cluster.
+type ImageLoader interface {
+	// LoadImages makes the images available to the cluster.
+	LoadImages(images ...string) error
+}
+
+var _ ImageLoader = (*kubernetesRuntime)(nil)
+
+const (
+	k8sNamespace      = "k8s.io"
+	nerdctlConfigFile = "/etc/nerdctl/nerdctl.toml"
+	// nerdctlManaged marks the lines of the nerdctl config managed by colima.
+	nerdctlManaged = "# colima"
+)
+
+// shareContainerdNamespace sets the nerdctl default namespace to k8s.io,
+// making images built or pulled with nerdctl visible to Kubernetes.
+// The other settings of the nerdctl config are kept, a namespace set by the user is commented out.
+func shareContainerdNamespace(guest environment.GuestActions, a *cli.ActiveCommandChain) {
+	a.Add(func() error {
+		if err := guest.RunQuiet("sudo", "mkdir", "-p", "/etc/nerdctl"); err != nil {
+			return fmt.Errorf("error creating nerdctl config dir: %w", err)
+		}
+		script := fmt.Sprintf(`grep -q '%[2]s$' %[1]s 2>/dev/null && exit 0; touch %[1]s && `+
+			`sed -i 's/^namespace *=.*/# & %[2]s replaced/' %[1]s && `+
+			`echo 'namespace = "%[3]s" %[2]s' >> %[1]s`, nerdctlConfigFile, nerdctlManaged, k8sNamespace)
+		return guest.RunQuiet("sudo", "sh", "-c", script)
+	})
+}
+
+// unshareContainerdNamespace restores the nerdctl config prior to shareContainerdNamespace.
+func unshareContainerdNamespace(guest environment.GuestActions, a *cli.ActiveCommandChain) {
+	a.Add(func() error {
+		script := fmt.Sprintf(`[ -f %[1]s ] || exit 0; `+
+			`sed -i -e '/^namespace *=.* %[2]s$/d' -e 's/^# \(.*\) %[2]s replaced$/\1/' %[1]s && `+
+			// the file did not exist prior
+			`if [ ! -s %[1]s ]; then rm -f %[1]s; fi`, nerdctlConfigFile, nerdctlManaged)
+		return guest.RunQuiet("sudo", "sh", "-c", script)
+	})
+}
+
+func (c kubernetesRuntime) LoadImages(images ...string) error {
+	log := c.Logger()
+
+	switch c.runtime() {
+	case docker.Name:
+		// k3s uses the docker daemon, images are shared.
+		log.Println("images built or pulled with docker are available to", Name)
+		return nil
+	case containerd.Name:
+	default:
+		return fmt.Errorf("image loading not supported for '%s' runtime", c.runtime())
+	}
+
+	a := c.Init()
+	for _, image := range images {
+		image := image
+		a.Stagef("loading %s", image)
+		a.Add(func() error {
+			// already in the Kubernetes namespace
+			if c.guest.RunQuiet("sudo", "nerdctl", "-n", k8sNamespace, "image", "inspect", image) == nil {
+				return nil
+			}
+			// a failed save would otherwise be hidden by the load
+			load := fmt.Sprintf("set -o pipefail; nerdctl -n default save %s | nerdctl -n %s load", strconv.Quote(image), k8sNamespace)
+			return c.guest.Run("sudo", "sh", "-c", load)
+		})
+	}
+
+	return a.Exec()
+}
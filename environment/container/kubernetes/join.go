@@ -0,0 +1,83 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	serverService = "k3s"
+	agentService  = "k3s-agent"
+)
+
+// joinInfo retrieves the server url and node token required for joining the cluster of profileName.
+func joinInfo(host environment.HostActions, profileName string) (serverURL, token string, err error) {
+	p := config.ProfileFromName(profileName)
+	if p.ID == config.Profile().ID {
+		return "", "", fmt.Errorf("cannot join own cluster")
+	}
+
+	ipAddress := lima.IPAddress(p.ID)
+	if ipAddress == "127.0.0.1" {
+		return "", "", fmt.Errorf("%s has no reachable IP address, VM networking is required to join a cluster", p.DisplayName)
+	}
+
+	token, err = host.RunOutput("limactl", "shell", p.ID, "sudo", "cat", "/var/lib/rancher/k3s/server/node-token")
+	if err != nil {
+		return "", "", fmt.Errorf("error retrieving node token from %s, ensure it is running with Kubernetes: %w", p.DisplayName, err)
+	}
+
+	// the port is retrieved from the server's kubeconfig.
+	kubeconfig, err := host.RunOutput("limactl", "shell", p.ID, "cat", "/etc/rancher/k3s/k3s.yaml")
+	if err != nil {
+		return "", "", fmt.Errorf("error retrieving kubeconfig from %s: %w", p.DisplayName, err)
+	}
+	port, err := kubeconfigPort(kubeconfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	return "https://" + ipAddress + ":" + port, strings.TrimSpace(token), nil
+}
+
+func kubeconfigPort(kubeconfig string) (string, error) {
+	var conf struct {
+		Clusters []struct {
+			Cluster struct {
+				Server string `yaml:"server"`
+			} `yaml:"cluster"`
+		} `yaml:"clusters"`
+	}
+	if err := yaml.Unmarshal([]byte(kubeconfig), &conf); err != nil {
+		return "", fmt.Errorf("error parsing kubeconfig: %w", err)
+	}
+	if len(conf.Clusters) == 0 {
+		return "", fmt.Errorf("no cluster found in kubeconfig")
+	}
+	u, err := url.Parse(conf.Clusters[0].Cluster.Server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server address in kubeconfig: %w", err)
+	}
+	if u.Port() == "" {
+		return "6443", nil
+	}
+	return u.Port(), nil
+}
+
+func (c kubernetesRuntime) isAgent() bool {
+	return c.guest.RunQuiet("command", "-v", agentService+"-uninstall.sh") == nil
+}
+
+// service returns the name of the k3s service in the VM.
+func (c kubernetesRuntime) service() string {
+	if c.isAgent() {
+		return agentService
+	}
+	return serverService
+}
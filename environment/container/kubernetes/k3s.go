@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/abiosoft/colima/cli"
@@ -10,41 +11,103 @@ import (
 	"github.com/abiosoft/colima/environment/container/containerd"
 	"github.com/abiosoft/colima/environment/container/docker"
 	"github.com/abiosoft/colima/environment/vm/lima"
-	"github.com/abiosoft/colima/util/downloader"
 	"github.com/sirupsen/logrus"
 )
 
-const k3sVersion = "v1.22.4+k3s1"
+// DefaultVersion is the default Kubernetes (k3s) version.
+const DefaultVersion = "v1.22.4+k3s1"
 
-func installK3s(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, log *logrus.Entry, containerRuntime string) {
-	installK3sBinary(host, guest, a)
-	installK3sCache(host, guest, a, log, containerRuntime)
-	installK3sCluster(host, guest, a, containerRuntime)
+// EdgeVersion is the default Kubernetes (k3s) version of the edge channel.
+const EdgeVersion = "v1.23.4+k3s1"
+
+// releaseChecksums are the pinned sha256 checksums of the k3s releases by version, of the sha256sum file
+// of each architecture and of install.sh that is not part of the published checksums.
+// The other artifacts are verified by the pinned sha256sum file. Updated with scripts/checksums.sh.
+var releaseChecksums = map[string]map[string]string{
+	DefaultVersion: {},
+	EdgeVersion:    {},
+}
+
+// releaseChecksumNames are the names of the files of a k3s release with pinned checksums.
+var releaseChecksumNames = []string{"sha256sum-amd64.txt", "sha256sum-arm64.txt", "install.sh"}
+
+// pinnedChecksum returns the pinned checksum of the file of the k3s release, empty if not pinned.
+// A file without a pinned checksum is not verified, a warning is logged by the download.
+func pinnedChecksum(k3sVersion, name string) string {
+	return releaseChecksums[k3sVersion][name]
+}
+
+// ChannelVersion returns the default Kubernetes (k3s) version of the release channel.
+func ChannelVersion(channel string) string {
+	if channel == config.ChannelEdge {
+		return EdgeVersion
+	}
+	return DefaultVersion
+}
+
+// releaseVersion returns the k3s release for version.
+// Kubernetes versions without the k3s suffix e.g. v1.22.4 are mapped to the first k3s release.
+func releaseVersion(version string) string {
+	if version == "" {
+		return DefaultVersion
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !strings.Contains(version, "+") {
+		version += "+k3s1"
+	}
+	return version
+}
+
+// k3sURLs are the urls of the k3s release artifacts.
+type k3sURLs struct {
+	binary    string
+	images    string
+	installer string
+}
+
+// releaseURLs returns the urls of the artifacts of the k3s release for arch.
+func releaseURLs(k3sVersion string, arch environment.Arch) k3sURLs {
+	base := "https://github.com/k3s-io/k3s/releases/download/" + k3sVersion + "/"
+	u := k3sURLs{
+		binary:    base + "k3s",
+		images:    base + "k3s-airgap-images-" + arch.GoArch() + ".tar.gz",
+		installer: "https://raw.githubusercontent.com/k3s-io/k3s/" + k3sVersion + "/install.sh",
+	}
+	if arch.GoArch() == "arm64" {
+		u.binary += "-arm64"
+	}
+	return u
+}
+
+func installK3s(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, log *logrus.Entry, containerRuntime string, k3sVersion string, conf config.Kubernetes) {
+	src := newArtifactSource(conf.AirgapPath, k3sVersion, guest.Arch())
+	installK3sBinary(host, guest, a, src, k3sVersion)
+	installK3sCache(host, guest, a, log, src, containerRuntime, k3sVersion)
+	installK3sCluster(host, guest, a, src, containerRuntime, k3sVersion, conf)
 }
 
-func installK3sBinary(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain) {
+func installK3sBinary(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, src artifactSource, k3sVersion string) {
 	// install k3s last to ensure it is the last step
 	downloadPath := "/tmp/k3s"
-	url := "https://github.com/k3s-io/k3s/releases/download/" + k3sVersion + "/k3s"
-	if guest.Arch().GoArch() == "arm64" {
-		url += "-arm64"
-	}
+	url := releaseURLs(k3sVersion, guest.Arch()).binary
 	a.Add(func() error {
-		return downloader.Download(host, guest, url, downloadPath)
+		return src.Fetch(host, guest, url, downloadPath)
 	})
 	a.Add(func() error {
 		return guest.Run("sudo", "install", downloadPath, "/usr/local/bin/k3s")
 	})
 }
 
-func installK3sCache(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, log *logrus.Entry, containerRuntime string) {
+func installK3sCache(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, log *logrus.Entry, src artifactSource, containerRuntime string, k3sVersion string) {
 	imageTar := "k3s-airgap-images-" + guest.Arch().GoArch() + ".tar"
 	imageTarGz := imageTar + ".gz"
 	downloadPathTar := "/tmp/" + imageTar
 	downloadPathTarGz := "/tmp/" + imageTarGz
-	url := "https://github.com/k3s-io/k3s/releases/download/" + k3sVersion + "/" + imageTarGz
+	url := releaseURLs(k3sVersion, guest.Arch()).images
 	a.Add(func() error {
-		return downloader.Download(host, guest, url, downloadPathTarGz)
+		return src.Fetch(host, guest, url, downloadPathTarGz)
 	})
 	a.Add(func() error {
 		return guest.Run("gzip", "-f", "-d", downloadPathTarGz)
@@ -83,37 +146,96 @@ func installK3sCache(host environment.HostActions, guest environment.GuestAction
 
 }
 
-func installK3sCluster(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, containerRuntime string) {
+func installK3sCluster(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, src artifactSource, containerRuntime string, k3sVersion string, conf config.Kubernetes) {
 	// install k3s last to ensure it is the last step
 	downloadPath := "/tmp/k3s-install.sh"
-	url := "https://raw.githubusercontent.com/k3s-io/k3s/" + k3sVersion + "/install.sh"
+	url := releaseURLs(k3sVersion, guest.Arch()).installer
 	a.Add(func() error {
-		return downloader.Download(host, guest, url, downloadPath)
+		return src.Fetch(host, guest, url, downloadPath)
 	})
 	a.Add(func() error {
 		return guest.Run("sudo", "install", downloadPath, "/usr/local/bin/k3s-install.sh")
 	})
 
+	if conf.Join != "" {
+		installK3sAgent(host, guest, a, containerRuntime, conf)
+		return
+	}
+
 	args := []string{
 		"--write-kubeconfig-mode", "644",
 		"--resolv-conf", "/etc/resolv.conf",
 		"--disable", "traefik",
 	}
 
+	// metrics-server is bundled with k3s.
+	// aggregator routing is required for the apiserver to reach metrics-server in the VM.
+	if conf.MetricsServer {
+		args = append(args, "--kube-apiserver-arg", "enable-aggregator-routing=true")
+	} else {
+		args = append(args, "--disable", "metrics-server")
+	}
+
+	if conf.Port > 0 {
+		args = append(args, "--https-listen-port", strconv.Itoa(conf.Port))
+	}
+
 	// replace ip address if networking is enabled
+	// and make the API server reachable by the address from other machines on the network.
 	ipAddress := lima.IPAddress(config.Profile().ID)
 	if ipAddress != "127.0.0.1" {
 		args = append(args, "--bind-address", ipAddress)
+		args = append(args, "--tls-san", ipAddress)
 	}
-
-	switch containerRuntime {
-	case docker.Name:
-		args = append(args, "--docker")
-	case containerd.Name:
-		args = append(args, "--container-runtime-endpoint", "unix:///run/containerd/containerd.sock")
+	for _, san := range conf.TLSSAN {
+		args = append(args, "--tls-san", san)
 	}
+
+	args = append(args, runtimeArgs(containerRuntime)...)
 	a.Add(func() error {
 		return guest.Run("sh", "-c", "INSTALL_K3S_SKIP_DOWNLOAD=true INSTALL_K3S_SKIP_ENABLE=true k3s-install.sh "+strings.Join(args, " "))
 	})
 
 }
+
+// installK3sAgent installs k3s as an agent joining the cluster of another profile.
+func installK3sAgent(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, containerRuntime string, conf config.Kubernetes) {
+	args := []string{
+		"--resolv-conf", "/etc/resolv.conf",
+	}
+
+	// use the network address to be reachable by the server
+	ipAddress := lima.IPAddress(config.Profile().ID)
+	if ipAddress != "127.0.0.1" {
+		args = append(args, "--node-ip", ipAddress)
+	}
+	args = append(args, runtimeArgs(containerRuntime)...)
+	// the token is not passed on the command line, visible to the processes of the VM and logged with --debug
+	args = append(args, "--token-file", joinTokenFile)
+
+	a.Stagef("joining cluster of profile '%s'", conf.Join)
+	a.Add(func() error {
+		serverURL, token, err := joinInfo(host, conf.Join)
+		if err != nil {
+			return err
+		}
+		if err := environment.WriteFile(guest, joinTokenFile, 0600, token); err != nil {
+			return err
+		}
+		env := fmt.Sprintf("K3S_URL=%s K3S_TOKEN_FILE=%s", serverURL, joinTokenFile)
+		return guest.Run("sh", "-c", env+" INSTALL_K3S_SKIP_DOWNLOAD=true INSTALL_K3S_SKIP_ENABLE=true k3s-install.sh "+strings.Join(args, " "))
+	})
+}
+
+// joinTokenFile is the node token of the cluster joined by the agent, readable only by root.
+const joinTokenFile = "/etc/rancher/k3s/join-token"
+
+func runtimeArgs(containerRuntime string) []string {
+	switch containerRuntime {
+	case docker.Name:
+		return []string{"--docker"}
+	case containerd.Name:
+		return []string{"--container-runtime-endpoint", "unix:///run/containerd/containerd.sock"}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package kubernetes
+
+import (
+	"regexp"
+	"testing"
+)
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func Test_releaseChecksums(t *testing.T) {
+	for _, version := range []string{DefaultVersion, EdgeVersion} {
+		sums, ok := releaseChecksums[version]
+		if !ok {
+			t.Errorf("releaseChecksums has no entry for k3s %s", version)
+			continue
+		}
+		if len(sums) == 0 {
+			// the downloads are not verified until pinned with scripts/checksums.sh
+			t.Logf("no pinned checksums for k3s %s", version)
+			continue
+		}
+		for _, name := range releaseChecksumNames {
+			if !sha256Pattern.MatchString(sums[name]) {
+				t.Errorf("invalid or missing pinned checksum for '%s' of k3s %s: '%s'", name, version, sums[name])
+			}
+		}
+		if len(sums) != len(releaseChecksumNames) {
+			t.Errorf("unexpected pinned checksums for k3s %s: %v", version, sums)
+		}
+	}
+}
@@ -3,19 +3,31 @@ package kubernetes
 import (
 	"fmt"
 	"path/filepath"
-	"strconv"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/vm/lima"
 )
 
 const kubeconfigKey = "kubeconfig"
 
 func (c kubernetesRuntime) provisionKubeconfig() error {
-	provisioned, _ := strconv.ParseBool(c.guest.Get(kubeconfigKey))
-	if provisioned {
+	kubeconfig, err := c.guest.RunOutput("cat", "/etc/rancher/k3s/k3s.yaml")
+	if err != nil {
+		return fmt.Errorf("error fetching kubeconfig on guest: %w", err)
+	}
+	server, err := c.serverURL(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	// the address of the VM may have changed since, and
+	// the host kubeconfig may have been modified e.g. after a profile rename
+	provisioned := c.guest.Get(kubeconfigKey) == server
+	if provisioned && c.host.RunQuiet("kubectl", "config", "get-contexts", config.Profile().ID) == nil {
 		return nil
 	}
 
@@ -45,12 +57,9 @@ func (c kubernetesRuntime) provisionKubeconfig() error {
 
 	// manipulate in VM and save to host
 	a.Add(func() error {
-		kubeconfig, err := c.guest.RunOutput("cat", "/etc/rancher/k3s/k3s.yaml")
-		if err != nil {
-			return fmt.Errorf("error fetching kubeconfig on guest: %w", err)
-		}
-		// replace name
-		kubeconfig = strings.ReplaceAll(kubeconfig, ": default", ": "+profile)
+		// replace name and server address
+		kubeconfig := strings.ReplaceAll(kubeconfig, ": default", ": "+profile)
+		kubeconfig = serverPattern.ReplaceAllString(kubeconfig, "${1}"+server)
 
 		// save on the host
 		return c.host.Write(tmpkubeconfFile, kubeconfig)
@@ -101,12 +110,25 @@ func (c kubernetesRuntime) provisionKubeconfig() error {
 
 	// save settings
 	a.Add(func() error {
-		return c.guest.Set(kubeconfigKey, "true")
+		return c.guest.Set(kubeconfigKey, server)
 	})
 
 	return a.Exec()
 }
 
+// serverPattern matches the server address of the clusters of a kubeconfig.
+var serverPattern = regexp.MustCompile(`(?m)^(\s*server:\s*)\S+`)
+
+// serverURL returns the address of the API server reachable from the host.
+// With networking enabled, the API server is bound to the IP address of the VM.
+func (c kubernetesRuntime) serverURL(kubeconfig string) (string, error) {
+	port, err := kubeconfigPort(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	return "https://" + lima.IPAddress(config.Profile().ID) + ":" + port, nil
+}
+
 func (c kubernetesRuntime) unsetKubeconfig(a *cli.ActiveCommandChain) {
 	profile := config.Profile().ID
 	a.Add(func() error {
@@ -1,7 +1,9 @@
 package kubernetes
 
 import (
+	"context"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/abiosoft/colima/cli"
@@ -9,6 +11,8 @@ import (
 	"github.com/abiosoft/colima/environment"
 	"github.com/abiosoft/colima/environment/container/containerd"
 	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/vm/lima"
+	"github.com/abiosoft/colima/util/downloader"
 )
 
 // Name is container runtime name
@@ -40,39 +44,86 @@ func (c kubernetesRuntime) Name() string {
 
 func (c kubernetesRuntime) isInstalled() bool {
 	// it is installed if uninstall script is present.
-	return c.guest.RunQuiet("command", "-v", "k3s-uninstall.sh") == nil
+	return c.guest.RunQuiet("command", "-v", "k3s-uninstall.sh") == nil || c.isAgent()
 }
 
 func (c kubernetesRuntime) Running() bool {
-	return c.guest.RunQuiet("sudo", "service", "k3s", "status") == nil
+	return c.guest.RunQuiet("sudo", "service", c.service(), "status") == nil
 }
 
 func (c kubernetesRuntime) runtime() string {
 	return c.guest.Get(environment.ContainerRuntimeKey)
 }
-func (c kubernetesRuntime) kubernetesVersion() string { //nolint:unused
-	return c.guest.Get(environment.KubernetesVersionKey)
+func (c kubernetesRuntime) kubernetesVersion() string {
+	return releaseVersion(c.guest.Get(environment.KubernetesVersionKey))
 }
 
-func (c *kubernetesRuntime) Provision() error {
+func (c *kubernetesRuntime) Provision(ctx context.Context) error {
 	log := c.Logger()
 	a := c.Init()
+	conf := config.FromContext(ctx).Kubernetes
 
 	if !c.isInstalled() {
 		// k3s
 		a.Stage("downloading and installing")
-		installK3s(c.host, c.guest, a, log, c.runtime())
+		installK3s(c.host, c.guest, a, log, c.runtime(), c.kubernetesVersion(), conf)
 	}
 
 	// this needs to happen on each startup
+	provisionRegistries(c.guest, a, config.FromContext(ctx).Registry)
+	if conf.Join == "" {
+		provisionManifests(c.host, c.guest, a, conf)
+	}
 	if c.runtime() == containerd.Name {
 		installContainerdDeps(c.guest, a)
+		shareContainerdNamespace(c.guest, a)
 	}
 
 	return a.Exec()
 }
 
-func (c kubernetesRuntime) Start() error {
+// Prefetch downloads the k3s release artifacts into the host cache concurrently.
+func (c kubernetesRuntime) Prefetch(ctx context.Context) error {
+	conf := config.FromContext(ctx)
+	// copied from the airgap path instead
+	if conf.Kubernetes.AirgapPath != "" {
+		return nil
+	}
+	arch := environment.Arch(conf.VM.Arch).Value()
+	version := releaseVersion(conf.Kubernetes.Version)
+	src := newArtifactSource("", version, arch)
+	u := releaseURLs(version, arch)
+
+	// the checksum file is fetched first, it is shared by the artifacts
+	urls := []string{u.binary, u.images, u.installer}
+	checksums := make([]string, len(urls))
+	for i, url := range urls {
+		checksum, err := src.releaseChecksum(c.host, url)
+		if err != nil {
+			return err
+		}
+		checksums[i] = checksum
+	}
+
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			_, errs[i] = downloader.FetchArtifact(c.host, url, checksums[i])
+		}(i, url)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c kubernetesRuntime) Start(ctx context.Context) error {
 	log := c.Logger()
 	a := c.Init()
 	if c.Running() {
@@ -82,29 +133,65 @@ func (c kubernetesRuntime) Start() error {
 
 	a.Stage("starting")
 
+	service := c.service()
 	a.Add(func() error {
-		defer time.Sleep(time.Second * 5)
-		return c.guest.Run("sudo", "service", "k3s", "start")
+		return c.guest.Run("sudo", "service", service, "start")
 	})
 
-	if err := a.Exec(); err != nil {
+	// agents do not serve the API
+	ready := func() error { return c.guest.RunQuiet("sudo", "k3s", "kubectl", "get", "--raw", "/readyz") }
+	if service == agentService {
+		ready = func() error { return c.guest.RunQuiet("sudo", "service", service, "status") }
+	}
+	timeout := config.FromContext(ctx).WaitTimeout.Duration(Name)
+	a.Wait("waiting for startup to complete", time.Second*5, timeout, ready)
+
+	// the log file is named after the service
+	if err := environment.WithLogTail(c.guest, a.Exec(), "/var/log/"+service+".log"); err != nil {
 		return err
 	}
 
+	// agents are managed via the kubeconfig of the server
+	if service == agentService {
+		return nil
+	}
+
 	return c.provisionKubeconfig()
 }
 
-func (c kubernetesRuntime) Stop() error {
+func (c kubernetesRuntime) Stop(context.Context) error {
 	a := c.Init()
 	a.Stage("stopping")
+
+	// the watchdog restarts k3s found crashed, it is stopped until k3s is stopped
+	watchdog := false
+	a.Add(func() error {
+		if c.guest.RunQuiet("service", lima.WatchdogService, "status") != nil {
+			return nil
+		}
+		watchdog = true
+		return c.guest.RunQuiet("sudo", "service", lima.WatchdogService, "stop")
+	})
 	a.Add(func() error {
 		return c.guest.Run("k3s-killall.sh")
 	})
+	// the service is otherwise considered crashed and restarted by the watchdog
+	a.Add(func() error {
+		return c.guest.RunQuiet("sudo", "rc-service", c.service(), "zap")
+	})
 
 	// k3s is buggy with external containerd for now
 	// cleanup is manual
 	a.Add(c.stopAllContainers)
 
+	// the container runtime is still watched
+	a.Add(func() error {
+		if !watchdog {
+			return nil
+		}
+		return c.guest.RunQuiet("sudo", "service", lima.WatchdogService, "start")
+	})
+
 	return a.Exec()
 }
 
@@ -177,8 +264,9 @@ func (c kubernetesRuntime) Teardown() error {
 	a.Stage("deleting")
 
 	if c.isInstalled() {
+		uninstall := c.service() + "-uninstall.sh"
 		a.Add(func() error {
-			return c.guest.Run("k3s-uninstall.sh")
+			return c.guest.Run(uninstall)
 		})
 	}
 
@@ -188,6 +276,10 @@ func (c kubernetesRuntime) Teardown() error {
 		return c.deleteAllContainers()
 	})
 
+	if c.runtime() == containerd.Name {
+		unshareContainerdNamespace(c.guest, a)
+	}
+
 	c.teardownKubeconfig(a)
 	a.Add(func() error {
 		return c.guest.Set(kubeconfigKey, "")
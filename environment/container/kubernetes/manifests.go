@@ -0,0 +1,123 @@
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util"
+	"github.com/abiosoft/colima/util/downloader"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestsDir is the k3s auto-deploy directory.
+// Manifests in the directory are applied by k3s at startup and on change.
+const manifestsDir = k3sDataDir + "/server/manifests"
+
+// manifestPrefix is the prefix for files managed by colima in the manifests directory.
+const manifestPrefix = "colima-"
+
+// helmChart is the k3s HelmChart resource.
+// https://rancher.com/docs/k3s/latest/en/helm/
+type helmChart struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Chart           string `yaml:"chart"`
+		Repo            string `yaml:"repo,omitempty"`
+		Version         string `yaml:"version,omitempty"`
+		TargetNamespace string `yaml:"targetNamespace,omitempty"`
+		ValuesContent   string `yaml:"valuesContent,omitempty"`
+	} `yaml:"spec"`
+}
+
+func newHelmChart(c config.HelmChart) (helmChart, error) {
+	var h helmChart
+	h.APIVersion = "helm.cattle.io/v1"
+	h.Kind = "HelmChart"
+	h.Metadata.Name = c.Name
+	h.Metadata.Namespace = "kube-system"
+	h.Spec.Chart = c.Chart
+	h.Spec.Repo = c.Repo
+	h.Spec.Version = c.Version
+	h.Spec.TargetNamespace = c.Namespace
+
+	if len(c.Values) > 0 {
+		b, err := yaml.Marshal(c.Values)
+		if err != nil {
+			return h, fmt.Errorf("error encoding values for chart '%s': %w", c.Name, err)
+		}
+		h.Spec.ValuesContent = string(b)
+	}
+
+	return h, nil
+}
+
+// provisionManifests syncs the configured manifests and Helm charts to the k3s manifests directory.
+func provisionManifests(host environment.HostActions, guest environment.GuestActions, a *cli.ActiveCommandChain, conf config.Kubernetes) {
+	// clear previous manifests, config may have changed.
+	a.Add(func() error {
+		return guest.RunQuiet("sudo", "sh", "-c", "rm -f "+manifestsDir+"/"+manifestPrefix+"*")
+	})
+
+	if len(conf.Manifests) == 0 && len(conf.HelmCharts) == 0 {
+		return
+	}
+
+	a.Stage("applying manifests")
+	a.Add(func() error {
+		return guest.RunQuiet("sudo", "mkdir", "-p", manifestsDir)
+	})
+
+	for i, m := range conf.Manifests {
+		m := m
+		fileName := fmt.Sprintf("%s/%s%d-%s", manifestsDir, manifestPrefix, i, path.Base(m))
+		a.Add(func() error {
+			tmpFile := "/tmp/" + manifestPrefix + "manifest"
+			var err error
+			if strings.HasPrefix(m, "http://") || strings.HasPrefix(m, "https://") {
+				err = downloader.Download(host, guest, m, tmpFile)
+			} else {
+				file := m
+				if strings.HasPrefix(file, "~") {
+					file = strings.Replace(file, "~", util.HomeDir(), 1)
+				}
+				file, err = filepath.Abs(file)
+				if err == nil {
+					err = downloader.CopyFile(host, guest, file, tmpFile)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("error retrieving manifest '%s': %w", m, err)
+			}
+			return guest.RunQuiet("sudo", "mv", tmpFile, fileName)
+		})
+	}
+
+	for _, c := range conf.HelmCharts {
+		c := c
+		fileName := manifestsDir + "/" + manifestPrefix + "helm-" + c.Name + ".yaml"
+		a.Add(func() error {
+			h, err := newHelmChart(c)
+			if err != nil {
+				return err
+			}
+			b, err := yaml.Marshal(h)
+			if err != nil {
+				return fmt.Errorf("error encoding chart '%s': %w", c.Name, err)
+			}
+			if err := environment.WriteFile(guest, fileName, 0600, string(b)); err != nil {
+				return fmt.Errorf("error writing chart '%s': %w", c.Name, err)
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"gopkg.in/yaml.v3"
+)
+
+const registriesFile = "/etc/rancher/k3s/registries.yaml"
+
+// registries is the k3s registries.yaml file.
+// https://rancher.com/docs/k3s/latest/en/installation/private-registry/
+type registries struct {
+	Mirrors map[string]registryMirror `yaml:"mirrors,omitempty"`
+	Configs map[string]registryConfig `yaml:"configs,omitempty"`
+}
+
+type registryMirror struct {
+	Endpoint []string `yaml:"endpoint"`
+}
+
+type registryConfig struct {
+	Auth *registryAuth `yaml:"auth,omitempty"`
+	TLS  *registryTLS  `yaml:"tls,omitempty"`
+}
+
+type registryAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type registryTLS struct {
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+func newRegistries(conf config.Registry) registries {
+	r := registries{
+		Mirrors: map[string]registryMirror{},
+		Configs: map[string]registryConfig{},
+	}
+
+	if len(conf.Mirrors) > 0 {
+		r.Mirrors["docker.io"] = registryMirror{Endpoint: conf.Mirrors}
+	}
+	for _, host := range conf.Insecure {
+		c := r.Configs[host]
+		c.TLS = &registryTLS{InsecureSkipVerify: true}
+		r.Configs[host] = c
+	}
+	for host, auth := range conf.Auths {
+		c := r.Configs[host]
+		c.Auth = &registryAuth{Username: auth.Username, Password: auth.Password}
+		r.Configs[host] = c
+	}
+
+	return r
+}
+
+func (r registries) empty() bool { return len(r.Mirrors) == 0 && len(r.Configs) == 0 }
+
+// provisionRegistries renders the registry config to k3s' registries.yaml.
+// It is applied at the next k3s startup.
+func provisionRegistries(guest environment.GuestActions, a *cli.ActiveCommandChain, conf config.Registry) {
+	r := newRegistries(conf)
+
+	a.Add(func() error {
+		if r.empty() {
+			return guest.RunQuiet("sudo", "rm", "-f", registriesFile)
+		}
+
+		b, err := yaml.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("error encoding registries.yaml: %w", err)
+		}
+
+		// the file may contain credentials
+		if err := environment.WriteFile(guest, registriesFile, 0600, string(b)); err != nil {
+			return fmt.Errorf("error writing registries.yaml: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util/release"
+)
+
+// Upgrader is implemented by Kubernetes runtimes that can be upgraded in place.
+type Upgrader interface {
+	// Upgrade upgrades the cluster to version, an older version than the current version is refused
+	// unless downgrade is set. A snapshot of the cluster state is taken prior to the upgrade.
+	Upgrade(ctx context.Context, version string, downgrade bool) error
+}
+
+var _ Upgrader = (*kubernetesRuntime)(nil)
+
+const (
+	k3sDataDir  = "/var/lib/rancher/k3s"
+	snapshotDir = "/var/lib/rancher/colima/snapshots"
+)
+
+func (c kubernetesRuntime) Upgrade(ctx context.Context, version string, downgrade bool) error {
+	log := c.Logger()
+	a := c.Init()
+
+	if !c.isInstalled() {
+		return fmt.Errorf("%s is not enabled", Name)
+	}
+	version = releaseVersion(version)
+	current := c.kubernetesVersion()
+	if version == current {
+		log.Println("already on version", version)
+		return nil
+	}
+	// the k3s revisions of a version are not ordered, e.g. a fix of the release
+	if older, err := release.Older(version, current); err == nil && older && !downgrade {
+		return cli.NewError(cli.ExitKubernetes, fmt.Errorf("%s is older than the current version %s", version, current),
+			"pass --downgrade to downgrade the cluster")
+	}
+
+	service := c.service()
+	a.Stage("stopping")
+	a.Add(func() error {
+		return c.guest.Run("sudo", "service", service, "stop")
+	})
+
+	// agents do not hold cluster state
+	if service != agentService {
+		snapshot := filepath.Join(snapshotDir, fmt.Sprintf("%s-%d.tar.gz", strings.ReplaceAll(current, "+", "-"), time.Now().Unix()))
+
+		a.Stage("creating snapshot")
+		a.Add(func() error {
+			return c.guest.Run("sudo", "mkdir", "-p", snapshotDir)
+		})
+		a.Add(func() error {
+			return c.guest.Run("sudo", "tar", "-czf", snapshot, "-C", k3sDataDir, "server")
+		})
+		a.Add(func() error {
+			log.Println("snapshot saved at", snapshot)
+			return nil
+		})
+	}
+
+	a.Stagef("upgrading from %s to %s", current, version)
+	installK3s(c.host, c.guest, a, log, c.runtime(), version, config.FromContext(ctx).Kubernetes)
+
+	a.Add(func() error {
+		return c.guest.Set(environment.KubernetesVersionKey, version)
+	})
+
+	if err := a.Exec(); err != nil {
+		return err
+	}
+
+	return c.Start(ctx)
+}
@@ -0,0 +1,98 @@
+package environment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSetServiceEnv(t *testing.T) {
+	tests := []struct {
+		systemd     bool
+		current     string
+		env         map[string]string
+		want        string
+		wantChanged bool
+	}{
+		{
+			current:     "command_args=\"--debug\"\n",
+			env:         map[string]string{"LANG": "en_US.UTF-8", "HTTP_PROXY": "http://proxy:3128"},
+			want:        "command_args=\"--debug\"\n# BEGIN colima env\nexport HTTP_PROXY='http://proxy:3128'\nexport LANG='en_US.UTF-8'\n# END colima env\n",
+			wantChanged: true,
+		},
+		// the section is replaced
+		{
+			current:     "command_args=\"--debug\"\n# BEGIN colima env\nexport LANG='C.UTF-8'\n# END colima env\n",
+			env:         map[string]string{"LANG": "en_US.UTF-8"},
+			want:        "command_args=\"--debug\"\n# BEGIN colima env\nexport LANG='en_US.UTF-8'\n# END colima env\n",
+			wantChanged: true,
+		},
+		// unchanged
+		{
+			current: "# BEGIN colima env\nexport LANG='en_US.UTF-8'\n# END colima env\n",
+			env:     map[string]string{"LANG": "en_US.UTF-8"},
+			want:    "# BEGIN colima env\nexport LANG='en_US.UTF-8'\n# END colima env\n",
+		},
+		// the section is removed
+		{
+			current:     "command_args=\"--debug\"\n# BEGIN colima env\nexport LANG='C.UTF-8'\n# END colima env\n",
+			want:        "command_args=\"--debug\"\n",
+			wantChanged: true,
+		},
+		{
+			systemd:     true,
+			env:         map[string]string{"LANG": "en_US.UTF-8", "NO_PROXY": "100%"},
+			want:        "[Service]\nEnvironment=\"LANG=en_US.UTF-8\"\nEnvironment=\"NO_PROXY=100%%\"\n",
+			wantChanged: true,
+		},
+		{
+			systemd: true,
+			current: "[Service]\nEnvironment=\"LANG=en_US.UTF-8\"\n",
+			env:     map[string]string{"LANG": "en_US.UTF-8"},
+			want:    "[Service]\nEnvironment=\"LANG=en_US.UTF-8\"\n",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			file := "/etc/conf.d/docker"
+			if tt.systemd {
+				file = systemdDropIn("docker")
+			}
+			guest := &fakeGuest{systemd: tt.systemd, files: map[string]string{}}
+			if tt.current != "" {
+				guest.files[file] = tt.current
+			}
+
+			changed, err := SetServiceEnv(guest, "docker", tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("SetServiceEnv() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if got := guest.files[file]; got != tt.want {
+				t.Errorf("SetServiceEnv() file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	tests := []struct {
+		env  map[string]string
+		want map[string]string
+	}{
+		{want: map[string]string{"LANG": "de_DE.UTF-8"}},
+		{env: map[string]string{"LANG": "C.UTF-8", "A": "1"}, want: map[string]string{"LANG": "C.UTF-8", "A": "1"}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := DaemonEnv(tt.env); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("DaemonEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
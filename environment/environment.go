@@ -1,6 +1,7 @@
 package environment
 
 import (
+	"io"
 	"os"
 
 	"github.com/abiosoft/colima/config"
@@ -16,6 +17,8 @@ type runActions interface {
 	RunOutput(args ...string) (string, error)
 	// RunInteractive runs command interactively.
 	RunInteractive(args ...string) error
+	// RunWith runs command with stdin and stdout.
+	RunWith(stdin io.Reader, stdout io.Writer, args ...string) error
 }
 
 type fileActions interface {
@@ -58,6 +61,9 @@ type GuestActions interface {
 	User() (string, error)
 	// Arch returns the architecture of the VM.
 	Arch() Arch
+	// SSH runs the command interactively in the directory dir of the VM,
+	// or starts a login shell if no command is specified.
+	SSH(dir string, args ...string) error
 }
 
 // Dependencies are dependencies that must exist on the host.
@@ -0,0 +1,16 @@
+package environment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WriteFile writes body to fileName in the VM as root with mode e.g. 0644, creating the directory if missing.
+// The body is passed through the input of the command, it does not appear in the arguments.
+func WriteFile(guest GuestActions, fileName string, mode uint32, body string) error {
+	script := fmt.Sprintf(`mkdir -p "$(dirname "$1")" && touch "$1" && chmod %o "$1" && cat > "$1"`, mode)
+	if err := guest.RunWith(strings.NewReader(body), nil, "sudo", "sh", "-c", script, "sh", fileName); err != nil {
+		return fmt.Errorf("error writing %s: %w", fileName, err)
+	}
+	return nil
+}
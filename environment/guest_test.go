@@ -0,0 +1,57 @@
+package environment
+
+import (
+	"fmt"
+	"io"
+)
+
+// fakeGuest is a guest that records the commands it runs and returns the outputs by command.
+type fakeGuest struct {
+	GuestActions
+	outputs map[string]string
+	files   map[string]string
+	systemd bool
+	cmds    []string
+}
+
+func (f *fakeGuest) Systemd() bool { return f.systemd }
+
+func (f *fakeGuest) RunOutput(args ...string) (string, error) {
+	cmd := fmt.Sprint(args)
+	f.cmds = append(f.cmds, cmd)
+	if len(args) == 2 && args[0] == "cat" {
+		if body, ok := f.files[args[1]]; ok {
+			return body, nil
+		}
+	}
+	if out, ok := f.outputs[cmd]; ok {
+		return out, nil
+	}
+	return "", fmt.Errorf("unexpected command %s", cmd)
+}
+
+func (f *fakeGuest) RunQuiet(args ...string) error {
+	f.cmds = append(f.cmds, fmt.Sprint(args))
+	return nil
+}
+
+// RunWith writes stdin to the file of a WriteFile script.
+func (f *fakeGuest) RunWith(stdin io.Reader, _ io.Writer, args ...string) error {
+	f.cmds = append(f.cmds, fmt.Sprint(args))
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	if f.files == nil {
+		f.files = map[string]string{}
+	}
+	f.files[args[len(args)-1]] = string(b)
+	return nil
+}
+
+func (f *fakeGuest) boot(id string) {
+	if f.files == nil {
+		f.files = map[string]string{}
+	}
+	f.files["/proc/sys/kernel/random/boot_id"] = id
+}
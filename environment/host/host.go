@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/abiosoft/colima/util/terminal"
+	"github.com/sirupsen/logrus"
 
 	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/environment"
@@ -37,15 +39,27 @@ func (h hostEnv) Run(args ...string) error {
 	if len(args) == 0 {
 		return errors.New("args not specified")
 	}
+	defer cli.TimeCommand(args...)()
 	cmd := cli.Command(args[0], args[1:]...)
 	cmd.Env = append(os.Environ(), h.env...)
 
 	lineHeight := 6
-	if cli.Settings.Verbose {
+	if cli.Settings.Verbose || cli.Settings.CI {
 		lineHeight = -1 // disable scrolling
 	}
 
-	out := terminal.NewVerboseWriter(lineHeight)
+	var out io.WriteCloser = terminal.NewVerboseWriter(lineHeight)
+	switch {
+	case cli.Settings.Quiet:
+		out = nopWriteCloser{io.Discard}
+	case cli.Settings.LogFormat == "json":
+		// keep the output parseable, the command output is logged line by line.
+		level := logrus.DebugLevel
+		if cli.Settings.Verbose {
+			level = logrus.InfoLevel
+		}
+		out = logrus.WithField("event", "command.output").WriterLevel(level)
+	}
 	cmd.Stdout = out
 	cmd.Stderr = out
 
@@ -53,6 +67,9 @@ func (h hostEnv) Run(args ...string) error {
 	if err == nil {
 		return out.Close()
 	}
+	if cli.Settings.LogFormat == "json" {
+		_ = out.Close()
+	}
 	return err
 }
 
@@ -60,6 +77,7 @@ func (h hostEnv) RunQuiet(args ...string) error {
 	if len(args) == 0 {
 		return errors.New("args not specified")
 	}
+	defer cli.TimeCommand(args...)()
 	cmd := cli.Command(args[0], args[1:]...)
 	cmd.Env = append(os.Environ(), h.env...)
 	cmd.Stdout = nil
@@ -72,6 +90,7 @@ func (h hostEnv) RunOutput(args ...string) (string, error) {
 	if len(args) == 0 {
 		return "", errors.New("args not specified")
 	}
+	defer cli.TimeCommand(args...)()
 
 	cmd := cli.Command(args[0], args[1:]...)
 	cmd.Env = append(os.Environ(), h.env...)
@@ -90,11 +109,33 @@ func (h hostEnv) RunInteractive(args ...string) error {
 	if len(args) == 0 {
 		return errors.New("args not specified")
 	}
+	defer cli.TimeCommand(args...)()
 	cmd := cli.CommandInteractive(args[0], args[1:]...)
 	cmd.Env = append(os.Environ(), h.env...)
 	return cmd.Run()
 }
 
+func (h hostEnv) RunWith(stdin io.Reader, stdout io.Writer, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("args not specified")
+	}
+	defer cli.TimeCommand(args...)()
+	cmd := cli.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), h.env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
 func (h hostEnv) Env(s string) string {
 	return os.Getenv(s)
 }
@@ -126,8 +167,15 @@ func IsInstalled(dependencies environment.Dependencies) error {
 	}
 
 	if len(missing) > 0 {
-		return fmt.Errorf("%s not found, run 'brew install %s' to install", strings.Join(missing, ", "), strings.Join(missing, " "))
+		return cli.NewError(cli.ExitDependency,
+			fmt.Errorf("%s not found", strings.Join(missing, ", ")),
+			fmt.Sprintf("run 'brew install %s' to install", strings.Join(missing, " ")),
+		)
 	}
 
 	return nil
 }
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,56 @@
+package environment
+
+import (
+	"github.com/abiosoft/colima/config"
+	"github.com/sirupsen/logrus"
+)
+
+// instanceIDFile is the marker of the instance of the guest in /etc, it is lost with the changes of /etc.
+const instanceIDFile = "/etc/colima/instance_id"
+
+// instanceID returns the id of the instance of the guest, created if missing.
+func instanceID(guest GuestActions) string {
+	script := `test -s ` + instanceIDFile + ` || { mkdir -p /etc/colima && cat /proc/sys/kernel/random/uuid > ` + instanceIDFile + `; }; cat ` + instanceIDFile
+	id, _ := guest.RunOutput("sudo", "sh", "-c", script)
+	return id
+}
+
+// bootID returns the id of the current boot of the guest.
+func bootID(guest GuestActions) string {
+	id, _ := guest.RunOutput("cat", "/proc/sys/kernel/random/boot_id")
+	return id
+}
+
+// Provisioned wraps the provisioning step f of the guest to be skipped if inputs are unchanged since it last
+// succeeded in the current instance of the guest, i.e. as long as the changes of f to /etc are retained.
+// inputs must include everything the outcome of f depends on.
+func Provisioned(guest GuestActions, step string, inputs interface{}, f func() error) func() error {
+	return provisioned(guest, step, inputs, f, instanceID)
+}
+
+// ProvisionedBoot is Provisioned for the steps whose changes are not retained across reboots of the guest,
+// e.g. outside of /etc or overwritten on boot. The step is skipped in the current boot of the guest.
+func ProvisionedBoot(guest GuestActions, step string, inputs interface{}, f func() error) func() error {
+	return provisioned(guest, step, inputs, f, bootID)
+}
+
+func provisioned(guest GuestActions, step string, inputs interface{}, f func() error, keyFunc func(GuestActions) string) func() error {
+	return func() error {
+		key := keyFunc(guest)
+		if config.ProvisionUnchanged(step, key, inputs) {
+			logrus.WithField("context", step).Debugln("unchanged, skipped")
+			return nil
+		}
+		if err := f(); err != nil {
+			return err
+		}
+		if key == "" {
+			return nil
+		}
+		if err := config.RecordProvision(step, key, inputs); err != nil {
+			// not fatal, the step is not skipped on the next start
+			logrus.Warnln(err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package environment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_provisioned(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	guest := &fakeGuest{outputs: map[string]string{}}
+	script := `test -s ` + instanceIDFile + ` || { mkdir -p /etc/colima && cat /proc/sys/kernel/random/uuid > ` + instanceIDFile + `; }; cat ` + instanceIDFile
+	guest.outputs[fmt.Sprint([]string{"sudo", "sh", "-c", script})] = "instance-1"
+
+	tests := []struct {
+		boot    string
+		name    string
+		step    func(GuestActions, string, interface{}, func() error) func() error
+		inputs  interface{}
+		wantRun bool
+	}{
+		{boot: "boot-1", name: "vm.etc", step: Provisioned, inputs: "a", wantRun: true},
+		{boot: "boot-1", name: "vm.boot", step: ProvisionedBoot, inputs: "a", wantRun: true},
+		{boot: "boot-1", name: "vm.etc", step: Provisioned, inputs: "a"},
+		{boot: "boot-1", name: "vm.boot", step: ProvisionedBoot, inputs: "a"},
+		// rebooted, the changes of the instance are retained
+		{boot: "boot-2", name: "vm.etc", step: Provisioned, inputs: "a"},
+		{boot: "boot-2", name: "vm.boot", step: ProvisionedBoot, inputs: "a", wantRun: true},
+		// changed inputs
+		{boot: "boot-2", name: "vm.etc", step: Provisioned, inputs: "b", wantRun: true},
+		{boot: "boot-2", name: "vm.boot", step: ProvisionedBoot, inputs: "b", wantRun: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			guest.boot(tt.boot)
+			var run bool
+			if err := tt.step(guest, tt.name, tt.inputs, func() error { run = true; return nil })(); err != nil {
+				t.Fatal(err)
+			}
+			if run != tt.wantRun {
+				t.Errorf("run = %v, want %v", run, tt.wantRun)
+			}
+		})
+	}
+
+	// a new instance of the VM, e.g. recreated
+	guest.outputs[fmt.Sprint([]string{"sudo", "sh", "-c", script})] = "instance-2"
+	var run bool
+	if err := Provisioned(guest, "vm.etc", "b", func() error { run = true; return nil })(); err != nil {
+		t.Fatal(err)
+	}
+	if !run {
+		t.Error("run = false, want true for a new instance")
+	}
+}
@@ -0,0 +1,88 @@
+package environment
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+)
+
+// registryAuthsKey is the settings key of the registries with credentials set by SetRegistryAuths.
+const registryAuthsKey = "registry_auths"
+
+// SetRegistryAuths sets the registry credentials in the docker config of root and of the user in the VM,
+// for the docker and nerdctl clients and the kubelet in the VM. The other entries of the configs are kept,
+// the credentials previously set and since removed from auths are removed.
+func SetRegistryAuths(guest GuestActions, auths map[string]config.RegistryAuth) error {
+	var previous []string
+	if v := guest.Get(registryAuthsKey); v != "" {
+		previous = strings.Split(v, ",")
+	}
+	if len(auths) == 0 && len(previous) == 0 {
+		return nil
+	}
+
+	user, err := guest.User()
+	if err != nil {
+		return fmt.Errorf("error retrieving user in the VM: %w", err)
+	}
+	home, err := guest.RunOutput("sh", "-c", "echo $HOME")
+	if err != nil {
+		return fmt.Errorf("error retrieving home directory in the VM: %w", err)
+	}
+
+	for _, dir := range []string{"/root", home} {
+		file := dir + "/.docker/config.json"
+		current, _ := guest.RunOutput("sudo", "cat", file)
+		body, err := mergeRegistryAuths(current, auths, previous)
+		if err != nil {
+			return fmt.Errorf("error updating %s: %w", file, err)
+		}
+		if err := WriteFile(guest, file, 0600, body); err != nil {
+			return err
+		}
+	}
+	if err := guest.RunQuiet("sudo", "chown", "-R", user, home+"/.docker"); err != nil {
+		return fmt.Errorf("error setting owner of docker config: %w", err)
+	}
+
+	hosts := make([]string, 0, len(auths))
+	for host := range auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return guest.Set(registryAuthsKey, strings.Join(hosts, ","))
+}
+
+// mergeRegistryAuths merges auths into the docker config json current, the previous hosts not in auths are removed.
+func mergeRegistryAuths(current string, auths map[string]config.RegistryAuth, previous []string) (string, error) {
+	obj := map[string]interface{}{}
+	if strings.TrimSpace(current) != "" {
+		if err := json.Unmarshal([]byte(current), &obj); err != nil {
+			return "", fmt.Errorf("error decoding docker config: %w", err)
+		}
+	}
+
+	entries, _ := obj["auths"].(map[string]interface{})
+	if entries == nil {
+		entries = map[string]interface{}{}
+	}
+	for _, host := range previous {
+		delete(entries, host)
+	}
+	for host, auth := range auths {
+		entries[host] = map[string]interface{}{
+			"auth": base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password)),
+		}
+	}
+	obj["auths"] = entries
+
+	b, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding docker config: %w", err)
+	}
+	return string(b) + "\n", nil
+}
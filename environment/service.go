@@ -0,0 +1,82 @@
+package environment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Systemd is implemented by the guests whose services may be managed by systemd, e.g. a remote host.
+// The services of the VM are managed by OpenRC.
+type Systemd interface {
+	// Systemd returns if the services of the guest are managed by systemd.
+	Systemd() bool
+}
+
+func usesSystemd(guest GuestActions) bool {
+	s, ok := guest.(Systemd)
+	return ok && s.Systemd()
+}
+
+// ServiceCmd returns the command that runs action on the service of the guest, one of start, stop,
+// restart and status, with the init system of the guest. It is prefixed with sudo if not status.
+func ServiceCmd(guest GuestActions, service, action string) []string {
+	args := []string{"service", service, action}
+	if usesSystemd(guest) {
+		args = []string{"systemctl", action, service}
+		if action == "status" {
+			args = []string{"systemctl", "is-active", "--quiet", service}
+		}
+	}
+	if action == "status" {
+		return args
+	}
+	return append([]string{"sudo"}, args...)
+}
+
+// systemdDropIn is the drop-in of the systemd unit of service with the environment managed by colima.
+func systemdDropIn(service string) string {
+	return "/etc/systemd/system/" + service + ".service.d/colima-env.conf"
+}
+
+// systemdQuote quotes s as a word of a systemd unit file, % is a specifier prefix.
+func systemdQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%")
+	return `"` + r.Replace(s) + `"`
+}
+
+// setSystemdEnv is SetServiceEnv for a service managed by systemd, with a drop-in of the unit.
+func setSystemdEnv(guest GuestActions, service string, env map[string]string) (changed bool, err error) {
+	file := systemdDropIn(service)
+	current, _ := guest.RunOutput("cat", file)
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var body string
+	if len(keys) > 0 {
+		lines := []string{"[Service]"}
+		for _, k := range keys {
+			lines = append(lines, "Environment="+systemdQuote(k+"="+env[k]))
+		}
+		body = strings.Join(lines, "\n")
+	}
+	if strings.TrimSpace(current) == body {
+		return false, nil
+	}
+
+	if body == "" {
+		err = guest.RunQuiet("sudo", "rm", "-f", file)
+	} else {
+		err = WriteFile(guest, file, 0644, body+"\n")
+	}
+	if err != nil {
+		return false, fmt.Errorf("error setting %s environment: %w", service, err)
+	}
+	if err := guest.RunQuiet("sudo", "systemctl", "daemon-reload"); err != nil {
+		return false, fmt.Errorf("error setting %s environment: %w", service, err)
+	}
+	return true, nil
+}
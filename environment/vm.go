@@ -38,6 +38,15 @@ func (a Arch) GoArch() string {
 	return runtime.GOARCH
 }
 
+// HostArch returns the architecture of the host.
+func HostArch() Arch { return Arch(runtime.GOARCH).Value() }
+
+// Emulated returns if the architecture differs from the host, the VM is then emulated.
+func (a Arch) Emulated() bool {
+	v := a.Value()
+	return v != "default" && v != HostArch()
+}
+
 // Value converts the underlying architecture alias value to one of X8664 or AARCH64.
 func (a Arch) Value() Arch {
 	switch a {
@@ -0,0 +1,64 @@
+package lima
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+)
+
+// applyCPUAffinity pins the vCPUs of the VM to the host cores of conf.
+// On macOS, threads cannot be pinned to cores and the VM can only be restricted to the efficiency cores.
+// Failures are not fatal, the VM runs unpinned.
+func (l limaVM) applyCPUAffinity(conf config.Config) error {
+	if conf.VM.CPUAffinity == "" {
+		return nil
+	}
+	log := l.Logger()
+
+	if runtime.GOOS == "darwin" {
+		pid, err := qemuPid(config.Profile().ID)
+		if err == nil {
+			err = l.host.RunQuiet("taskpolicy", "-b", "-p", strconv.Itoa(pid))
+		}
+		if err != nil {
+			log.Warnln(fmt.Errorf("error restricting VM to efficiency cores: %w", err))
+		}
+		return nil
+	}
+
+	cores, err := config.ParseCPUList(conf.VM.CPUAffinity)
+	if err != nil {
+		return err
+	}
+	threads, err := vcpuThreads(config.Profile().ID)
+	if err != nil {
+		log.Warnln(fmt.Errorf("error pinning vCPUs: %w", err))
+		return nil
+	}
+	// one core per vCPU, shared if there are fewer cores than vCPUs
+	for i, tid := range threads {
+		core := strconv.Itoa(cores[i%len(cores)])
+		if err := l.host.RunQuiet("taskset", "-p", "-c", core, strconv.Itoa(tid)); err != nil {
+			log.Warnln(fmt.Errorf("error pinning vCPU %d to core %s: %w", i, core, err))
+		}
+	}
+	return nil
+}
+
+// qemuPid returns the pid of the QEMU process of the instance.
+func qemuPid(name string) (int, error) {
+	dir, err := InstanceDir(name)
+	if err != nil {
+		return 0, err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "qemu.pid"))
+	if err != nil {
+		return 0, fmt.Errorf("error reading qemu pid: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
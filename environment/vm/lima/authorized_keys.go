@@ -0,0 +1,36 @@
+package lima
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+)
+
+// the markers of the section of the authorized keys in the VM managed by colima.
+const (
+	authorizedKeysBegin = "# BEGIN colima ssh_authorized_keys"
+	authorizedKeysEnd   = "# END colima ssh_authorized_keys"
+)
+
+// updateAuthorizedKeys replaces the additional authorized keys of the user in the VM with the keys of conf.
+// The key of Lima is retained.
+func (l limaVM) updateAuthorizedKeys(conf config.Config) error {
+	keys, err := conf.VM.AuthorizedKeys()
+	if err != nil {
+		return err
+	}
+
+	section := ""
+	if len(keys) > 0 {
+		section = authorizedKeysBegin + "\n" + strings.Join(keys, "\n") + "\n" + authorizedKeysEnd + "\n"
+	}
+	// the section is appended from stdin
+	script := fmt.Sprintf(`f="$HOME/.ssh/authorized_keys" && sed -i '/^%s$/,/^%s$/d' "$f" && cat >> "$f"`,
+		authorizedKeysBegin, authorizedKeysEnd)
+	// not a fatal error, a warning suffices.
+	if err := l.RunWith(strings.NewReader(section), nil, "sh", "-c", script); err != nil {
+		l.Logger().Warnln(fmt.Errorf("error updating ssh authorized keys: %w", err))
+	}
+	return nil
+}
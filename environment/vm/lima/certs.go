@@ -2,12 +2,25 @@ package lima
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/util"
 )
 
+// certsInputs returns the inputs of copyCerts, the files of the registry certs on the host.
+func certsInputs() []string {
+	var files []string
+	_ = filepath.Walk(filepath.Join(util.HomeDir(), ".docker", "certs.d"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, fmt.Sprint(path, info.Size(), info.ModTime().Unix()))
+		}
+		return nil
+	})
+	return files
+}
+
 func (l limaVM) copyCerts() error {
 	log := l.Logger()
 	err := func() error {
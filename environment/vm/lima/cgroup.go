@@ -0,0 +1,43 @@
+package lima
+
+import (
+	"fmt"
+
+	"github.com/abiosoft/colima/config"
+)
+
+// cgroupModes are the OpenRC cgroup modes of the cgroup versions.
+var cgroupModes = map[string]string{
+	config.CgroupV1: "legacy",
+	config.CgroupV2: "unified",
+}
+
+// applyCgroup sets the cgroup version of conf in the VM, the VM is restarted if it changes.
+// The cgroup version of the VM image is left unchanged if unset.
+func (l limaVM) applyCgroup(conf config.Config) error {
+	mode, ok := cgroupModes[conf.VM.Cgroup]
+	if !ok {
+		return nil
+	}
+	setting := fmt.Sprintf(`rc_cgroup_mode="%s"`, mode)
+	if l.RunQuiet("grep", "-qx", setting, "/etc/rc.conf") == nil {
+		return nil
+	}
+
+	log := l.Logger()
+	log.Println("switching to cgroup", conf.VM.Cgroup)
+	script := fmt.Sprintf(`sed -i '/^#\?rc_cgroup_mode=/d' /etc/rc.conf && echo '%s' >> /etc/rc.conf`, setting)
+	if err := l.RunQuiet("sudo", "sh", "-c", script); err != nil {
+		return fmt.Errorf("error setting cgroup version: %w", err)
+	}
+
+	// cgroups are mounted on boot
+	log.Println("restarting VM to apply cgroup", conf.VM.Cgroup)
+	if err := l.host.Run(limactl, "stop", config.Profile().ID); err != nil {
+		return fmt.Errorf("error restarting VM: %w", err)
+	}
+	if err := l.limactlStart(conf, config.Profile().ID); err != nil {
+		return fmt.Errorf("error restarting VM: %w", err)
+	}
+	return nil
+}
@@ -3,12 +3,19 @@ package lima
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/util/yamlutil"
+	"gopkg.in/yaml.v3"
 )
 
 // InstanceInfo is the information about a Lima instance
@@ -23,7 +30,9 @@ type InstanceInfo struct {
 		VNL       string `json:"vnl,omitempty"`
 		Interface string `json:"interface,omitempty"`
 	} `json:"network,omitempty"`
-	IPAddress string `json:"-"`
+	SSHPort   int    `json:"sshLocalPort,omitempty"`
+	IPAddress string `json:"address,omitempty"`
+	Runtime   string `json:"runtime,omitempty"`
 }
 
 // Instances returns Lima instances created by colima.
@@ -59,12 +68,33 @@ func Instances() ([]InstanceInfo, error) {
 		// rename to local friendly names
 		i.Name = toUserFriendlyName(i.Name)
 
+		if conf, err := config.LoadProfile(i.Name); err == nil {
+			i.Runtime = conf.Runtime
+		}
+
 		instances = append(instances, i)
 	}
 
 	return instances, nil
 }
 
+// Instance returns the Lima instance for profile.
+func Instance(profile string) (InstanceInfo, error) {
+	profile = toUserFriendlyName(profile)
+
+	instances, err := Instances()
+	if err != nil {
+		return InstanceInfo{}, err
+	}
+	for _, instance := range instances {
+		if instance.Name == profile {
+			return instance, nil
+		}
+	}
+
+	return InstanceInfo{}, fmt.Errorf("instance '%s' not found", profile)
+}
+
 func getIPAddress(profile, interfaceName string) string {
 	var buf bytes.Buffer
 	// TODO: this should be cleaner
@@ -100,6 +130,70 @@ func IPAddress(profile string) string {
 	return fallback
 }
 
+// SSHConfig returns the SSH config of the instance, with a Host block for the aliases.
+func SSHConfig(name string, aliases ...string) (string, error) {
+	var buf bytes.Buffer
+	cmd := cli.Command("limactl", "show-ssh", "--format", "config", name)
+	cmd.Stdout = &buf
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error retrieving ssh config: %w", err)
+	}
+
+	// the host keys are verified if persisted, they are stable across recreates
+	knownHosts, err := knownHostsFile(config.ProfileFromName(toUserFriendlyName(name)))
+	if err == nil {
+		if _, err := os.Stat(knownHosts); err != nil {
+			knownHosts = ""
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		option := strings.Fields(line)
+		switch {
+		case strings.TrimSpace(line) == "Host lima-"+name:
+			lines = append(lines, "Host "+strings.Join(aliases, " "))
+			if knownHosts != "" {
+				lines = append(lines,
+					"  HostKeyAlias "+name,
+					"  UserKnownHostsFile \""+knownHosts+"\"",
+					"  StrictHostKeyChecking yes",
+					"  NoHostAuthenticationForLocalhost no",
+				)
+			}
+		case knownHosts != "" && len(option) > 0 && isHostKeyOption(option[0]):
+			// replaced above, the first obtained value is used by ssh
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// isHostKeyOption returns if the SSH config option is for the verification of host keys.
+func isHostKeyOption(option string) bool {
+	switch strings.ToLower(option) {
+	case "hostkeyalias", "userknownhostsfile", "stricthostkeychecking", "nohostauthenticationforlocalhost":
+		return true
+	}
+	return false
+}
+
+// SSHConfigFile writes the SSH config of the instance of the current profile, with the ID of the profile
+// as the host, and returns the path to the file.
+func SSHConfigFile() (string, error) {
+	block, err := SSHConfig(config.Profile().ID, config.Profile().ID)
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(config.Dir(), "ssh_config")
+	if err := os.WriteFile(file, []byte(block), 0600); err != nil {
+		return "", fmt.Errorf("error writing ssh config: %w", err)
+	}
+	return file, nil
+}
+
 // ShowSSH runs the show-ssh command in Lima.
 func ShowSSH(name, format string) error {
 	var buf bytes.Buffer
@@ -126,3 +220,113 @@ func toUserFriendlyName(name string) string {
 	}
 	return strings.TrimPrefix(name, "colima-")
 }
+
+// RenameInstance renames the Lima instance of the profile from to the profile to, the instance must be stopped.
+// The files of the instance generated by Lima on start for the previous name are removed, the config of the
+// instance is to be written for the profile with WriteInstanceConfig.
+func RenameInstance(from, to config.ProfileInfo) error {
+	home, err := limaHome()
+	if err != nil {
+		return err
+	}
+
+	src, dst := filepath.Join(home, from.ID), filepath.Join(home, to.ID)
+	if _, err := os.Stat(src); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("instance '%s' already exists", to.ID)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("error renaming instance: %w", err)
+	}
+	// the ssh config has the previous name and paths, the sockets and pids remain after a crash
+	for _, f := range []string{"ssh.config", "ssh.sock", "ha.sock", "ha.pid", "serial.sock", "qemu.pid"} {
+		if err := os.Remove(filepath.Join(dst, f)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error renaming instance: %w", err)
+		}
+	}
+
+	// the host keys are restored for the new name when the VM is recreated
+	fromKeys, _ := hostKeysDir(from)
+	toKeys, _ := hostKeysDir(to)
+	if _, err := os.Stat(fromKeys); err == nil {
+		_ = os.RemoveAll(toKeys)
+		if err := os.Rename(fromKeys, toKeys); err != nil {
+			return fmt.Errorf("error renaming ssh host keys: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteInstanceConfig writes the Lima config of the existing instance of the current profile generated for conf,
+// e.g. for the paths of the profile after a rename. VM networking is only configured during startup.
+func WriteInstanceConfig(conf config.Config) error {
+	dir, err := InstanceDir(config.Profile().ID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	l, err := newConf(context.Background(), conf)
+	if err != nil {
+		return err
+	}
+	if err := yamlutil.WriteYAML(l, filepath.Join(dir, "lima.yaml")); err != nil {
+		return fmt.Errorf("error writing Lima config: %w", err)
+	}
+	return nil
+}
+
+// Archive writes a gzip compressed tarball of paths in the Lima instance to w.
+// paths must be absolute and exist in the instance.
+func Archive(name string, w io.Writer, paths ...string) error {
+	args := []string{"shell", name, "sudo", "tar", "-C", "/", "-czf", "-"}
+	for _, p := range paths {
+		args = append(args, strings.TrimPrefix(p, "/"))
+	}
+
+	cmd := cli.Command("limactl", args...)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error archiving %s: %w", strings.Join(paths, ", "), err)
+	}
+	return nil
+}
+
+// InstanceDir returns the directory of the Lima instance, containing its config and logs.
+func InstanceDir(name string) (string, error) {
+	home, err := limaHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, name), nil
+}
+
+// DockerSocketProxied returns if the docker socket of the running VM of profile is forwarded to the socket
+// proxied by the monitor. The forwarding is set on start, it may differ from the current config.
+func DockerSocketProxied(profile string) bool {
+	dir, err := InstanceDir(profile)
+	if err != nil {
+		return false
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "lima.yaml"))
+	if err != nil {
+		return false
+	}
+	var c struct {
+		PortForwards []PortForward `yaml:"portForwards"`
+	}
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return false
+	}
+	for _, f := range c.PortForwards {
+		if f.GuestSocket == "/var/run/docker.sock" {
+			return f.HostSocket == docker.VMSocketFile()
+		}
+	}
+	return false
+}
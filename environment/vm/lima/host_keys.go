@@ -0,0 +1,165 @@
+package lima
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+)
+
+// hostKeyTypes are the types of the SSH host keys of the VM.
+var hostKeyTypes = []string{"ed25519", "ecdsa", "rsa"}
+
+// hostKeysDir returns the directory of the SSH host keys persisted for the profile.
+// It is in the shared cache directory to survive the deletion of the profile, the cache directory
+// of the profile is mounted in the VM.
+func hostKeysDir(p config.ProfileInfo) (string, error) {
+	return filepath.Join(config.SharedCacheDir(), "ssh_host_keys", p.ID), nil
+}
+
+// migrateHostKeys moves the host keys persisted by previous versions in the cache directory of the profile to dir.
+func migrateHostKeys(p config.ProfileInfo, dir string) error {
+	cacheDir, err := config.ProfileCacheDir(p)
+	if err != nil {
+		return err
+	}
+	old := filepath.Join(cacheDir, "ssh_host_keys")
+	if _, err := os.Stat(old); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return os.RemoveAll(old)
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(old, dir); err != nil {
+		return err
+	}
+	return os.Chmod(dir, 0700)
+}
+
+// knownHostsFile returns the known_hosts file of the VM of the profile, it may not exist.
+// The host keys are listed for the profile ID, to be used as the HostKeyAlias.
+func knownHostsFile(p config.ProfileInfo) (string, error) {
+	dir, err := hostKeysDir(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "known_hosts"), nil
+}
+
+// persistHostKeys restores the SSH host keys persisted for the profile in the VM, and persists
+// the keys of a new VM. The host keys are thereby stable across recreates of the VM.
+func (l limaVM) persistHostKeys() error {
+	err := func() error {
+		dir, err := hostKeysDir(config.Profile())
+		if err != nil {
+			return err
+		}
+		if err := migrateHostKeys(config.Profile(), dir); err != nil {
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(dir, "ssh_host_ed25519_key")); err == nil {
+			if err := l.restoreHostKeys(dir); err != nil {
+				return err
+			}
+		} else if err := l.saveHostKeys(dir); err != nil {
+			return err
+		}
+		return writeKnownHosts(dir)
+	}()
+
+	// not a fatal error, a warning suffices.
+	if err != nil {
+		l.Logger().Warnln(fmt.Errorf("cannot persist ssh host keys: %w", err))
+	}
+	return nil
+}
+
+// saveHostKeys saves the host keys of the VM to dir.
+func (l limaVM) saveHostKeys(dir string) error {
+	// the shared cache directory is readable by others
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for _, t := range hostKeyTypes {
+		key := "/etc/ssh/ssh_host_" + t + "_key"
+		private, err := l.RunOutput("sudo", "cat", key)
+		if err != nil {
+			// not generated for the VM
+			continue
+		}
+		public, err := l.RunOutput("sudo", "cat", key+".pub")
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", key+".pub", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(key)), []byte(private+"\n"), 0600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(key)+".pub"), []byte(public+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreHostKeys installs the host keys in dir in the VM, if they differ from the keys of the VM.
+func (l limaVM) restoreHostKeys(dir string) error {
+	changed := false
+	for _, t := range hostKeyTypes {
+		name := "ssh_host_" + t + "_key"
+		public, err := os.ReadFile(filepath.Join(dir, name+".pub"))
+		if err != nil {
+			continue
+		}
+		current, _ := l.RunOutput("sudo", "cat", "/etc/ssh/"+name+".pub")
+		if strings.TrimSpace(current) == strings.TrimSpace(string(public)) {
+			continue
+		}
+
+		// the private keys are only readable by the user of the host
+		private, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", name, err)
+		}
+		if err := environment.WriteFile(&l, "/etc/ssh/"+name, 0600, string(private)); err != nil {
+			return fmt.Errorf("error installing %s: %w", name, err)
+		}
+		if err := environment.WriteFile(&l, "/etc/ssh/"+name+".pub", 0644, string(public)); err != nil {
+			return fmt.Errorf("error installing %s: %w", name+".pub", err)
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	// the established connections are retained
+	return l.RunQuiet("sudo", "service", "sshd", "restart")
+}
+
+// writeKnownHosts writes the known_hosts file for the public host keys in dir.
+func writeKnownHosts(dir string) error {
+	var lines []string
+	for _, t := range hostKeyTypes {
+		b, err := os.ReadFile(filepath.Join(dir, "ssh_host_"+t+"_key.pub"))
+		if err != nil {
+			continue
+		}
+		// <type> <key> [comment]
+		if fields := strings.Fields(string(b)); len(fields) >= 2 {
+			lines = append(lines, config.Profile().ID+" "+fields[0]+" "+fields[1])
+		}
+	}
+	file, err := knownHostsFile(config.Profile())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -112,7 +113,9 @@ func (l limaVM) prepareNetwork(ctx cli.Context) error {
 		if !l.network.DependenciesInstalled() {
 			log.Println("network dependencies missing")
 			log.Println("sudo password may be required for setting up network dependencies")
-			return l.network.InstallDependencies()
+			if err := l.network.InstallDependencies(); err != nil {
+				return fmt.Errorf("error installing network dependencies: %w", err)
+			}
 		}
 		return nil
 	})
@@ -130,9 +133,10 @@ func (l limaVM) prepareNetwork(ctx cli.Context) error {
 		return err
 	})
 
-	// network failure is not fatal
+	// network failure is not fatal, the VM starts without a reachable IP address
 	if err := a.Exec(); err != nil {
 		log.Warnln(fmt.Errorf("error starting network: %w", err))
+		log.Warnln("the VM has no reachable IP address, run 'colima doctor' to check the vmnet helper")
 	} else {
 		ctx.SetContext(context.WithValue(ctx, ctxKeyNetwork, true))
 	}
@@ -142,6 +146,10 @@ func (l limaVM) prepareNetwork(ctx cli.Context) error {
 
 func (l *limaVM) Start(conf config.Config) error {
 	a := l.Init()
+	if err := checkQEMUBinary(conf); err != nil {
+		return err
+	}
+	l.warnEmulated(conf)
 
 	if l.Created() {
 		return l.resume(conf)
@@ -153,26 +161,56 @@ func (l *limaVM) Start(conf config.Config) error {
 	a.Stage("creating and starting")
 	configFile := filepath.Join(os.TempDir(), config.Profile().ID+".yaml")
 
+	// a new VM is provisioned from scratch
+	a.Add(config.ResetProvision)
+
 	a.AddCtx(func(ctx cli.Context) error {
 		limaConf, err := newConf(ctx, conf)
 		if err != nil {
 			return err
 		}
+		if err := l.cacheImage(&limaConf); err != nil {
+			return err
+		}
 		return yamlutil.WriteYAML(limaConf, configFile)
 	})
 	a.Add(func() error {
-		return l.host.Run(limactl, "start", "--tty=false", configFile)
+		return l.limactlStart(conf, "--tty=false", configFile)
 	})
 	a.Add(func() error {
 		return os.Remove(configFile)
 	})
 
-	// registry certs
-	a.Add(l.copyCerts)
+	// cgroup version, first as it restarts the VM
+	a.Add(environment.Provisioned(l, "vm.cgroup", conf.VM.Cgroup, func() error { return l.applyCgroup(conf) }))
+
+	// vCPU pinning
+	a.Add(func() error { return l.applyCPUAffinity(conf) })
+
+	// independent of each other: registry certs, the watchdog to restart crashed services
+	// and the additional ssh keys
+	authorizedKeys, _ := conf.VM.AuthorizedKeys()
+	a.Parallel(
+		environment.Provisioned(l, "vm.certs", certsInputs(), l.copyCerts),
+		l.startWatchdog,
+		environment.ProvisionedBoot(l, "vm.authorized_keys", authorizedKeys, func() error { return l.updateAuthorizedKeys(conf) }),
+	)
+
+	// stable ssh host keys
+	a.Add(l.persistHostKeys)
 
 	// dns
 	l.applyDNS(a, conf)
 
+	// time synchronisation, after the dns to resolve the servers
+	a.Add(environment.ProvisionedBoot(l, "vm.ntp", conf.NTP, func() error { return l.applyNTP(conf) }))
+
+	// packages, after the dns to download them
+	a.Add(environment.ProvisionedBoot(l, "vm.packages", conf.Packages, func() error { return l.installPackages(conf) }))
+
+	// user provisioning, after the dns for the scripts to download packages
+	a.Add(func() error { return l.runProvisionScripts(conf) })
+
 	// adding it to command chain to execute only after successful startup.
 	a.Add(func() error {
 		l.conf = conf
@@ -205,13 +243,32 @@ func (l limaVM) resume(conf config.Config) error {
 
 	a.Stage("starting")
 	a.Add(func() error {
-		return l.host.Run(limactl, "start", config.Profile().ID)
+		return l.limactlStart(conf, config.Profile().ID)
 	})
 
-	// registry certs
-	a.Add(l.copyCerts)
+	// cgroup version, first as it restarts the VM
+	a.Add(environment.Provisioned(&l, "vm.cgroup", conf.VM.Cgroup, func() error { return l.applyCgroup(conf) }))
+
+	// vCPU pinning
+	a.Add(func() error { return l.applyCPUAffinity(conf) })
+
+	// independent of each other: registry certs, the watchdog to restart crashed services
+	// and the additional ssh keys
+	authorizedKeys, _ := conf.VM.AuthorizedKeys()
+	a.Parallel(
+		environment.Provisioned(&l, "vm.certs", certsInputs(), l.copyCerts),
+		l.startWatchdog,
+		environment.ProvisionedBoot(&l, "vm.authorized_keys", authorizedKeys, func() error { return l.updateAuthorizedKeys(conf) }),
+	)
+
+	// stable ssh host keys
+	a.Add(l.persistHostKeys)
 
 	l.applyDNS(a, conf)
+	a.Add(environment.ProvisionedBoot(&l, "vm.ntp", conf.NTP, func() error { return l.applyNTP(conf) }))
+
+	// packages added to the config since the VM was created
+	a.Add(environment.ProvisionedBoot(&l, "vm.packages", conf.Packages, func() error { return l.installPackages(conf) }))
 
 	return a.Exec()
 }
@@ -281,7 +338,7 @@ func (l limaVM) Stop(force bool) error {
 		if force {
 			return l.host.Run(limactl, "stop", "--force", config.Profile().ID)
 		}
-		return l.host.Run(limactl, "stop", config.Profile().ID)
+		return l.stopGraceful()
 	})
 
 	a.Add(l.network.Stop)
@@ -289,6 +346,35 @@ func (l limaVM) Stop(force bool) error {
 	return a.Exec()
 }
 
+// stopGraceful stops the VM gracefully. If the shutdown does not complete within the shutdown timeout,
+// it is cancelled and the VM is forcefully stopped.
+func (l limaVM) stopGraceful() error {
+	conf, err := config.Load()
+	if err != nil {
+		// not fatal, the default shutdown timeout is used
+		l.Logger().Warnln(err)
+	}
+	timeout := conf.VM.ShutdownDuration()
+
+	cmd := cli.Command(limactl, "stop", config.Profile().ID)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		l.Logger().Warnf("graceful shutdown did not complete after %v, forcing shutdown", timeout)
+		// the forced shutdown does not race the graceful one
+		_ = cmd.Process.Kill()
+		<-done
+		return l.host.Run(limactl, "stop", "--force", config.Profile().ID)
+	}
+}
+
 func (l limaVM) Teardown() error {
 	a := l.Init()
 
@@ -346,6 +432,18 @@ func (l limaVM) RunInteractive(args ...string) error {
 	return a.Exec()
 }
 
+func (l limaVM) RunWith(stdin io.Reader, stdout io.Writer, args ...string) error {
+	args = append([]string{lima}, args...)
+
+	a := l.Init()
+
+	a.Add(func() error {
+		return l.host.RunWith(stdin, stdout, args...)
+	})
+
+	return a.Exec()
+}
+
 func (l limaVM) RunOutput(args ...string) (out string, err error) {
 	args = append([]string{lima}, args...)
 
@@ -120,3 +120,8 @@ func (s colimaVmnetFile) Install(host environment.HostActions) error {
 	}
 	return nil
 }
+
+// RootfulFiles returns the files and directories installed as root on the host for VM networking.
+func RootfulFiles() []string {
+	return []string{sudoerFile{}.Paths()[0], "/opt/colima"}
+}
@@ -92,3 +92,8 @@ func (l launchdManager) createVmnetScript() error {
 
 	return nil
 }
+
+// LaunchdFiles returns the launchd files created for the networking of all profiles.
+func LaunchdFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(launchdManager{}.Dir(), packageNamePrefix+".*.plist"))
+}
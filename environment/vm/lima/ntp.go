@@ -0,0 +1,71 @@
+package lima
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+)
+
+// applyNTP configures the time synchronisation of the VM with the NTP servers of conf,
+// or disables it. The default configuration of the VM is left unchanged if unset.
+// Failures are not fatal, the watchdog keeps the clock in sync with the host.
+func (l limaVM) applyNTP(conf config.Config) error {
+	log := l.Logger()
+
+	if conf.NTP.Disabled {
+		// the ntp client of the image may be chrony or busybox ntpd
+		for _, service := range []string{"chronyd", "ntpd"} {
+			if l.RunQuiet("rc-service", "-e", service) == nil {
+				_ = l.RunQuiet("sudo", "rc-service", service, "stop")
+				_ = l.RunQuiet("sudo", "rc-update", "del", service, "default")
+			}
+		}
+		return nil
+	}
+	if len(conf.NTP.Servers) == 0 {
+		return nil
+	}
+
+	if err := l.configureChrony(conf.NTP.Servers); err != nil {
+		log.Warnln(fmt.Errorf("error configuring NTP servers: %w", err))
+	}
+	return nil
+}
+
+func (l limaVM) configureChrony(servers []string) error {
+	if l.RunQuiet("rc-service", "-e", "chronyd") != nil {
+		err := cli.DefaultBackoff.Do(l.Logger(), "installation of chrony", func() error {
+			return l.RunQuiet("sudo", "apk", "add", "--no-cache", "chrony")
+		})
+		if err != nil {
+			return fmt.Errorf("error installing chrony: %w", err)
+		}
+	}
+	// chrony replaces busybox ntpd
+	if l.RunQuiet("rc-service", "-e", "ntpd") == nil {
+		_ = l.RunQuiet("sudo", "rc-service", "ntpd", "stop")
+		_ = l.RunQuiet("sudo", "rc-update", "del", "ntpd", "default")
+	}
+
+	lines := []string{"# managed by colima, set ntp.servers in the colima config to change"}
+	for _, s := range servers {
+		lines = append(lines, "server "+s+" iburst")
+	}
+	lines = append(lines,
+		"driftfile /var/lib/chrony/chrony.drift",
+		// the clock may be far off after the VM is paused or the host sleeps
+		"makestep 1.0 -1",
+		"rtcsync",
+	)
+
+	if err := environment.WriteFile(&l, "/etc/chrony/chrony.conf", 0644, strings.Join(lines, "\n")+"\n"); err != nil {
+		return err
+	}
+	if err := l.RunQuiet("sudo", "rc-update", "add", "chronyd", "default"); err != nil {
+		return err
+	}
+	return l.RunQuiet("sudo", "rc-service", "chronyd", "restart")
+}
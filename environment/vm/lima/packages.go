@@ -0,0 +1,75 @@
+package lima
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+)
+
+// packagesCacheDir is the apk cache directory in the VM for the packages in the config.
+const packagesCacheDir = "/var/cache/colima/apk"
+
+// packagesArchive is the file the downloaded packages are kept in, to not download them again when the VM is recreated.
+// It is in the cache directory which outlives the VM and is mounted in the VM.
+func packagesArchive() string {
+	return filepath.Join(config.CacheDir(), "packages.tar.gz")
+}
+
+// installPackages installs the packages of conf that are not installed in the VM.
+func (l limaVM) installPackages(conf config.Config) error {
+	if len(conf.Packages) == 0 {
+		return nil
+	}
+	// already installed
+	if l.RunQuiet(append([]string{"apk", "info", "-e"}, conf.Packages...)...) == nil {
+		return nil
+	}
+
+	log := l.Logger()
+	log.Println("installing packages")
+
+	archive := packagesArchive()
+	if _, err := os.Stat(archive); err == nil {
+		if err := l.RunQuiet("sudo", "tar", "-C", "/", "-xzf", archive); err != nil {
+			log.Warnln(fmt.Errorf("error restoring package cache: %w", err))
+		}
+	}
+
+	if err := l.RunQuiet("sudo", "mkdir", "-p", packagesCacheDir); err != nil {
+		return fmt.Errorf("error creating package cache: %w", err)
+	}
+	args := append([]string{"sudo", "apk", "add", "--cache-dir", packagesCacheDir}, conf.Packages...)
+	if err := cli.DefaultBackoff.Do(log, "installation of packages", func() error { return l.Run(args...) }); err != nil {
+		return fmt.Errorf("error installing packages: %w", err)
+	}
+
+	// remove the packages no longer required before keeping the cache
+	_ = l.RunQuiet("sudo", "apk", "cache", "--cache-dir", packagesCacheDir, "clean")
+	if err := l.keepPackages(); err != nil {
+		// not fatal, the packages are downloaded again
+		log.Warnln(err)
+	}
+	return nil
+}
+
+// keepPackages archives the package cache of the VM to the host.
+func (l limaVM) keepPackages() error {
+	tmp := packagesArchive() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error creating package cache archive: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	if err := Archive(config.Profile().ID, f, packagesCacheDir); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing package cache archive: %w", err)
+	}
+	return os.Rename(tmp, packagesArchive())
+}
@@ -0,0 +1,66 @@
+package lima
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util/cloudinit"
+	"github.com/sirupsen/logrus"
+)
+
+// provisionScripts returns the provision scripts of conf, including the scripts of the cloud-init user-data,
+// and the unsupported cloud-init modules.
+func provisionScripts(conf config.Config) (scripts []config.Provision, unsupported []string) {
+	if conf.VM.CloudInit == "" {
+		return conf.Provision, nil
+	}
+	userData, err := cloudinit.Parse(conf.VM.CloudInit)
+	if err != nil {
+		// not fatal, the file may have been removed after the VM was created
+		logrus.Warnln(err)
+		return conf.Provision, nil
+	}
+
+	if s := userData.BootScript(); s != "" {
+		scripts = append(scripts, config.Provision{Mode: config.ProvisionModeSystem, Script: s, EveryBoot: true})
+	}
+	if s := userData.CreateScript(); s != "" {
+		scripts = append(scripts, config.Provision{Mode: config.ProvisionModeSystem, Script: s})
+	}
+	return append(scripts, conf.Provision...), userData.Unsupported
+}
+
+// runProvisionScripts runs the provision scripts of conf that are only run when the VM is created.
+// The scripts run on every boot are run by Lima.
+func (l limaVM) runProvisionScripts(conf config.Config) error {
+	log := l.Logger()
+	scripts, unsupported := provisionScripts(conf)
+	if len(unsupported) > 0 {
+		log.Warnln("cloud-init modules not supported and ignored:", strings.Join(unsupported, ", "))
+	}
+	for i, p := range scripts {
+		if p.EveryBoot {
+			continue
+		}
+		n := strconv.Itoa(i + 1)
+		log.Println("running provision script", n)
+
+		script := "/tmp/colima-provision-" + n
+		if err := environment.WriteFile(&l, script, 0755, p.Script); err != nil {
+			return fmt.Errorf("error copying provision script %s: %w", n, err)
+		}
+
+		args := []string{script}
+		if p.Mode != config.ProvisionModeUser {
+			args = append([]string{"sudo"}, args...)
+		}
+		if err := l.Run(args...); err != nil {
+			return fmt.Errorf("error running provision script %s: %w", n, err)
+		}
+		_ = l.RunQuiet("sudo", "rm", "-f", script)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package lima
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util"
+	"github.com/abiosoft/colima/util/release"
+)
+
+// minQEMUVersion is the minimum supported version of QEMU.
+const minQEMUVersion = "6.1.0"
+
+// warnEmulated warns that the VM is emulated if its architecture differs from the host.
+func (l limaVM) warnEmulated(conf config.Config) {
+	arch := environment.Arch(conf.VM.Arch).Value()
+	if !arch.Emulated() {
+		return
+	}
+	l.Logger().Warnf("the %s VM is emulated on the %s host and is significantly slower, "+
+		"use the %s architecture unless %s is required", arch, environment.HostArch(), environment.HostArch(), arch)
+}
+
+// vmArch returns the architecture of the VM of conf.
+func vmArch(conf config.Config) environment.Arch {
+	if arch := environment.Arch(conf.VM.Arch).Value(); arch != "default" {
+		return arch
+	}
+	return environment.HostArch()
+}
+
+// QEMUBinary returns the QEMU binary for the VM of conf, looked up in the PATH unless configured.
+func QEMUBinary(conf config.Config) string {
+	if conf.VM.QEMUBinary != "" {
+		return conf.VM.QEMUBinary
+	}
+	return "qemu-system-" + string(vmArch(conf))
+}
+
+// CheckQEMU returns an error if the QEMU binary for the VM of conf is not found, is older than
+// the minimum version or does not support the accelerator of the VM.
+func CheckQEMU(conf config.Config) error {
+	binary := QEMUBinary(conf)
+	if _, err := exec.LookPath(binary); err != nil {
+		return fmt.Errorf("qemu binary '%s' not found", binary)
+	}
+
+	out, err := exec.Command(binary, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("error retrieving qemu version of '%s': %w", binary, err)
+	}
+	// QEMU emulator version 6.2.0 (Debian 1:6.2+dfsg-2ubuntu6)
+	version, err := release.OutputVersion(string(out))
+	if err != nil {
+		return fmt.Errorf("error retrieving qemu version of '%s': %w", binary, err)
+	}
+	older, err := release.Older(version, minQEMUVersion)
+	if err != nil {
+		return fmt.Errorf("error retrieving qemu version of '%s': %w", binary, err)
+	}
+	if older {
+		return fmt.Errorf("qemu version %s of '%s' is not supported, minimum is %s", version, binary, minQEMUVersion)
+	}
+
+	accel := "tcg"
+	if !vmArch(conf).Emulated() {
+		accel = "kvm"
+		if runtime.GOOS == "darwin" {
+			accel = "hvf"
+		}
+	}
+	out, err = exec.Command(binary, "-accel", "help").Output()
+	if err != nil {
+		return fmt.Errorf("error retrieving qemu accelerators of '%s': %w", binary, err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == accel {
+			return nil
+		}
+	}
+	return fmt.Errorf("qemu binary '%s' does not support the %s accelerator", binary, accel)
+}
+
+// qemuEnv returns the environment of limactl for the QEMU binary and the tuning of the emulation of conf.
+// Lima runs the QEMU binary in QEMU_SYSTEM_<ARCH> and does not override the -accel option set there.
+func qemuEnv(conf config.Config) []string {
+	arch := vmArch(conf)
+
+	var args []string
+	if arch.Emulated() && !conf.VM.TCG.Empty() {
+		accel := "tcg"
+		if conf.VM.TCG.Thread != "" {
+			accel += ",thread=" + conf.VM.TCG.Thread
+		}
+		if conf.VM.TCG.TBSize > 0 {
+			accel += fmt.Sprintf(",tb-size=%d", conf.VM.TCG.TBSize)
+		}
+		args = append(args, "-accel", accel)
+	}
+	if conf.VM.QEMUBinary == "" && len(args) == 0 {
+		return nil
+	}
+
+	// quoted for the shell-style parsing of Lima
+	binary := util.ShellQuote(QEMUBinary(conf))
+	env := []string{fmt.Sprintf("QEMU_SYSTEM_%s=%s", strings.ToUpper(string(arch)), strings.Join(append([]string{binary}, args...), " "))}
+	if conf.VM.QEMUBinary != "" {
+		// qemu-img of the same build for the disks
+		env = append(env, "PATH="+filepath.Dir(conf.VM.QEMUBinary)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+	return env
+}
+
+// limactlStart runs limactl start with args and the QEMU settings of conf.
+func (l limaVM) limactlStart(conf config.Config, args ...string) error {
+	args = append([]string{limactl, "start"}, args...)
+	return l.host.WithEnv(qemuEnv(conf)...).Run(args...)
+}
+
+// checkQEMUBinary checks the QEMU binary of conf, if configured.
+func checkQEMUBinary(conf config.Config) error {
+	if conf.VM.QEMUBinary == "" {
+		return nil
+	}
+	if err := CheckQEMU(conf); err != nil {
+		return cli.NewError(cli.ExitDependency, err, "check 'vm.qemu_binary' in the config, or run 'colima doctor'")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package lima
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"time"
+)
+
+// Pause suspends the execution of the VM of the instance, the memory is retained.
+func Pause(name string) error {
+	_, err := qmp(name, "stop")
+	if err != nil {
+		return fmt.Errorf("error pausing vm: %w", err)
+	}
+	return nil
+}
+
+// Resume resumes the execution of a paused VM of the instance.
+func Resume(name string) error {
+	_, err := qmp(name, "cont")
+	if err != nil {
+		return fmt.Errorf("error resuming vm: %w", err)
+	}
+	return nil
+}
+
+// Paused returns if the VM of the instance is paused.
+func Paused(name string) bool {
+	out, err := qmp(name, "query-status")
+	if err != nil {
+		return false
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(out, &status); err != nil {
+		return false
+	}
+	return status.Status == "paused"
+}
+
+// qmp executes the command with the QEMU Machine Protocol socket of the instance
+// and returns the response.
+func qmp(name, command string) (json.RawMessage, error) {
+	dir, err := InstanceDir(name)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", filepath.Join(dir, "qmp.sock"), 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to qemu: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	// greeting
+	var greeting json.RawMessage
+	if err := dec.Decode(&greeting); err != nil {
+		return nil, fmt.Errorf("error reading qemu greeting: %w", err)
+	}
+
+	execute := func(command string) (json.RawMessage, error) {
+		if err := enc.Encode(map[string]string{"execute": command}); err != nil {
+			return nil, err
+		}
+		for {
+			var resp struct {
+				Event  string          `json:"event"`
+				Return json.RawMessage `json:"return"`
+				Error  *struct {
+					Desc string `json:"desc"`
+				} `json:"error"`
+			}
+			if err := dec.Decode(&resp); err != nil {
+				return nil, err
+			}
+			// asynchronous events may precede the response
+			if resp.Event != "" {
+				continue
+			}
+			if resp.Error != nil {
+				return nil, fmt.Errorf("qemu error for '%s': %s", command, resp.Error.Desc)
+			}
+			return resp.Return, nil
+		}
+	}
+
+	// capabilities negotiation is required before commands are accepted
+	if _, err := execute("qmp_capabilities"); err != nil {
+		return nil, err
+	}
+	return execute(command)
+}
+
+// vcpuThreads returns the host thread ids of the vCPUs of the VM of the instance, by vCPU index.
+func vcpuThreads(name string) ([]int, error) {
+	out, err := qmp(name, "query-cpus-fast")
+	if err != nil {
+		return nil, err
+	}
+	var cpus []struct {
+		Index    int `json:"cpu-index"`
+		ThreadID int `json:"thread-id"`
+	}
+	if err := json.Unmarshal(out, &cpus); err != nil {
+		return nil, fmt.Errorf("error decoding vCPUs: %w", err)
+	}
+	threads := make([]int, len(cpus))
+	for i, c := range cpus {
+		threads[i] = c.ThreadID
+		if c.Index >= 0 && c.Index < len(threads) {
+			threads[c.Index] = c.ThreadID
+		}
+	}
+	return threads, nil
+}
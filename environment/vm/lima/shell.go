@@ -0,0 +1,58 @@
+package lima
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+)
+
+// SSH runs the command interactively in the directory dir of the VM, or starts a login shell
+// if no command is specified. dir defaults to the current directory if mounted, or the home directory.
+func (l limaVM) SSH(dir string, args ...string) error {
+	shell := []string{limactl, "shell"}
+	if dir != "" {
+		shell = append(shell, "--workdir", dir)
+	}
+	args = append(append(shell, config.Profile().ID), args...)
+
+	a := l.Init()
+
+	a.Add(func() error {
+		return l.host.RunInteractive(args...)
+	})
+
+	return a.Exec()
+}
+
+// GuestDir returns the path in the VM of the host directory dir, if it is mounted with conf.
+func GuestDir(conf config.Config, dir string) (string, bool) {
+	mounts := conf.VM.Mounts
+	if len(mounts) == 0 {
+		mounts = []string{"~", filepath.Join("/tmp", config.Profile().ID)}
+	}
+
+	for _, m := range mounts {
+		location, err := volumeMount(m).Path()
+		if err != nil {
+			continue
+		}
+		location = strings.TrimSuffix(location, "/")
+
+		// the directory may be reported with the symlinks of the mount resolved
+		// e.g. /private/tmp for /tmp on macOS
+		candidates := []string{location}
+		if resolved, err := filepath.EvalSymlinks(location); err == nil && resolved != location {
+			candidates = append(candidates, resolved)
+		}
+		for _, c := range candidates {
+			rel, err := filepath.Rel(c, dir)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+				continue
+			}
+			// mounted at the same path in the VM
+			return filepath.Join(location, rel), true
+		}
+	}
+	return "", false
+}
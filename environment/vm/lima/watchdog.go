@@ -0,0 +1,43 @@
+package lima
+
+import (
+	"fmt"
+
+	"github.com/abiosoft/colima/embedded"
+	"github.com/abiosoft/colima/environment"
+)
+
+// WatchdogService is the service of the watchdog in the VM.
+const WatchdogService = "colima-watchdog"
+
+// WatchdogLogFile is the file in the VM the restarts by the watchdog are logged to.
+const WatchdogLogFile = "/var/log/colima-watchdog.log"
+
+// startWatchdog installs and (re)starts the watchdog service that restarts
+// crashed container runtime and Kubernetes services.
+func (l limaVM) startWatchdog() error {
+	log := l.Logger()
+	err := func() error {
+		files := map[string]string{
+			"watchdog/watchdog.sh": "/usr/local/bin/colima-watchdog",
+			"watchdog/init.sh":     "/etc/init.d/" + WatchdogService,
+		}
+		for src, dst := range files {
+			body, err := embedded.ReadString(src)
+			if err != nil {
+				return err
+			}
+			if err := environment.WriteFile(&l, dst, 0755, body); err != nil {
+				return err
+			}
+		}
+		// restart to apply updates to the script
+		return l.RunQuiet("sudo", "service", WatchdogService, "restart")
+	}()
+
+	// not a fatal error, a warning suffices.
+	if err != nil {
+		log.Warnln(fmt.Errorf("cannot start watchdog: %w", err))
+	}
+	return nil
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/abiosoft/colima/environment/container/docker"
 	"github.com/abiosoft/colima/environment/vm/lima/network"
 	"github.com/abiosoft/colima/util"
+	"github.com/abiosoft/colima/util/downloader"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
 func newConf(ctx context.Context, conf config.Config) (l Config, err error) {
@@ -30,7 +32,7 @@ func newConf(ctx context.Context, conf config.Config) (l Config, err error) {
 	l.Memory = fmt.Sprintf("%dGiB", conf.VM.Memory)
 	l.Disk = fmt.Sprintf("%dGiB", conf.VM.Disk)
 
-	l.SSH = SSH{LocalPort: 0, LoadDotSSHPubKeys: false, ForwardAgent: conf.VM.ForwardAgent}
+	l.SSH = SSH{LocalPort: conf.VM.SSHPort, LoadDotSSHPubKeys: false, ForwardAgent: conf.VM.ForwardAgent}
 	l.Containerd = Containerd{System: false, User: false}
 	l.Firmware.LegacyBIOS = false
 
@@ -46,6 +48,10 @@ func newConf(ctx context.Context, conf config.Config) (l Config, err error) {
 	for k, v := range conf.VM.Env {
 		l.Env[k] = v
 	}
+	// the locale of the host, tools in the VM may assume a UTF-8 locale
+	if _, ok := l.Env["LANG"]; !ok {
+		l.Env["LANG"] = util.HostLocale()
+	}
 
 	// add user to docker group
 	// "sudo", "usermod", "-aG", "docker", user
@@ -54,6 +60,19 @@ func newConf(ctx context.Context, conf config.Config) (l Config, err error) {
 		Script: `sudo usermod -aG docker $USER`,
 	})
 
+	// provision scripts run by Lima on every boot, the others are run on create
+	scripts, _ := provisionScripts(conf)
+	for _, p := range scripts {
+		if !p.EveryBoot {
+			continue
+		}
+		mode := ProvisionModeSystem
+		if p.Mode == config.ProvisionModeUser {
+			mode = ProvisionModeUser
+		}
+		l.Provision = append(l.Provision, Provision{Mode: mode, Script: p.Script})
+	}
+
 	// networking on Lima is limited to macOS
 	networkEnabled, _ := ctx.Value(ctxKeyNetwork).(bool)
 	if runtime.GOOS == "darwin" && networkEnabled {
@@ -92,10 +111,15 @@ func newConf(ctx context.Context, conf config.Config) (l Config, err error) {
 	{
 		// docker socket
 		if conf.Runtime == docker.Name {
+			// the monitor proxies the host socket to resume or start the VM on connection
+			hostSocket := docker.HostSocketFile()
+			if conf.Idle.Enabled() || conf.LazyStart {
+				hostSocket = docker.VMSocketFile()
+			}
 			l.PortForwards = append(l.PortForwards,
 				PortForward{
 					GuestSocket: "/var/run/docker.sock",
-					HostSocket:  docker.HostSocketFile(),
+					HostSocket:  hostSocket,
 					Proto:       TCP,
 				})
 		}
@@ -159,6 +183,35 @@ func newConf(ctx context.Context, conf config.Config) (l Config, err error) {
 	return
 }
 
+// cacheImage downloads the image of the VM into the artifact cache shared by all profiles, Lima boots the cached file.
+func (l limaVM) cacheImage(c *Config) error {
+	for i, image := range c.Images {
+		if image.Arch != c.Arch {
+			continue
+		}
+		file, err := downloader.CacheArtifact(l.host, image.Location, image.Digest)
+		if err != nil {
+			return fmt.Errorf("error downloading the VM image: %w", err)
+		}
+		c.Images[i].Location = file
+	}
+	return nil
+}
+
+// Spec returns the Lima config generated for conf, encoded as YAML.
+// VM networking is excluded as it is only configured during startup.
+func Spec(conf config.Config) ([]byte, error) {
+	l, err := newConf(context.Background(), conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := yaml.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding Lima config: %w", err)
+	}
+	return b, nil
+}
+
 // Config is lima config. Code copied from lima and modified.
 type Config struct {
 	Arch         environment.Arch  `yaml:"arch,omitempty"`
@@ -0,0 +1,351 @@
+// Package remote implements the VM of a profile with a remote Linux machine, reached over SSH.
+//
+// The machine is not managed by colima, it is never shut down. Starting and stopping the profile
+// starts and stops the container runtime, the docker socket is forwarded to the host by the monitor.
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/util"
+)
+
+// dockerInstallScript installs docker on the remote host if missing, with remote_install.
+const dockerInstallScript = "https://get.docker.com"
+
+// New creates the VM of the remote host at address, user@host[:port].
+func New(host environment.HostActions, address string) environment.VM {
+	return &remoteVM{
+		host:         host,
+		address:      address,
+		CommandChain: cli.New("remote"),
+	}
+}
+
+var _ environment.VM = (*remoteVM)(nil)
+var _ environment.Systemd = (*remoteVM)(nil)
+
+type remoteVM struct {
+	host environment.HostActions
+	cli.CommandChain
+
+	// address is the ssh destination, user@host[:port].
+	address string
+}
+
+// createdFile marks the profile as created on the remote host, runningFile as started.
+func createdFile() string { return filepath.Join(config.Dir(), "remote") }
+func runningFile() string { return filepath.Join(config.Dir(), "remote.running") }
+
+// SSHConfigFile writes the SSH config of the remote host at address, with the ID of the profile
+// as the host, and returns the path to the file.
+func SSHConfigFile(address string) (string, error) {
+	r, err := config.ParseRemote(address)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{
+		"Host " + config.Profile().ID,
+		"  HostName " + r.Host,
+		"  User " + r.User,
+		"  Port " + strconv.Itoa(r.Port),
+		// the commands in the VM are run non-interactively, keys or an agent are required
+		"  BatchMode yes",
+		// a connection shared by the commands of the profile
+		"  ControlMaster auto",
+		"  ControlPath \"" + filepath.Join(config.Dir(), "ssh.sock") + "\"",
+		"  ControlPersist 5m",
+	}
+	file := filepath.Join(config.Dir(), "ssh_config")
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("error writing ssh config: %w", err)
+	}
+	return file, nil
+}
+
+// ssh returns the ssh command for the remote host, with args as the command.
+// The arguments are quoted, they are passed as is as with the VM.
+func (r remoteVM) ssh(flags []string, args ...string) ([]string, error) {
+	sshConfig, err := SSHConfigFile(r.address)
+	if err != nil {
+		return nil, err
+	}
+	command := append([]string{"ssh", "-F", sshConfig}, flags...)
+	command = append(command, config.Profile().ID)
+	if len(args) > 0 {
+		var quoted []string
+		for _, a := range args {
+			quoted = append(quoted, util.ShellQuote(a))
+		}
+		command = append(command, "--", strings.Join(quoted, " "))
+	}
+	return command, nil
+}
+
+func (r remoteVM) Dependencies() []string {
+	return []string{
+		"ssh",
+	}
+}
+
+func (r remoteVM) Host() environment.HostActions {
+	return r.host
+}
+
+func (r *remoteVM) Start(conf config.Config) error {
+	a := r.Init()
+
+	a.Stage("connecting to " + r.address)
+	a.Add(func() error {
+		if err := r.RunQuiet("true"); err != nil {
+			return cli.NewError(cli.ExitVM, fmt.Errorf("error connecting to %s: %w", r.address, err),
+				"key based ssh access to the remote host is required, check with 'ssh "+r.address+"'")
+		}
+		return nil
+	})
+	a.Add(func() error {
+		if kernel, _ := r.RunOutput("uname", "-s"); kernel != "Linux" {
+			return fmt.Errorf("remote host %s is not a Linux machine", r.address)
+		}
+		if err := r.RunQuiet("sudo", "-n", "true"); err != nil {
+			return fmt.Errorf("passwordless sudo is required on the remote host %s", r.address)
+		}
+		return nil
+	})
+
+	a.Add(func() error {
+		if r.RunQuiet("command", "-v", "docker") == nil {
+			return nil
+		}
+		if !conf.RemoteInstall {
+			return cli.NewError(cli.ExitDependency, fmt.Errorf("docker is not installed on the remote host %s", r.address),
+				"install docker on the remote host, or start with --remote-install to install it with "+dockerInstallScript)
+		}
+		r.Logger().Println("installing docker on", r.address, "with", dockerInstallScript)
+		return r.Run("sh", "-c", "curl -fsSL "+dockerInstallScript+" | sudo sh")
+	})
+
+	a.Add(func() error {
+		if err := os.WriteFile(createdFile(), []byte(r.address), 0644); err != nil {
+			return fmt.Errorf("error saving remote host: %w", err)
+		}
+		return os.WriteFile(runningFile(), nil, 0644)
+	})
+
+	return a.Exec()
+}
+
+// Stop marks the profile as stopped, the remote host is left running.
+func (r remoteVM) Stop(force bool) error {
+	if err := os.Remove(runningFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error stopping remote: %w", err)
+	}
+	r.closeConnection()
+	return nil
+}
+
+// Restart reopens the ssh connection, for changes of the groups of the user to apply.
+func (r remoteVM) Restart() error {
+	r.closeConnection()
+	return nil
+}
+
+// closeConnection closes the shared ssh connection, if open.
+func (r remoteVM) closeConnection() {
+	if args, err := r.ssh([]string{"-O", "exit"}); err == nil {
+		_ = r.host.RunQuiet(args...)
+	}
+}
+
+func (r remoteVM) Teardown() error {
+	a := r.Init()
+
+	a.Stage("deleting")
+
+	// the settings of the profile on the remote host, the runtime is left installed
+	a.Add(func() error {
+		if err := r.RunQuiet("sudo", "rm", "-f", configFile()); err != nil {
+			r.Logger().Warnln(fmt.Errorf("error removing settings from %s: %w", r.address, err))
+		}
+		r.closeConnection()
+		return nil
+	})
+
+	return a.Exec()
+}
+
+func (r remoteVM) Created() bool {
+	_, err := os.Stat(createdFile())
+	return err == nil
+}
+
+func (r remoteVM) Running() bool {
+	if _, err := os.Stat(runningFile()); err != nil {
+		return false
+	}
+	return r.RunQuiet("true") == nil
+}
+
+func (r remoteVM) Run(args ...string) error {
+	args, err := r.ssh(nil, args...)
+	if err != nil {
+		return err
+	}
+
+	a := r.Init()
+
+	a.Add(func() error {
+		return r.host.Run(args...)
+	})
+
+	return a.Exec()
+}
+
+func (r remoteVM) RunInteractive(args ...string) error {
+	args, err := r.ssh([]string{"-t"}, args...)
+	if err != nil {
+		return err
+	}
+
+	a := r.Init()
+
+	a.Add(func() error {
+		return r.host.RunInteractive(args...)
+	})
+
+	return a.Exec()
+}
+
+func (r remoteVM) RunWith(stdin io.Reader, stdout io.Writer, args ...string) error {
+	args, err := r.ssh(nil, args...)
+	if err != nil {
+		return err
+	}
+
+	a := r.Init()
+
+	a.Add(func() error {
+		return r.host.RunWith(stdin, stdout, args...)
+	})
+
+	return a.Exec()
+}
+
+func (r remoteVM) RunOutput(args ...string) (out string, err error) {
+	args, err = r.ssh(nil, args...)
+	if err != nil {
+		return "", err
+	}
+
+	a := r.Init()
+
+	a.Add(func() (err error) {
+		out, err = r.host.RunOutput(args...)
+		return
+	})
+
+	err = a.Exec()
+	return
+}
+
+func (r remoteVM) RunQuiet(args ...string) (err error) {
+	args, err = r.ssh(nil, args...)
+	if err != nil {
+		return err
+	}
+
+	a := r.Init()
+
+	a.Add(func() (err error) {
+		return r.host.RunQuiet(args...)
+	})
+
+	err = a.Exec()
+	return
+}
+
+// SSH runs the command interactively on the remote host, or starts a login shell
+// if no command is specified. The host directories are not mounted, dir is used if set.
+func (r remoteVM) SSH(dir string, args ...string) error {
+	if dir == "" {
+		return r.RunInteractive(args...)
+	}
+	command := `exec "$SHELL" -l`
+	if len(args) > 0 {
+		command = `exec "$@"`
+	}
+	return r.RunInteractive(append([]string{"sh", "-c", "cd " + util.ShellQuote(dir) + " && " + command, "sh"}, args...)...)
+}
+
+func (r remoteVM) Env(s string) (string, error) {
+	if !r.Running() {
+		return "", fmt.Errorf("not running")
+	}
+	return r.RunOutput("sh", "-c", "echo $"+s)
+}
+
+// configFile is the settings file of the profile on the remote host, a host may back several profiles.
+func configFile() string { return "/etc/colima/" + config.Profile().ID + ".json" }
+
+func (r remoteVM) getConf() map[string]string {
+	obj := map[string]string{}
+	b, err := r.RunOutput("cat", configFile())
+	if err != nil {
+		return obj
+	}
+
+	// we do not care if it fails
+	_ = json.Unmarshal([]byte(b), &obj)
+
+	return obj
+}
+
+func (r remoteVM) Get(key string) string {
+	if val, ok := r.getConf()[key]; ok {
+		return val
+	}
+
+	return ""
+}
+
+func (r remoteVM) Set(key, value string) error {
+	obj := r.getConf()
+	obj[key] = value
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("error marshalling settings to json: %w", err)
+	}
+
+	if err := r.Run("sudo", "mkdir", "-p", filepath.Dir(configFile())); err != nil {
+		return fmt.Errorf("error saving settings: %w", err)
+	}
+	if err := r.Run("sudo", "sh", "-c", fmt.Sprintf(`echo %s > %s`, util.ShellQuote(string(b)), configFile())); err != nil {
+		return fmt.Errorf("error saving settings: %w", err)
+	}
+
+	return nil
+}
+
+// Systemd returns if the services of the remote host are managed by systemd, as on most distributions.
+func (r remoteVM) Systemd() bool {
+	return r.RunQuiet("test", "-d", "/run/systemd/system") == nil
+}
+
+func (r remoteVM) User() (string, error) {
+	return r.RunOutput("whoami")
+}
+
+func (r remoteVM) Arch() environment.Arch {
+	a, _ := r.RunOutput("uname", "-m")
+	return environment.Arch(a)
+}
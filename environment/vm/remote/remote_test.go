@@ -0,0 +1,94 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/abiosoft/colima/config"
+)
+
+func TestSSHConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config.SetProfile("buildbox")
+
+	tests := []struct {
+		address string
+		want    []string
+		wantErr bool
+	}{
+		{address: "user@buildbox", want: []string{"Host colima-buildbox", "  HostName buildbox", "  User user", "  Port 22"}},
+		{address: "ci@10.0.0.2:2222", want: []string{"Host colima-buildbox", "  HostName 10.0.0.2", "  User ci", "  Port 2222"}},
+		{address: "buildbox", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			file, err := SSHConfigFile(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SSHConfigFile(%s) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(string(b), "\n")
+			if got := lines[:len(tt.want)]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SSHConfigFile(%s) = %q, want %q", tt.address, got, tt.want)
+			}
+			if !strings.Contains(string(b), "  BatchMode yes\n") {
+				t.Errorf("SSHConfigFile(%s) is not non-interactive: %s", tt.address, b)
+			}
+			if want := `  ControlPath "` + filepath.Join(config.Dir(), "ssh.sock") + `"`; !strings.Contains(string(b), want) {
+				t.Errorf("SSHConfigFile(%s) has no '%s': %s", tt.address, want, b)
+			}
+		})
+	}
+}
+
+func Test_remoteVM_ssh(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config.SetProfile("buildbox")
+	sshConfig := filepath.Join(config.Dir(), "ssh_config")
+
+	tests := []struct {
+		flags []string
+		args  []string
+		want  []string
+	}{
+		{want: []string{"ssh", "-F", sshConfig, "colima-buildbox"}},
+		{flags: []string{"-t"}, args: []string{"docker", "ps"},
+			want: []string{"ssh", "-F", sshConfig, "-t", "colima-buildbox", "--", "'docker' 'ps'"}},
+		// passed as is, as with the VM
+		{args: []string{"sh", "-c", "echo $HOME; echo 'it''s'"},
+			want: []string{"ssh", "-F", sshConfig, "colima-buildbox", "--", `'sh' '-c' 'echo $HOME; echo '\''it'\'''\''s'\'''`}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			r := remoteVM{address: "user@buildbox"}
+			got, err := r.ssh(tt.flags, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ssh() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (remoteVM{address: "buildbox"}).ssh(nil, "true"); err == nil {
+		t.Error("ssh() with an invalid address error = nil, want error")
+	}
+}
+
+func Test_configFile(t *testing.T) {
+	config.SetProfile("buildbox")
+	if got, want := configFile(), "/etc/colima/colima-buildbox.json"; got != want {
+		t.Errorf("configFile() = %s, want %s", got, want)
+	}
+}
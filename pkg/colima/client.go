@@ -0,0 +1,170 @@
+// Package colima is a library to manage Colima profiles from Go programs.
+//
+// The operations of a Client are equivalent to the colima commands for the profile,
+// and are serialized with other colima processes operating on the profile.
+package colima
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/abiosoft/colima/app"
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Status is the status of a profile.
+type Status = app.StatusInfo
+
+var (
+	// ErrNotCreated is returned when the profile has not been created.
+	ErrNotCreated = app.ErrNotCreated
+	// ErrNotRunning is returned when the profile is not running.
+	ErrNotRunning = app.ErrNotRunning
+)
+
+// Profiles returns the names of the profiles with a saved config.
+func Profiles() ([]string, error) { return config.Profiles() }
+
+// SetExecutable sets the path to the colima executable run in the background for the profiles, e.g. the monitor
+// of the idle policy. By default, it is colima in PATH.
+func SetExecutable(path string) { app.Executable = path }
+
+// Client manages a profile.
+type Client struct {
+	profile string
+}
+
+// NewClient creates a client for the profile, "default" is the default profile.
+func NewClient(profile string) *Client {
+	return &Client{profile: config.ProfileFromName(profile).ShortName}
+}
+
+// Profile returns the name of the profile of the client.
+func (c *Client) Profile() string { return c.profile }
+
+// mu guards the current profile of the process, the operations depend on it.
+var mu sync.Mutex
+
+// do runs f with the profile of the client as the current profile.
+// If lock is true, the profile is locked against other colima processes.
+func (c *Client) do(lock bool, f func() error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	previous := config.Profile().ShortName
+	config.SetProfile(c.profile)
+	defer config.SetProfile(previous)
+	if err := config.EnsureDirs(); err != nil {
+		return err
+	}
+
+	if lock {
+		unlock, err := config.Lock(func() {
+			log.Println("waiting for another colima process operating on " + config.Profile().DisplayName)
+		})
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	return f()
+}
+
+// Start starts the profile with conf, like 'colima start'. If conf is empty, the saved config
+// is used, or the default config for a new profile. conf is saved if valid.
+func (c *Client) Start(conf Config) error {
+	return c.do(true, func() error {
+		current, err := config.LoadInto(DefaultConfig())
+		if err != nil {
+			return err
+		}
+		if conf.Empty() {
+			conf = current
+		}
+		if conf.Empty() {
+			conf = DefaultConfig()
+		}
+		// settings only effective on create are retained for an existing profile, as with 'colima start'
+		if !current.Empty() {
+			WarnCreateOnlyChanged(current, conf)
+			SetCreateOnly(&conf, current)
+		}
+
+		AllocatePorts(&conf, current)
+		if err := validationError(Validate(conf)); err != nil {
+			return err
+		}
+		if err := config.Save(conf); err != nil {
+			return fmt.Errorf("error saving config: %w", err)
+		}
+
+		colimaApp, err := app.New()
+		if err != nil {
+			return err
+		}
+		return colimaApp.Start(conf)
+	})
+}
+
+// Stop stops the profile, like 'colima stop'.
+func (c *Client) Stop(force bool) error {
+	return c.do(true, func() error {
+		colimaApp, err := app.New()
+		if err != nil {
+			return err
+		}
+		return colimaApp.Stop(force)
+	})
+}
+
+// Status returns the status of the profile, like 'colima status --json'.
+// ErrNotCreated and ErrNotRunning are not returned, they are reflected in the status.
+func (c *Client) Status() (status Status, err error) {
+	err = c.do(false, func() error {
+		colimaApp, err := app.New()
+		if err != nil {
+			return err
+		}
+		status, err = colimaApp.StatusInfo()
+		return err
+	})
+	return status, err
+}
+
+// Config returns the saved config of the profile, the default config if none is saved.
+func (c *Client) Config() (conf Config, err error) {
+	err = c.do(false, func() error {
+		conf, err = config.LoadInto(DefaultConfig())
+		if err == nil && conf.Empty() {
+			conf = DefaultConfig()
+		}
+		return err
+	})
+	return conf, err
+}
+
+// SetConfig validates and saves the config of the profile, effective on the next start.
+func (c *Client) SetConfig(conf Config) error {
+	return c.do(true, func() error {
+		if err := validationError(Validate(conf)); err != nil {
+			return err
+		}
+		return config.Save(conf)
+	})
+}
+
+// validationError combines the validation errors.
+func validationError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return cli.NewError(cli.ExitConfig, errors.New("invalid config: "+strings.Join(messages, "; ")), "")
+}
@@ -0,0 +1,332 @@
+package colima
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"sort"
+	"strings"
+
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	"github.com/abiosoft/colima/environment/container/docker"
+	"github.com/abiosoft/colima/environment/container/kubernetes"
+	"github.com/abiosoft/colima/util"
+	"github.com/abiosoft/colima/util/cloudinit"
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+)
+
+// envNamePattern matches the valid names of environment variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Config is the configuration of a profile.
+type Config = config.Config
+
+// Default values of the config.
+const (
+	DefaultCPU               = 2
+	DefaultMemory            = 2
+	DefaultDisk              = 60
+	DefaultKubernetesVersion = kubernetes.DefaultVersion
+)
+
+// DefaultArch returns the default architecture, the architecture of the host.
+func DefaultArch() string { return string(environment.Arch(goruntime.GOARCH).Value()) }
+
+// DefaultConfig returns the default config of new profiles.
+func DefaultConfig() Config {
+	var c Config
+	c.Runtime = docker.Name
+	c.VM.CPU = DefaultCPU
+	c.VM.Memory = DefaultMemory
+	c.VM.Disk = DefaultDisk
+	c.VM.Arch = DefaultArch()
+	c.Kubernetes.Version = DefaultKubernetesVersion
+	c.Kubernetes.MetricsServer = true
+	return c
+}
+
+// Validate validates the values of conf and returns all the problems found.
+func Validate(conf Config) (errs []error) {
+	runtimes := environment.ContainerRuntimes()
+	sort.Strings(runtimes)
+	if !contains(runtimes, conf.Runtime) {
+		errs = append(errs, fmt.Errorf("invalid runtime '%s', valid values are %s", conf.Runtime, strings.Join(runtimes, ", ")))
+	}
+	if environment.Arch(conf.VM.Arch).Value() == "default" {
+		errs = append(errs, fmt.Errorf("invalid arch '%s', valid values are aarch64, x86_64", conf.VM.Arch))
+	}
+
+	// host capacity
+	if conf.VM.CPU < 1 {
+		errs = append(errs, fmt.Errorf("invalid cpu '%d', must be at least 1", conf.VM.CPU))
+	} else if n := goruntime.NumCPU(); conf.VM.CPU > n {
+		errs = append(errs, fmt.Errorf("invalid cpu '%d', the host has %d CPUs", conf.VM.CPU, n))
+	}
+	if conf.VM.Memory < 1 {
+		errs = append(errs, fmt.Errorf("invalid memory '%d', must be at least 1", conf.VM.Memory))
+	} else if mem := util.HostMemory(); mem > 0 && int64(conf.VM.Memory)*units.GiB > mem {
+		errs = append(errs, fmt.Errorf("invalid memory '%d', the host has %s memory", conf.VM.Memory, units.BytesSize(float64(mem))))
+	}
+	if conf.VM.Disk < 1 {
+		errs = append(errs, fmt.Errorf("invalid disk '%d', must be at least 1", conf.VM.Disk))
+	}
+	if conf.VM.SSHPort < 0 || conf.VM.SSHPort > 65535 {
+		errs = append(errs, fmt.Errorf("invalid ssh_port '%d'", conf.VM.SSHPort))
+	}
+	if conf.VM.PortRange != "" {
+		if _, _, err := config.ParsePortRange(conf.VM.PortRange); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := conf.VM.AuthorizedKeys(); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.VM.ShutdownTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid shutdown_timeout '%d', cannot be negative", conf.VM.ShutdownTimeout))
+	}
+
+	if w := conf.WaitTimeout; w.Docker < 0 || w.Containerd < 0 || w.Kubernetes < 0 {
+		errs = append(errs, fmt.Errorf("invalid wait_timeout, cannot be negative"))
+	}
+
+	if conf.Idle.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid idle timeout '%d', cannot be negative", conf.Idle.Timeout))
+	}
+	if a := conf.Idle.Action; a != "" && a != config.IdleActionStop && a != config.IdleActionPause {
+		errs = append(errs, fmt.Errorf("invalid idle action '%s', valid values are stop, pause", a))
+	}
+
+	if c := conf.Channel; c != "" && c != config.ChannelStable && c != config.ChannelEdge {
+		errs = append(errs, fmt.Errorf("invalid channel '%s', valid values are stable, edge", c))
+	}
+
+	if conf.LazyStart && conf.Runtime != docker.Name {
+		errs = append(errs, fmt.Errorf("lazy_start is only supported for the %s runtime", docker.Name))
+	}
+
+	if (conf.RemoteInstall || conf.RemoteManage) && conf.Remote == "" {
+		errs = append(errs, fmt.Errorf("remote_install and remote_manage require remote"))
+	}
+	if conf.Remote != "" && !conf.RemoteManage && len(conf.Health.Repairs) > 0 {
+		errs = append(errs, fmt.Errorf("health repairs on a remote host require remote_manage"))
+	}
+	if conf.Remote != "" {
+		if _, err := config.ParseRemote(conf.Remote); err != nil {
+			errs = append(errs, err)
+		}
+		if conf.Runtime != docker.Name {
+			errs = append(errs, fmt.Errorf("remote is only supported for the %s runtime", docker.Name))
+		}
+		if conf.Kubernetes.Enabled {
+			errs = append(errs, fmt.Errorf("remote is not supported with kubernetes"))
+		}
+		// the socket of the remote host is forwarded by the monitor, not proxied
+		if conf.Idle.Enabled() || conf.LazyStart {
+			errs = append(errs, fmt.Errorf("remote is not supported with the idle timeout and lazy_start"))
+		}
+	}
+
+	if t := conf.DockerTransport; t != "" && t != config.DockerTransportUnix && t != config.DockerTransportSSH {
+		errs = append(errs, fmt.Errorf("invalid docker transport '%s', valid values are unix, ssh", t))
+	}
+	if conf.DockerTransport == config.DockerTransportSSH {
+		if conf.Runtime != docker.Name {
+			errs = append(errs, fmt.Errorf("docker_transport is only supported for the %s runtime", docker.Name))
+		}
+		// the connections over ssh bypass the socket proxied by the monitor
+		if conf.Idle.Enabled() || conf.LazyStart {
+			errs = append(errs, fmt.Errorf("docker_transport ssh is not supported with the idle timeout and lazy_start"))
+		}
+	}
+
+	if conf.Health.Interval < 0 {
+		errs = append(errs, fmt.Errorf("invalid health interval '%d', cannot be negative", conf.Health.Interval))
+	}
+	for _, r := range conf.Health.Repairs {
+		if r != config.HealthRepairRuntime && r != config.HealthRepairDisk {
+			errs = append(errs, fmt.Errorf("invalid health repair '%s', valid values are runtime, disk", r))
+		}
+	}
+
+	if a := conf.VM.CPUAffinity; a != "" {
+		if goruntime.GOOS == "darwin" {
+			if a != config.CPUAffinityEfficiency {
+				errs = append(errs, fmt.Errorf("invalid cpu affinity '%s', only '%s' is supported on macOS", a, config.CPUAffinityEfficiency))
+			}
+		} else if _, err := config.ParseCPUList(a); err != nil {
+			errs = append(errs, fmt.Errorf("invalid cpu affinity: %w", err))
+		}
+	}
+	if t := conf.VM.TCG.Thread; t != "" && t != config.TCGThreadSingle && t != config.TCGThreadMulti {
+		errs = append(errs, fmt.Errorf("invalid tcg thread '%s', valid values are single, multi", t))
+	}
+	if conf.VM.TCG.TBSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid tcg tb_size '%d', cannot be negative", conf.VM.TCG.TBSize))
+	}
+	if !conf.VM.TCG.Empty() && !environment.Arch(conf.VM.Arch).Emulated() {
+		log.Warnln("vm.tcg is ignored, the VM is not emulated")
+	}
+
+	if conf.VM.QEMUBinary != "" && !filepath.IsAbs(conf.VM.QEMUBinary) {
+		errs = append(errs, fmt.Errorf("invalid qemu_binary '%s', must be an absolute path", conf.VM.QEMUBinary))
+	}
+
+	if conf.VM.Cgroup != "" && conf.VM.Cgroup != config.CgroupV1 && conf.VM.Cgroup != config.CgroupV2 {
+		errs = append(errs, fmt.Errorf("invalid cgroup version '%s', valid values are v1, v2", conf.VM.Cgroup))
+	}
+	if conf.VM.Timezone != "" {
+		if !util.ValidTimezone(conf.VM.Timezone) {
+			errs = append(errs, fmt.Errorf("invalid timezone '%s'", conf.VM.Timezone))
+		}
+	}
+	if conf.VM.CloudInit != "" {
+		if _, err := cloudinit.Parse(conf.VM.CloudInit); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, p := range conf.Provision {
+		if p.Mode != "" && p.Mode != config.ProvisionModeSystem && p.Mode != config.ProvisionModeUser {
+			errs = append(errs, fmt.Errorf("invalid provision mode '%s', valid values are system, user", p.Mode))
+		}
+		if strings.TrimSpace(p.Script) == "" {
+			errs = append(errs, fmt.Errorf("invalid provision script %d, cannot be empty", i+1))
+		}
+	}
+
+	for _, p := range conf.Packages {
+		if p == "" || strings.HasPrefix(p, "-") || strings.ContainsAny(p, " \t") {
+			errs = append(errs, fmt.Errorf("invalid package '%s'", p))
+		}
+	}
+
+	for name, env := range map[string]map[string]string{"docker_env": conf.DockerEnv, "containerd_env": conf.ContainerdEnv} {
+		for k := range env {
+			if !envNamePattern.MatchString(k) {
+				errs = append(errs, fmt.Errorf("invalid %s variable '%s'", name, k))
+			}
+		}
+	}
+
+	for i, s := range conf.ContainerdConfig {
+		if strings.TrimSpace(s) == "" {
+			errs = append(errs, fmt.Errorf("invalid containerd_config snippet %d, cannot be empty", i+1))
+		}
+	}
+
+	for name, value := range conf.DockerUlimits {
+		if _, err := config.ParseUlimit(name, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if conf.DockerShmSize != "" {
+		if _, err := units.RAMInBytes(conf.DockerShmSize); err != nil {
+			errs = append(errs, fmt.Errorf("invalid docker shm size '%s'", conf.DockerShmSize))
+		}
+	}
+
+	for _, s := range conf.NTP.Servers {
+		if s == "" || strings.HasPrefix(s, "-") || strings.ContainsAny(s, " \t\n\r'\"") {
+			errs = append(errs, fmt.Errorf("invalid ntp server '%s'", s))
+		}
+	}
+	if conf.NTP.Disabled && len(conf.NTP.Servers) > 0 {
+		errs = append(errs, fmt.Errorf("ntp servers cannot be set with ntp disabled"))
+	}
+
+	for _, t := range conf.Tunnels {
+		if _, err := config.ParseTunnel(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if f := conf.LogFormat; f != "" && f != "text" && f != "json" {
+		errs = append(errs, fmt.Errorf("invalid log_format '%s', valid values are text, json", f))
+	}
+
+	// kubernetes
+	if conf.Kubernetes.Port < 0 || conf.Kubernetes.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid kubernetes port '%d'", conf.Kubernetes.Port))
+	}
+	if join := conf.Kubernetes.Join; join != "" {
+		if goruntime.GOOS != "darwin" {
+			errs = append(errs, fmt.Errorf("kubernetes join requires VM networking, only available on macOS"))
+		}
+		if config.ProfileFromName(join).ID == config.Profile().ID {
+			errs = append(errs, fmt.Errorf("kubernetes join cannot be the current profile"))
+		} else if c, err := config.LoadProfile(join); err != nil || c.Empty() {
+			errs = append(errs, fmt.Errorf("kubernetes join profile '%s' does not exist", join))
+		} else if !c.Kubernetes.Enabled {
+			errs = append(errs, fmt.Errorf("kubernetes join profile '%s' does not have kubernetes enabled", join))
+		}
+	}
+	for i, chart := range conf.Kubernetes.HelmCharts {
+		if chart.Name == "" || chart.Chart == "" {
+			errs = append(errs, fmt.Errorf("helm chart %d requires name and chart", i+1))
+		}
+	}
+
+	return errs
+}
+
+// SetCreateOnly sets the settings of c that are only effective on create of the VM or the profile to those of from.
+// runtime, disk size, kubernetes version, arch and remote host.
+func SetCreateOnly(c *Config, from Config) {
+	c.Runtime = from.Runtime
+	c.VM.Disk = from.VM.Disk
+	c.VM.Arch = from.VM.Arch
+	c.Kubernetes.Version = from.Kubernetes.Version
+	c.Remote = from.Remote
+}
+
+// WarnCreateOnlyChanged warns if the settings of conf only effective on create differ from those of current.
+func WarnCreateOnlyChanged(current, conf Config) {
+	if conf.Runtime != current.Runtime || conf.VM.Disk != current.VM.Disk || conf.VM.Arch != current.VM.Arch {
+		log.Warnln("runtime, disk and arch cannot be changed after the VM is created, changes are ignored")
+	}
+	if conf.Remote != current.Remote {
+		log.Warnln("the remote host cannot be changed after the profile is created, changes are ignored")
+	}
+}
+
+// AllocatePorts allocates the unset ports of conf from the port range of the current profile.
+// current is the saved config of the profile. The ports are persisted in the config to remain
+// stable across restarts.
+func AllocatePorts(conf *Config, current Config) {
+	used := config.UsedPorts()
+	portRange := conf.VM.PortRangeOrDefault()
+
+	if conf.VM.SSHPort == 0 {
+		port, err := config.AllocatePort(config.Profile().ID+"/ssh", portRange, used)
+		if err != nil {
+			// not fatal, a random port is used
+			log.Warnln(fmt.Errorf("error allocating SSH port: %w", err))
+		}
+		conf.VM.SSHPort = port
+		used[port] = true
+	}
+
+	// the default profile retains the default Kubernetes port.
+	// the port of an existing cluster cannot be changed.
+	// agents do not serve the API.
+	if conf.Kubernetes.Enabled && conf.Kubernetes.Port == 0 && conf.Kubernetes.Join == "" &&
+		!current.Kubernetes.Enabled && config.Profile().ShortName != config.AppName {
+		port, err := config.AllocatePort(config.Profile().ID+"/kubernetes", portRange, used)
+		if err != nil {
+			// not fatal, the default port is used
+			log.Warnln(fmt.Errorf("error allocating Kubernetes port: %w", err))
+		}
+		conf.Kubernetes.Port = port
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
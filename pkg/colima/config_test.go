@@ -0,0 +1,60 @@
+package colima
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/abiosoft/colima/config"
+)
+
+func TestSetCreateOnly(t *testing.T) {
+	from := Config{
+		Runtime:    "containerd",
+		Remote:     "user@host",
+		VM:         config.VM{Disk: 100, Arch: "x86_64", CPU: 4},
+		Kubernetes: config.Kubernetes{Version: "v1.22.4+k3s1"},
+	}
+	c := Config{
+		Runtime: "docker",
+		VM:      config.VM{Disk: 60, Arch: "aarch64", CPU: 2},
+	}
+
+	SetCreateOnly(&c, from)
+	want := from
+	want.VM.CPU = 2
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("SetCreateOnly() = %+v, want %+v", c, want)
+	}
+}
+
+func TestValidate_ntpServers(t *testing.T) {
+	tests := []struct {
+		server string
+		valid  bool
+	}{
+		{server: "pool.ntp.org", valid: true},
+		{server: "10.0.0.1", valid: true},
+		{server: ""},
+		{server: "-x"},
+		{server: "pool.ntp.org iburst"},
+		{server: "pool.ntp.org\nallow all"},
+		{server: "pool.ntp.org\rallow all"},
+		{server: "'pool.ntp.org'"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			conf := Config{NTP: config.NTP{Servers: []string{tt.server}}}
+			var invalid bool
+			for _, err := range Validate(conf) {
+				if strings.HasPrefix(err.Error(), "invalid ntp server") {
+					invalid = true
+				}
+			}
+			if invalid == tt.valid {
+				t.Errorf("Validate() ntp server %q valid = %v, want %v", tt.server, !invalid, tt.valid)
+			}
+		})
+	}
+}
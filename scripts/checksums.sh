@@ -0,0 +1,44 @@
+#!/usr/bin/env sh
+
+# prints the pinned checksums to be updated with the versions, of the k3s releases for releaseChecksums in
+# environment/container/kubernetes/k3s.go and of the edge components for componentChecksums in
+# environment/container/containerd/components.go.
+#
+# usage: sh scripts/checksums.sh k3s v1.22.4+k3s1 v1.23.4+k3s1
+#        sh scripts/checksums.sh containerd <nerdctl version> <buildkit version>
+
+set -e
+
+# sha256sum is not on macOS by default, use shasum
+SHA256SUM=sha256sum
+if ! command -v sha256sum >/dev/null; then
+    SHA256SUM="shasum -a 256"
+fi
+
+sum() {
+    curl -fsSL "$1" | ${SHA256SUM} | cut -d' ' -f1
+}
+
+case "$1" in
+k3s)
+    shift
+    for version in "$@"; do
+        echo "\"${version}\": {"
+        for arch in amd64 arm64; do
+            echo "    \"sha256sum-${arch}.txt\": \"$(sum "https://github.com/k3s-io/k3s/releases/download/${version}/sha256sum-${arch}.txt")\","
+        done
+        echo "    \"install.sh\": \"$(sum "https://raw.githubusercontent.com/k3s-io/k3s/${version}/install.sh")\","
+        echo "},"
+    done
+    ;;
+containerd)
+    for arch in amd64 arm64; do
+        echo "\"nerdctl-$2-linux-${arch}.tar.gz\": \"$(sum "https://github.com/containerd/nerdctl/releases/download/v$2/nerdctl-$2-linux-${arch}.tar.gz")\","
+        echo "\"buildkit-v$3.linux-${arch}.tar.gz\": \"$(sum "https://github.com/moby/buildkit/releases/download/v$3/buildkit-v$3.linux-${arch}.tar.gz")\","
+    done
+    ;;
+*)
+    echo "usage: $0 k3s <version>... | containerd <nerdctl version> <buildkit version>" >&2
+    exit 1
+    ;;
+esac
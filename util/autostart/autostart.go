@@ -0,0 +1,158 @@
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/embedded"
+	"github.com/abiosoft/colima/util"
+)
+
+// labelPrefix is the prefix of the launchd labels and systemd unit names.
+// It differs from the networking launchd files to not be mistaken for them.
+const labelPrefix = "com.abiosoft.colima-autostart"
+
+// Supported returns an error if autostart is not supported on the host.
+func Supported() error {
+	switch runtime.GOOS {
+	case "darwin", "linux":
+		return nil
+	}
+	return fmt.Errorf("autostart is not supported on %s", runtime.GOOS)
+}
+
+func dir() string {
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(util.HomeDir(), "Library", "LaunchAgents")
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(util.HomeDir(), ".config")
+	}
+	return filepath.Join(configDir, "systemd", "user")
+}
+
+func label() string { return labelPrefix + "." + config.Profile().ID }
+
+func fileName(label string) string {
+	if runtime.GOOS == "darwin" {
+		return label + ".plist"
+	}
+	return label + ".service"
+}
+
+// File returns the launchd agent (macOS) or systemd user unit (Linux) file of the current profile.
+func File() string {
+	return filepath.Join(dir(), fileName(label()))
+}
+
+// Files returns the autostart files of all profiles.
+func Files() ([]string, error) {
+	return filepath.Glob(filepath.Join(dir(), fileName(labelPrefix+".*")))
+}
+
+// Enabled returns if the current profile starts at login.
+func Enabled() bool {
+	_, err := os.Stat(File())
+	return err == nil
+}
+
+// Enable installs the launchd agent (macOS) or systemd user unit (Linux) that starts the current profile
+// with the colima binary at login and stops it at logout. If lazy, only the Docker socket is listened on
+// at login and the profile is started on the first connection.
+// It takes effect at the next login.
+func Enable(binary string, lazy bool) error {
+	if err := Supported(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir(), 0755); err != nil {
+		return fmt.Errorf("error creating autostart directory: %w", err)
+	}
+
+	command := "start"
+	if lazy {
+		command = "monitor --background"
+	}
+
+	values := struct {
+		Label   string
+		Profile string
+		Binary  string
+		Command string
+		Path    string
+		Log     string
+	}{
+		Label:   label(),
+		Profile: config.Profile().ShortName,
+		Binary:  binary,
+		Command: command,
+		// limactl and the runtime clients are looked up in the PATH
+		Path: os.Getenv("PATH"),
+		Log:  filepath.Join(config.Dir(), "autostart.log"),
+	}
+
+	template := "autostart/launchd.plist"
+	if runtime.GOOS == "linux" {
+		template = "autostart/systemd.service"
+	}
+	body, err := embedded.ReadString(template)
+	if err != nil {
+		return fmt.Errorf("error preparing autostart file: %w", err)
+	}
+	if err := util.WriteTemplate(body, File(), values); err != nil {
+		return fmt.Errorf("error writing autostart file: %w", err)
+	}
+
+	if runtime.GOOS == "linux" {
+		for _, args := range [][]string{{"daemon-reload"}, {"enable", fileName(label())}} {
+			if err := systemctl(args...); err != nil {
+				_ = os.Remove(File())
+				return fmt.Errorf("error enabling systemd unit: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Disable removes the autostart file of the current profile.
+// A running profile is not stopped, it is stopped at logout for the last time.
+func Disable() error {
+	if !Enabled() {
+		return nil
+	}
+	if runtime.GOOS == "linux" {
+		if err := systemctl("disable", fileName(label())); err != nil {
+			return fmt.Errorf("error disabling systemd unit: %w", err)
+		}
+	}
+	if err := os.Remove(File()); err != nil {
+		return fmt.Errorf("error removing autostart file: %w", err)
+	}
+	return nil
+}
+
+// Unload removes the autostart file after stopping the job, stopping the profile if started by it.
+func Unload(file string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		_ = quiet("launchctl", "unload", file)
+	case "linux":
+		_ = systemctl("disable", "--now", filepath.Base(file))
+	}
+	return os.Remove(file)
+}
+
+func systemctl(args ...string) error {
+	return quiet("systemctl", append([]string{"--user"}, args...)...)
+}
+
+func quiet(command string, args ...string) error {
+	cmd := cli.Command(command, args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	return cmd.Run()
+}
@@ -0,0 +1,147 @@
+// Package cloudinit translates cloud-init user-data into provision scripts for the VM.
+//
+// The VM does not run cloud-init, the modules commonly used for dev boxes are supported:
+// bootcmd, write_files, packages and runcmd.
+package cloudinit
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/abiosoft/colima/util"
+	"gopkg.in/yaml.v3"
+)
+
+// UserData is the supported subset of cloud-init user-data.
+type UserData struct {
+	BootCmd    []Command   `yaml:"bootcmd"`
+	WriteFiles []WriteFile `yaml:"write_files"`
+	Packages   []string    `yaml:"packages"`
+	RunCmd     []Command   `yaml:"runcmd"`
+
+	// Unsupported are the modules in the user-data that are not supported.
+	Unsupported []string `yaml:"-"`
+}
+
+// WriteFile is an entry of the write_files module.
+type WriteFile struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content"`
+	Encoding    string `yaml:"encoding"`
+	Permissions string `yaml:"permissions"`
+	Owner       string `yaml:"owner"`
+	Append      bool   `yaml:"append"`
+}
+
+// Command is a command of the runcmd and bootcmd modules, a shell command or a list of arguments.
+type Command string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *Command) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = Command(value.Value)
+		return nil
+	}
+	var args []string
+	if err := value.Decode(&args); err != nil {
+		return err
+	}
+	for i, a := range args {
+		args[i] = util.ShellQuote(a)
+	}
+	*c = Command(strings.Join(args, " "))
+	return nil
+}
+
+var supported = map[string]bool{"bootcmd": true, "write_files": true, "packages": true, "runcmd": true}
+
+// Parse parses the user-data file.
+func Parse(file string) (UserData, error) {
+	var u UserData
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return u, fmt.Errorf("error reading cloud-init user-data: %w", err)
+	}
+	if !strings.HasPrefix(string(b), "#cloud-config") {
+		return u, fmt.Errorf("invalid cloud-init user-data '%s', only #cloud-config is supported", file)
+	}
+	if err := yaml.Unmarshal(b, &u); err != nil {
+		return u, fmt.Errorf("invalid cloud-init user-data '%s': %w", file, err)
+	}
+
+	var modules map[string]interface{}
+	_ = yaml.Unmarshal(b, &modules)
+	for name := range modules {
+		if !supported[name] {
+			u.Unsupported = append(u.Unsupported, name)
+		}
+	}
+	sort.Strings(u.Unsupported)
+
+	for _, f := range u.WriteFiles {
+		if f.Path == "" {
+			return u, fmt.Errorf("invalid cloud-init user-data '%s', write_files entry without path", file)
+		}
+		switch f.Encoding {
+		case "", "text/plain", "b64", "base64":
+		default:
+			return u, fmt.Errorf("invalid cloud-init user-data '%s', unsupported encoding '%s'", file, f.Encoding)
+		}
+	}
+	return u, nil
+}
+
+// BootScript returns the script for the modules run on every boot, empty if there are none.
+func (u UserData) BootScript() string {
+	if len(u.BootCmd) == 0 {
+		return ""
+	}
+	lines := []string{"#!/bin/sh", "set -e"}
+	for _, c := range u.BootCmd {
+		lines = append(lines, string(c))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// CreateScript returns the script for the modules run once when the VM is created, empty if there are none.
+func (u UserData) CreateScript() string {
+	if len(u.WriteFiles) == 0 && len(u.Packages) == 0 && len(u.RunCmd) == 0 {
+		return ""
+	}
+	lines := []string{"#!/bin/sh", "set -e"}
+	for _, f := range u.WriteFiles {
+		content := f.Content
+		if f.Encoding == "" || f.Encoding == "text/plain" {
+			content = base64.StdEncoding.EncodeToString([]byte(f.Content))
+		}
+		redirect := ">"
+		if f.Append {
+			redirect = ">>"
+		}
+		path := util.ShellQuote(f.Path)
+		lines = append(lines,
+			"mkdir -p $(dirname "+path+")",
+			"echo "+util.ShellQuote(content)+" | base64 -d "+redirect+" "+path,
+		)
+		if f.Permissions != "" {
+			lines = append(lines, "chmod "+util.ShellQuote(f.Permissions)+" "+path)
+		}
+		if f.Owner != "" {
+			lines = append(lines, "chown "+util.ShellQuote(f.Owner)+" "+path)
+		}
+	}
+	if len(u.Packages) > 0 {
+		var packages []string
+		for _, p := range u.Packages {
+			packages = append(packages, util.ShellQuote(p))
+		}
+		lines = append(lines, "apk add --no-cache "+strings.Join(packages, " "))
+	}
+	for _, c := range u.RunCmd {
+		lines = append(lines, string(c))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
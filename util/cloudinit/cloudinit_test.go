@@ -0,0 +1,75 @@
+package cloudinit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		data        string
+		wantErr     bool
+		runCmd      []Command
+		unsupported []string
+	}{
+		{data: "#cloud-config\nruncmd:\n  - echo hello\n  - [ls, -l, \"it's\"]\n",
+			runCmd: []Command{"echo hello", `'ls' '-l' 'it'\''s'`}},
+		{data: "#cloud-config\nusers: []\nruncmd: []\nssh_pwauth: true\n", runCmd: []Command{},
+			unsupported: []string{"ssh_pwauth", "users"}},
+		{data: "#!/bin/sh\necho hello\n", wantErr: true},
+		{data: "#cloud-config\nwrite_files:\n  - content: hello\n", wantErr: true},
+		{data: "#cloud-config\nwrite_files:\n  - path: /etc/motd\n    encoding: gzip\n", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "user-data")
+			if err := os.WriteFile(file, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.RunCmd, tt.runCmd) {
+				t.Errorf("Parse() runcmd = %q, want %q", got.RunCmd, tt.runCmd)
+			}
+			if !reflect.DeepEqual(got.Unsupported, tt.unsupported) {
+				t.Errorf("Parse() unsupported = %v, want %v", got.Unsupported, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestUserData_Scripts(t *testing.T) {
+	tests := []struct {
+		userData UserData
+		boot     string
+		create   string
+	}{
+		{userData: UserData{}},
+		{userData: UserData{BootCmd: []Command{"echo boot"}},
+			boot: "#!/bin/sh\nset -e\necho boot\n"},
+		{userData: UserData{Packages: []string{"htop", "git"}, RunCmd: []Command{"echo run"}},
+			create: "#!/bin/sh\nset -e\napk add --no-cache 'htop' 'git'\necho run\n"},
+		{userData: UserData{WriteFiles: []WriteFile{{Path: "/etc/motd", Content: "hi", Permissions: "0644", Append: true}}},
+			create: "#!/bin/sh\nset -e\nmkdir -p $(dirname '/etc/motd')\necho 'aGk=' | base64 -d >> '/etc/motd'\nchmod '0644' '/etc/motd'\n"},
+		{userData: UserData{WriteFiles: []WriteFile{{Path: "/root/key", Content: "aGk=", Encoding: "b64", Owner: "root:root"}}},
+			create: "#!/bin/sh\nset -e\nmkdir -p $(dirname '/root/key')\necho 'aGk=' | base64 -d > '/root/key'\nchown 'root:root' '/root/key'\n"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := tt.userData.BootScript(); got != tt.boot {
+				t.Errorf("BootScript() = %q, want %q", got, tt.boot)
+			}
+			if got := tt.userData.CreateScript(); got != tt.create {
+				t.Errorf("CreateScript() = %q, want %q", got, tt.create)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/abiosoft/colima/cli"
+	"github.com/abiosoft/colima/config"
+	"github.com/abiosoft/colima/environment"
+	log "github.com/sirupsen/logrus"
+)
+
+// artifactCache is the content-addressed cache of release artifacts shared by all profiles, e.g. k3s and the VM image.
+// The artifacts are stored once by checksum in blobs/<algorithm>, the checksum of an artifact is pinned
+// by the caller. An artifact with a known checksum is not downloaded again, from any url.
+// An artifact without a pinned checksum is not verified, its checksum is recorded by url in unpinned
+// at the first download for it to be used offline afterwards.
+type artifactCache struct {
+	host environment.HostActions
+	dir  string
+}
+
+func newArtifactCache(host environment.HostActions) artifactCache {
+	return artifactCache{host: host, dir: filepath.Join(config.SharedCacheDir(), "artifacts")}
+}
+
+func (c artifactCache) blobFile(checksum string) string {
+	algorithm, sum := digest(checksum)
+	return filepath.Join(c.dir, "blobs", algorithm, sum)
+}
+
+// digest returns the algorithm and the value of checksum, e.g. sha512:<sum>. The default algorithm is sha256.
+func digest(checksum string) (algorithm, sum string) {
+	if i := strings.Index(checksum, ":"); i > 0 {
+		return checksum[:i], checksum[i+1:]
+	}
+	return "sha256", checksum
+}
+
+// lockFile acquires the exclusive lock of file, waiting for other processes holding it.
+func lockFile(file string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("error preparing cache dir: %w", err)
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("error acquiring lock: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
+
+func (c artifactCache) unpinnedFile(url string) string {
+	return filepath.Join(c.dir, "unpinned", sha256Hash(url))
+}
+
+// unpinned returns the checksum recorded at the first download of the artifact at url without a pinned checksum.
+func (c artifactCache) unpinned(url string) string {
+	b, err := os.ReadFile(c.unpinnedFile(url))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// lookup returns the cached file of the artifact with the checksum.
+func (c artifactCache) lookup(checksum string) (string, bool) {
+	if checksum == "" {
+		return "", false
+	}
+	file := c.blobFile(checksum)
+	if _, err := os.Stat(file); err != nil {
+		return "", false
+	}
+	return file, true
+}
+
+// fetch returns the cached file of the artifact at url, downloaded if not cached.
+// The checksum of the download is verified against checksum, it is not verified if checksum is empty.
+func (c artifactCache) fetch(url, checksum string, progress bool) (string, error) {
+	pinned := checksum != ""
+	if !pinned {
+		checksum = c.unpinned(url)
+	}
+	if file, ok := c.lookup(checksum); ok {
+		return file, nil
+	}
+
+	// a single download of the url at a time, e.g. for profiles started concurrently
+	d := downloader{host: c.host, dir: filepath.Join(c.dir, "downloads")}
+	unlock, err := lockFile(d.cacheFileName(url) + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	// downloaded by another process in the meantime
+	if !pinned {
+		checksum = c.unpinned(url)
+	}
+	if file, ok := c.lookup(checksum); ok {
+		return file, nil
+	}
+
+	// partial downloads are resumed by the retries
+	err = cli.DefaultBackoff.Do(log.WithField("context", "download"), "download of '"+url+"'", func() error {
+		return d.downloadFile(url, progress)
+	})
+	if err != nil {
+		return "", cli.NewError(cli.ExitNetwork, fmt.Errorf("error downloading '%s': %w", url, err),
+			"check the network connection and proxy settings of the host")
+	}
+	downloaded := d.cacheFileName(url)
+	defer func() { _ = os.Remove(downloaded) }()
+
+	algorithm, expected := digest(checksum)
+	sum, err := checksumFile(downloaded, algorithm)
+	if err != nil {
+		return "", fmt.Errorf("error computing checksum for '%s': %w", url, err)
+	}
+	if pinned && sum != expected {
+		return "", integrityError(url, checksum, sum)
+	}
+	if !pinned {
+		log.Warnf("'%s' is not verified, there is no pinned checksum", url)
+		checksum = sum
+	}
+
+	file := c.blobFile(checksum)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", fmt.Errorf("error preparing cache dir: %w", err)
+	}
+	if err := os.Rename(downloaded, file); err != nil {
+		return "", fmt.Errorf("error caching '%s': %w", url, err)
+	}
+	if !pinned {
+		if err := os.MkdirAll(filepath.Dir(c.unpinnedFile(url)), 0755); err != nil {
+			return "", fmt.Errorf("error preparing cache dir: %w", err)
+		}
+		if err := os.WriteFile(c.unpinnedFile(url), []byte(checksum+"\n"), 0644); err != nil {
+			return "", fmt.Errorf("error caching '%s': %w", url, err)
+		}
+	}
+	return file, nil
+}
+
+// integrityError is the error of an artifact with an unexpected checksum, it is not installed.
+func integrityError(url, expected, actual string) error {
+	return cli.NewError(cli.ExitNetwork, fmt.Errorf("integrity check failed for '%s': expected %s, got %s", url, expected, actual),
+		"the download was altered, e.g. by a proxy, and was not installed; check the proxy settings of the host")
+}
+
+// DownloadArtifact is like Download for release artifacts, that do not change for a url.
+// The artifacts are cached once for all profiles and verified by the pinned checksum, sha256 by default, if set.
+func DownloadArtifact(host environment.HostActions, guest environment.GuestActions, url, checksum, fileName string) error {
+	cached, err := newArtifactCache(host).fetch(url, checksum, true)
+	if err != nil {
+		return err
+	}
+
+	// the shared cache is not mounted in the VM, it is linked into the cache of the profile that is.
+	file := filepath.Join(config.CacheDir(), "caches", filepath.Base(cached))
+	if err := host.RunQuiet("mkdir", "-p", filepath.Dir(file)); err != nil {
+		return fmt.Errorf("error preparing cache dir: %w", err)
+	}
+	_ = os.Remove(file)
+	if err := os.Link(cached, file); err != nil {
+		if err := host.RunQuiet("cp", cached, file); err != nil {
+			return fmt.Errorf("error copying '%s': %w", url, err)
+		}
+	}
+	defer func() { _ = os.Remove(file) }()
+
+	if err := guest.RunQuiet("cp", file, fileName); err != nil {
+		return err
+	}
+	// verified again in the VM, the blob is named by its checksum
+	algorithm, _ := digest(checksum)
+	out, err := guest.RunOutput(algorithm+"sum", fileName)
+	if err != nil {
+		return fmt.Errorf("error computing checksum for '%s': %w", fileName, err)
+	}
+	if fields := strings.Fields(out); len(fields) == 0 || fields[0] != filepath.Base(cached) {
+		return integrityError(url, filepath.Base(cached), out)
+	}
+	return nil
+}
+
+// CacheArtifact returns the file on the host of the release artifact at url, downloaded with progress output
+// if not cached, e.g. for the VM image.
+func CacheArtifact(host environment.HostActions, url, checksum string) (string, error) {
+	return newArtifactCache(host).fetch(url, checksum, true)
+}
+
+// FetchArtifact returns the file on the host of the release artifact at url, downloaded without progress output
+// if not cached. It is safe to fetch artifacts concurrently, e.g. to prefetch artifacts for DownloadArtifact.
+func FetchArtifact(host environment.HostActions, url, checksum string) (string, error) {
+	return newArtifactCache(host).fetch(url, checksum, false)
+}
@@ -0,0 +1,54 @@
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_digest(t *testing.T) {
+	tests := []struct {
+		checksum  string
+		algorithm string
+		sum       string
+	}{
+		{checksum: "abc", algorithm: "sha256", sum: "abc"},
+		{checksum: "sha512:abc", algorithm: "sha512", sum: "abc"},
+		{checksum: "", algorithm: "sha256", sum: ""},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			algorithm, sum := digest(tt.checksum)
+			if algorithm != tt.algorithm || sum != tt.sum {
+				t.Errorf("digest(%s) = %s, %s, want %s, %s", tt.checksum, algorithm, sum, tt.algorithm, tt.sum)
+			}
+		})
+	}
+}
+
+func Test_artifactCache_fetch(t *testing.T) {
+	c := artifactCache{dir: t.TempDir()}
+	url := "https://example.com/artifact"
+	sum := strings.Repeat("a", 64)
+	for file, body := range map[string]string{c.blobFile(sum): "artifact", c.unpinnedFile(url): sum + "\n"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// cached without downloads, by the pinned checksum or the checksum of the first download
+	for _, checksum := range []string{sum, ""} {
+		file, err := c.fetch(url, checksum, false)
+		if err != nil {
+			t.Fatalf("fetch() with checksum '%s' error = %v", checksum, err)
+		}
+		if file != c.blobFile(sum) {
+			t.Errorf("fetch() with checksum '%s' = %s, want %s", checksum, file, c.blobFile(sum))
+		}
+	}
+}
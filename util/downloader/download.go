@@ -2,12 +2,18 @@ package downloader
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/abiosoft/colima/cli"
 	"github.com/abiosoft/colima/config"
 	"github.com/abiosoft/colima/environment"
 	"github.com/abiosoft/colima/util/terminal"
-	"os"
-	"path/filepath"
+	log "github.com/sirupsen/logrus"
 )
 
 // Download downloads file at url and saves it in the destination.
@@ -19,31 +25,88 @@ func Download(host environment.HostActions, guest environment.GuestActions, url,
 	d := downloader{
 		host:  host,
 		guest: guest,
+		dir:   filepath.Join(config.CacheDir(), "caches"),
+	}
+	if err := d.download(url); err != nil {
+		return err
 	}
+	return guest.RunQuiet("cp", d.cacheFileName(url), fileName)
+}
 
+// download downloads the file at url into the cache, if not cached.
+func (d downloader) download(url string) error {
 	if !d.hasCache(url) {
-		if err := d.downloadFile(url); err != nil {
-			return fmt.Errorf("error downloading '%s': %w", url, err)
+		// partial downloads are resumed by the retries
+		err := cli.DefaultBackoff.Do(log.WithField("context", "download"), "download of '"+url+"'", func() error {
+			return d.downloadFile(url, true)
+		})
+		if err != nil {
+			return cli.NewError(cli.ExitNetwork, fmt.Errorf("error downloading '%s': %w", url, err),
+				"check the network connection and proxy settings of the host")
 		}
 	}
+	return nil
+}
 
-	return guest.RunQuiet("cp", d.cacheFileName(url), fileName)
+// CopyFile copies the file on the host to the destination on the guest.
+// fileName must be a directory on the guest that does not require root access.
+func CopyFile(host environment.HostActions, guest environment.GuestActions, file, fileName string) error {
+	// the cache directory of the profile is mounted in the VM, large files are not piped over ssh.
+	cacheFile := filepath.Join(config.CacheDir(), "caches", sha256Hash(file))
+	if err := host.RunQuiet("mkdir", "-p", filepath.Dir(cacheFile)); err != nil {
+		return fmt.Errorf("error preparing cache dir: %w", err)
+	}
+	if err := host.RunQuiet("cp", file, cacheFile); err != nil {
+		return fmt.Errorf("error copying '%s': %w", file, err)
+	}
+
+	return guest.RunQuiet("cp", cacheFile, fileName)
+}
+
+// SHA256File returns the sha256 checksum of file.
+func SHA256File(file string) (string, error) { return checksumFile(file, "sha256") }
+
+// checksumFile returns the checksum of file with the algorithm, sha256 or sha512.
+func checksumFile(file, algorithm string) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported checksum algorithm '%s'", algorithm)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
 type downloader struct {
 	host  environment.HostActions
 	guest environment.GuestActions
+	// dir is the cache directory.
+	dir string
 }
 
 func (d downloader) cacheFileName(url string) string {
-	return filepath.Join(config.CacheDir(), "caches", sha256Hash(url))
+	return filepath.Join(d.dir, sha256Hash(url))
 }
 
 func (d downloader) cacheDownloadingFileName(url string) string {
 	return d.cacheFileName(url) + ".downloading"
 }
 
-func (d downloader) downloadFile(url string) (err error) {
+// downloadFile downloads the file at url into the cache, with the progress output if progress is true.
+func (d downloader) downloadFile(url string, progress bool) (err error) {
 	// save to a temporary file initially before renaming to the desired file after successful download
 	// this prevents having a corrupt file
 	cacheFileName := d.cacheDownloadingFileName(url)
@@ -51,18 +114,28 @@ func (d downloader) downloadFile(url string) (err error) {
 		return fmt.Errorf("error preparing cache dir: %w", err)
 	}
 
+	// the proxies of the host
+	host := d.host.WithEnv(proxyEnv()...)
+
 	// get rid of curl's initial progress bar by getting the redirect url directly.
-	downloadURL, err := d.host.RunOutput("curl", "-Ls", "-o", "/dev/null", "-w", "%{url_effective}", url)
+	downloadURL, err := host.RunOutput("curl", "-Ls", "-o", "/dev/null", "-w", "%{url_effective}", url)
 	if err != nil {
 		return fmt.Errorf("error retrieving redirect url: %w", err)
 	}
 
 	// ask curl to resume previous download if possible "-C -"
-	if err := d.host.RunInteractive("curl", "-L", "-#", "-C", "-", "-o", cacheFileName, downloadURL); err != nil {
-		return err
+	// and to fail on http errors, e.g. to not save the error page of a proxy
+	if !progress {
+		if err := host.RunQuiet("curl", "-fLs", "-C", "-", "-o", cacheFileName, downloadURL); err != nil {
+			return err
+		}
+	} else {
+		if err := host.RunInteractive("curl", "-fL", "-#", "-C", "-", "-o", cacheFileName, downloadURL); err != nil {
+			return err
+		}
+		// clear curl progress line
+		terminal.ClearLine()
 	}
-	// clear curl progress line
-	terminal.ClearLine()
 
 	return d.host.RunQuiet("mv", d.cacheDownloadingFileName(url), d.cacheFileName(url))
 
@@ -0,0 +1,62 @@
+package downloader
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// proxyEnv returns the environment of curl for the proxy settings of the host.
+// curl ignores the uppercase HTTP_PROXY and does not read the system proxies of macOS.
+func proxyEnv() []string {
+	var env []string
+	configured := false
+	for _, name := range []string{"http_proxy", "https_proxy", "no_proxy"} {
+		v := os.Getenv(name)
+		if v == "" {
+			if v = os.Getenv(strings.ToUpper(name)); v != "" {
+				env = append(env, name+"="+v)
+			}
+		}
+		if v != "" && name != "no_proxy" {
+			configured = true
+		}
+	}
+	if configured || runtime.GOOS != "darwin" {
+		return env
+	}
+	return append(env, systemProxyEnv()...)
+}
+
+// systemProxyEnv returns the proxy environment for the system proxies of macOS.
+func systemProxyEnv() []string {
+	out, err := exec.Command("scutil", "--proxy").Output()
+	if err != nil {
+		return nil
+	}
+	// <dictionary> {
+	//   HTTPSEnable : 1
+	//   HTTPSPort : 3128
+	//   HTTPSProxy : proxy.example.com
+	// }
+	values := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if parts := strings.SplitN(line, " : ", 2); len(parts) == 2 {
+			values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	var env []string
+	for _, p := range []struct{ key, name string }{{"HTTP", "http_proxy"}, {"HTTPS", "https_proxy"}} {
+		if values[p.key+"Enable"] != "1" || values[p.key+"Proxy"] == "" {
+			continue
+		}
+		proxy := "http://" + values[p.key+"Proxy"]
+		if port := values[p.key+"Port"]; port != "" {
+			proxy += ":" + port
+		}
+		env = append(env, p.name+"="+proxy)
+	}
+	return env
+}
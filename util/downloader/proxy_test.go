@@ -0,0 +1,37 @@
+package downloader
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func Test_proxyEnv(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want []string
+	}{
+		// lowercase variables are read by curl
+		{env: map[string]string{"http_proxy": "http://proxy:3128"}},
+		{env: map[string]string{"HTTP_PROXY": "http://proxy:3128", "HTTPS_PROXY": "http://proxy:3129", "NO_PROXY": "localhost"},
+			want: []string{"http_proxy=http://proxy:3128", "https_proxy=http://proxy:3129", "no_proxy=localhost"}},
+		{env: map[string]string{"http_proxy": "http://proxy:3128", "HTTP_PROXY": "http://other:3128", "HTTPS_PROXY": "http://proxy:3129"},
+			want: []string{"https_proxy=http://proxy:3129"}},
+		// no proxy configured, the system proxies are used on macOS
+		{env: map[string]string{"NO_PROXY": "localhost"}, want: []string{"no_proxy=localhost"}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			for _, name := range []string{"http_proxy", "https_proxy", "no_proxy", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if _, ok := tt.env["NO_PROXY"]; ok && len(tt.env) == 1 && runtime.GOOS == "darwin" {
+				t.Skip("depends on the system proxies")
+			}
+			if got := proxyEnv(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("proxyEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/abiosoft/colima/cli"
+)
+
+// Send posts a desktop notification with title and message.
+// osascript is used on macOS and notify-send on Linux.
+func Send(title, message string) error {
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		args = []string{"osascript", "-e", script}
+	case "linux":
+		args = []string{"notify-send", title, message}
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+
+	cmd := cli.Command(args[0], args[1:]...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	return cmd.Run()
+}
+
+// appleScriptString returns s as an AppleScript string literal, only backslashes and double quotes are escaped.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,39 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// checkInterval is the minimum interval between checks for a new release.
+const checkInterval = 24 * time.Hour
+
+// Notice returns the latest version if it is newer than current, or an empty string otherwise.
+// A new release is checked for at most once a day, the result of the check is cached in file.
+func Notice(current, file string) string {
+	var state struct {
+		Checked time.Time `json:"checked"`
+		Latest  string    `json:"latest"`
+	}
+	if b, err := os.ReadFile(file); err == nil {
+		_ = json.Unmarshal(b, &state)
+	}
+
+	if time.Since(state.Checked) > checkInterval {
+		latest, err := Latest()
+		if err != nil {
+			// not worth bothering the user, retried on the next check
+			return ""
+		}
+		state.Checked, state.Latest = time.Now(), latest
+		if b, err := json.Marshal(state); err == nil {
+			_ = os.WriteFile(file, b, 0644)
+		}
+	}
+
+	if Newer(current, state.Latest) {
+		return state.Latest
+	}
+	return ""
+}
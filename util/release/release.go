@@ -0,0 +1,198 @@
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	repo       = "abiosoft/colima"
+	latestURL  = "https://api.github.com/repos/" + repo + "/releases/latest"
+	releaseURL = "https://github.com/" + repo + "/releases/download/"
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Latest returns the tag of the latest release e.g. v0.3.4.
+func Latest() (string, error) {
+	resp, err := client.Get(latestURL)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error retrieving latest release: %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("error decoding latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("error retrieving latest release: empty tag")
+	}
+	return release.TagName, nil
+}
+
+// Valid returns if version is a release version e.g. v0.3.4.
+func Valid(version string) bool {
+	_, ok := parse(version)
+	return ok
+}
+
+// Newer returns if version latest is newer than current.
+// Development builds i.e. without a version tag are never outdated.
+func Newer(current, latest string) bool {
+	c, ok := parse(current)
+	if !ok {
+		return false
+	}
+	l, ok := parse(latest)
+	if !ok {
+		return false
+	}
+	for i := range c {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// Older returns if version is older than minimum, e.g. for the minimum version of a dependency.
+// Unlike Newer, an invalid version is an error.
+func Older(version, minimum string) (bool, error) {
+	v, ok := parse(version)
+	if !ok {
+		return false, fmt.Errorf("invalid version '%s'", version)
+	}
+	m, ok := parse(minimum)
+	if !ok {
+		return false, fmt.Errorf("invalid version '%s'", minimum)
+	}
+	for i := range v {
+		if v[i] != m[i] {
+			return v[i] < m[i], nil
+		}
+	}
+	return false, nil
+}
+
+// OutputVersion returns the version in the output of the --version flag of a binary, the word after "version"
+// on the first line e.g. 6.2.0 of "QEMU emulator version 6.2.0 (Debian 1:6.2+dfsg-2ubuntu6)".
+func OutputVersion(out string) (string, error) {
+	fields := strings.Fields(strings.SplitN(out, "\n", 2)[0])
+	for i, f := range fields {
+		if f == "version" && i+1 < len(fields) {
+			return fields[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("no version in '%s'", strings.TrimSpace(out))
+}
+
+// parse parses the major, minor and patch of version e.g. v0.3.4, v0.3.4-2-gabcdef, 6.2, 1:6.2+dfsg.
+// A missing patch is 0, the epoch of distribution packages is ignored.
+func parse(version string) (v [3]int, ok bool) {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.Index(version, ":"); i >= 0 {
+		version = version[i+1:]
+	}
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) == 2 {
+		parts = append(parts, "0")
+	}
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// assetName returns the name of the release binary for the host
+// e.g. colima-Darwin-arm64, matching `colima-$(uname)-$(uname -m)`.
+func assetName() string {
+	goos := strings.ToUpper(runtime.GOOS[:1]) + runtime.GOOS[1:]
+	arch := runtime.GOARCH
+	switch {
+	case arch == "amd64":
+		arch = "x86_64"
+	case arch == "arm64" && runtime.GOOS == "linux":
+		arch = "aarch64"
+	}
+	return "colima-" + goos + "-" + arch
+}
+
+// Download downloads the release binary of version for the host to file
+// and verifies it against the sha256 checksum published with the release.
+// The checksum is of the same origin, it detects corrupted downloads and does not authenticate the release.
+func Download(version, file string) error {
+	url := releaseURL + version + "/" + assetName()
+
+	expected, err := get(url + ".sha256sum")
+	if err != nil {
+		return fmt.Errorf("error retrieving checksum: %w", err)
+	}
+	fields := strings.Fields(string(expected))
+	if len(fields) == 0 {
+		return fmt.Errorf("error retrieving checksum: empty checksum file")
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error downloading %s: %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error downloading %s: %w", url, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if actual := fmt.Sprintf("%x", h.Sum(nil)); actual != fields[0] {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Base(url), fields[0], actual)
+	}
+	return nil
+}
+
+func get(url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,67 @@
+package release
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOutputVersion(t *testing.T) {
+	tests := []struct {
+		out     string
+		want    string
+		wantErr bool
+	}{
+		{out: "QEMU emulator version 6.2.0\nCopyright (c) 2003-2021 Fabrice Bellard and the QEMU Project developers\n", want: "6.2.0"},
+		{out: "QEMU emulator version 8.2.0 (v8.2.0-dirty)\n", want: "8.2.0"},
+		{out: "QEMU emulator version 6.2.0 (Debian 1:6.2+dfsg-2ubuntu6.15)\n", want: "6.2.0"},
+		{out: "QEMU emulator version 1:6.2+dfsg-2ubuntu6\n", want: "1:6.2+dfsg-2ubuntu6"},
+		{out: "limactl version 0.8.3\n", want: "0.8.3"},
+		{out: "QEMU emulator\n", wantErr: true},
+		{out: "", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := OutputVersion(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OutputVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("OutputVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOlder(t *testing.T) {
+	tests := []struct {
+		version string
+		minimum string
+		want    bool
+		wantErr bool
+	}{
+		{version: "6.2.0", minimum: "6.1.0"},
+		{version: "6.1.0", minimum: "6.1.0"},
+		{version: "6.0.1", minimum: "6.1.0", want: true},
+		{version: "5.10.0", minimum: "6.1.0", want: true},
+		{version: "6.2", minimum: "6.1.0"},
+		{version: "1:6.2+dfsg-2ubuntu6", minimum: "6.1.0"},
+		{version: "1:6.0+dfsg-2", minimum: "6.1.0", want: true},
+		{version: "8.2.0-dirty", minimum: "6.1.0"},
+		// k3s releases, the revisions are not ordered
+		{version: "v1.22.5+k3s1", minimum: "v1.23.4+k3s1", want: true},
+		{version: "v1.23.4+k3s1", minimum: "v1.23.4+k3s2"},
+		{version: "unknown", minimum: "6.1.0", wantErr: true},
+		{version: "6", minimum: "6.1.0", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := Older(tt.version, tt.minimum)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Older() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Older(%s, %s) = %v, want %v", tt.version, tt.minimum, got, tt.want)
+			}
+		})
+	}
+}
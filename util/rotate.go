@@ -0,0 +1,72 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// RotateFile rotates file if it is larger than maxSize, keeping at most keep rotated files
+// i.e. file -> file.1 -> file.2, the oldest is removed.
+// It is a no-op if the file does not exist.
+// The file should not be open for writing, writes to an open file continue in the rotated file.
+func RotateFile(file string, maxSize int64, keep int) error {
+	return rotateFile(file, maxSize, keep, func(rotated string) error {
+		return os.Rename(file, rotated)
+	})
+}
+
+// RotateOpenFile is like RotateFile for a file open for writing in append mode.
+// The file is copied to the rotated file and truncated, the writes continue in the file.
+// Writers not in append mode would continue at their offset, leaving the file sparse.
+func RotateOpenFile(file string, maxSize int64, keep int) error {
+	return rotateFile(file, maxSize, keep, func(rotated string) error {
+		if err := copyFile(file, rotated); err != nil {
+			return err
+		}
+		return os.Truncate(file, 0)
+	})
+}
+
+func rotateFile(file string, maxSize int64, keep int, rotate func(rotated string) error) error {
+	stat, err := os.Stat(file)
+	if err != nil || stat.Size() <= maxSize {
+		return nil
+	}
+
+	if keep < 1 {
+		return os.Remove(file)
+	}
+
+	rotated := func(i int) string { return fmt.Sprintf("%s.%d", file, i) }
+	_ = os.Remove(rotated(keep))
+	for i := keep - 1; i > 0; i-- {
+		if _, err := os.Stat(rotated(i)); err == nil {
+			if err := os.Rename(rotated(i), rotated(i+1)); err != nil {
+				return fmt.Errorf("error rotating %s: %w", file, err)
+			}
+		}
+	}
+	if err := rotate(rotated(1)); err != nil {
+		return fmt.Errorf("error rotating %s: %w", file, err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRotateFile(t *testing.T) {
+	tests := []struct {
+		size    int
+		rotated []string // existing rotated files
+		keep    int
+		want    map[string]string
+	}{
+		// not rotated below the size
+		{size: 10, keep: 2, want: map[string]string{"log": "0123456789"}},
+		{size: 11, keep: 2, want: map[string]string{"log.1": "01234567890"}},
+		{size: 11, rotated: []string{"log.1"}, keep: 2, want: map[string]string{"log.1": "01234567890", "log.2": "log.1"}},
+		// the oldest is removed
+		{size: 11, rotated: []string{"log.1", "log.2"}, keep: 2, want: map[string]string{"log.1": "01234567890", "log.2": "log.1"}},
+		{size: 11, rotated: []string{"log.1"}, keep: 0, want: map[string]string{"log.1": "log.1"}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			dir := t.TempDir()
+			body := ""
+			for j := 0; j < tt.size; j++ {
+				body += fmt.Sprint(j % 10)
+			}
+			file := filepath.Join(dir, "log")
+			if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range tt.rotated {
+				if err := os.WriteFile(filepath.Join(dir, r), []byte(r), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := RotateFile(file, 10, tt.keep); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			entries, _ := os.ReadDir(dir)
+			for _, e := range entries {
+				b, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+				got[e.Name()] = string(b)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RotateFile() files = %v, want %v", sorted(got), sorted(tt.want))
+			}
+		})
+	}
+
+	// missing file
+	if err := RotateFile(filepath.Join(t.TempDir(), "log"), 10, 2); err != nil {
+		t.Errorf("RotateFile() error = %v for a missing file", err)
+	}
+}
+
+func sorted(m map[string]string) []string {
+	var s []string
+	for k, v := range m {
+		s = append(s, k+"="+v)
+	}
+	sort.Strings(s)
+	return s
+}
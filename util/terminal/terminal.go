@@ -16,3 +16,9 @@ func ClearLine() {
 
 	fmt.Print("\033[1A \033[2K \r")
 }
+
+// IsTerminal returns if the output is a terminal.
+func IsTerminal() bool { return isTerminal }
+
+// IsInputTerminal returns if the input is a terminal.
+func IsInputTerminal() bool { return terminal.IsTerminal(int(os.Stdin.Fd())) }
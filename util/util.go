@@ -1,9 +1,16 @@
 package util
 
 import (
+	"bufio"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func HomeDir() string {
@@ -14,3 +21,72 @@ func HomeDir() string {
 	}
 	return home
 }
+
+// ShellQuote quotes s for the shell, as a single argument.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// timezonePattern matches the names of the tz database, e.g. Europe/Berlin, Etc/GMT+1.
+var timezonePattern = regexp.MustCompile(`^[A-Za-z0-9_+-]+(/[A-Za-z0-9_+-]+)*$`)
+
+// ValidTimezone returns if zone is a name of the tz database.
+func ValidTimezone(zone string) bool {
+	if !timezonePattern.MatchString(zone) {
+		return false
+	}
+	_, err := time.LoadLocation(zone)
+	return err == nil
+}
+
+// HostMemory returns the total memory of the host in bytes.
+// 0 is returned if it cannot be determined.
+func HostMemory() int64 {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0
+		}
+		mem, _ := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		return mem
+
+	case "linux":
+		f, err := os.Open("/proc/meminfo")
+		if err != nil {
+			return 0
+		}
+		defer f.Close()
+
+		// MemTotal:       16306992 kB
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "MemTotal:" {
+				mem, _ := strconv.ParseInt(fields[1], 10, 64)
+				return mem * 1024
+			}
+		}
+	}
+	return 0
+}
+
+// DefaultLocale is the locale used when the locale of the host is not a UTF-8 locale.
+const DefaultLocale = "C.UTF-8"
+
+// HostLocale returns the UTF-8 locale of the host from $LC_ALL, $LC_CTYPE or $LANG.
+// DefaultLocale is returned if the host locale is unset or not a UTF-8 locale, e.g. the
+// 'UTF-8' value set for LC_CTYPE by the macOS terminal.
+func HostLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := os.Getenv(env)
+		if locale == "" {
+			continue
+		}
+		lang := strings.SplitN(locale, ".", 2)
+		if len(lang) == 2 && lang[0] != "" && strings.Contains(strings.ToLower(lang[1]), "utf") {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHostLocale(t *testing.T) {
+	tests := []struct {
+		lcAll, lcCtype, lang string
+		want                 string
+	}{
+		{want: DefaultLocale},
+		{lang: "en_GB.UTF-8", want: "en_GB.UTF-8"},
+		{lang: "en_GB.utf8", want: "en_GB.utf8"},
+		{lang: "en_GB.ISO-8859-1", want: DefaultLocale},
+		{lang: "C", want: DefaultLocale},
+		// set by the macOS terminal
+		{lcCtype: "UTF-8", lang: "en_GB.UTF-8", want: "en_GB.UTF-8"},
+		{lcAll: "fr_FR.UTF-8", lcCtype: "de_DE.UTF-8", lang: "en_GB.UTF-8", want: "fr_FR.UTF-8"},
+		{lcCtype: "de_DE.UTF-8", lang: "en_GB.UTF-8", want: "de_DE.UTF-8"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_CTYPE", tt.lcCtype)
+			t.Setenv("LANG", tt.lang)
+			if got := HostLocale(); got != tt.want {
+				t.Errorf("HostLocale() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
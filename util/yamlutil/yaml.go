@@ -1,6 +1,7 @@
 package yamlutil
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
@@ -16,3 +17,87 @@ func WriteYAML(value interface{}, file string) error {
 
 	return os.WriteFile(file, b, 0644)
 }
+
+// UpdateYAML encodes struct to file as YAML, preserving the comments
+// and the order of keys in the existing file.
+// The file is created if it does not exist.
+func UpdateYAML(value interface{}, file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil || len(bytes.TrimSpace(b)) == 0 {
+		return WriteYAML(value, file)
+	}
+
+	var current yaml.Node
+	if err := yaml.Unmarshal(b, &current); err != nil || len(current.Content) == 0 {
+		// existing file is invalid, nothing to preserve
+		return WriteYAML(value, file)
+	}
+
+	var updated yaml.Node
+	if err := updated.Encode(value); err != nil {
+		return fmt.Errorf("error encoding YAML: %w", err)
+	}
+
+	current.Content[0] = mergeNode(current.Content[0], &updated)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(&current); err != nil {
+		return fmt.Errorf("error encoding YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("error encoding YAML: %w", err)
+	}
+
+	return os.WriteFile(file, buf.Bytes(), 0644)
+}
+
+// mergeNode returns src with the comments and key order of dst retained.
+// Keys in dst that are not in src are dropped.
+func mergeNode(dst, src *yaml.Node) *yaml.Node {
+	if dst.Kind != src.Kind {
+		keepComments(dst, src)
+		return src
+	}
+
+	if src.Kind == yaml.MappingNode {
+		srcKeys := map[string]int{}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			srcKeys[src.Content[i].Value] = i
+		}
+
+		var content []*yaml.Node
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			key := dst.Content[i]
+			j, ok := srcKeys[key.Value]
+			if !ok {
+				continue
+			}
+			seen[key.Value] = true
+			content = append(content, key, mergeNode(dst.Content[i+1], src.Content[j+1]))
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if !seen[src.Content[i].Value] {
+				content = append(content, src.Content[i], src.Content[i+1])
+			}
+		}
+		src.Content = content
+	}
+
+	keepComments(dst, src)
+	return src
+}
+
+func keepComments(dst, src *yaml.Node) {
+	if src.HeadComment == "" {
+		src.HeadComment = dst.HeadComment
+	}
+	if src.LineComment == "" {
+		src.LineComment = dst.LineComment
+	}
+	if src.FootComment == "" {
+		src.FootComment = dst.FootComment
+	}
+}